@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"composepack/internal/cli"
 	"composepack/internal/di"
@@ -14,6 +16,18 @@ func main() {
 	}
 
 	if err := cli.NewRootCommand(application).Execute(); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode passes through the status of a failed docker compose child (128+signal when it was
+// killed), and of any other error carrying its own code such as diff's 2. Every other error
+// exits 1, which a compose child can also return, so 1 alone does not say which side failed.
+func exitCode(err error) int {
+	var coded interface{ ExitCode() int }
+	if errors.As(err, &coded) && coded.ExitCode() > 0 {
+		return coded.ExitCode()
 	}
+	return 1
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"composepack/internal/infra/process"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want int
+	}{
+		"composepack error":    {errors.New("chart not found"), 1},
+		"compose child":        {fmt.Errorf("docker compose failed: %w", &process.ExitError{Code: 3, Err: errors.New("exit status 3")}), 3},
+		"compose child exit 1": {&process.ExitError{Code: 1, Err: errors.New("exit status 1")}, 1},
+		"killed child":         {&process.ExitError{Code: 130, Err: errors.New("signal: interrupt")}, 130},
+	}
+	for name, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("%s: exitCode() = %d, want %d", name, got, tt.want)
+		}
+	}
+}
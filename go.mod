@@ -3,6 +3,7 @@ module composepack
 go 1.22
 
 require (
+	github.com/Masterminds/semver/v3 v3.2.0
 	github.com/go-viper/mapstructure/v2 v2.4.0
 	github.com/google/wire v0.7.0
 	github.com/rs/zerolog v1.31.0
@@ -11,7 +12,6 @@ require (
 
 require (
 	github.com/Masterminds/goutils v1.1.1 // indirect
-	github.com/Masterminds/semver/v3 v3.2.0 // indirect
 	github.com/Masterminds/sprig/v3 v3.2.3 // indirect
 	github.com/google/uuid v1.1.1 // indirect
 	github.com/huandu/xstrings v1.3.3 // indirect
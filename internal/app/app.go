@@ -4,17 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	"composepack/internal/core/chart"
 	"composepack/internal/core/dockercompose"
+	"composepack/internal/core/hooks"
 	"composepack/internal/core/release"
 	releaseruntime "composepack/internal/core/runtime"
 	"composepack/internal/core/templating"
 	"composepack/internal/core/values"
+	"composepack/internal/infra/cache"
 	"composepack/internal/infra/config"
 	"composepack/internal/infra/logging"
 	"composepack/internal/infra/process"
@@ -31,20 +35,29 @@ type Runtime struct {
 	Config         config.Config
 	Logger         logging.Logger
 	ChartLoader    chart.Loader
+	ChartCache     *cache.Cache
 	TemplateEngine *templating.Engine
 	RuntimeWriter  *releaseruntime.Writer
 	ProcessRunner  *process.Runner
 	DockerRunner   *dockercompose.Runner
 	ReleaseStore   *release.Store
+	History        *release.History
+	Stdin          io.Reader
+	Stdout         io.Writer
+	Stderr         io.Writer
 }
 
 // NewRuntime wires default implementations for the runtime container.
-func NewRuntime(cfg config.Config, logger logging.Logger, loader chart.Loader) *Runtime {
+func NewRuntime(cfg config.Config, logger logging.Logger, loader chart.Loader, charts *cache.Cache) *Runtime {
 	if logger == nil {
 		logger = logging.Nop{}
 	}
+	if charts == nil {
+		charts = cache.New()
+	}
+	charts.Offline = cfg.Offline
 	if loader == nil {
-		loader = NewDefaultChartLoader()
+		loader = NewDefaultChartLoader(charts)
 	}
 	procRunner := process.NewRunner()
 
@@ -52,18 +65,23 @@ func NewRuntime(cfg config.Config, logger logging.Logger, loader chart.Loader) *
 		Config:         cfg,
 		Logger:         logger,
 		ChartLoader:    loader,
+		ChartCache:     charts,
 		TemplateEngine: templating.NewEngine(),
 		RuntimeWriter:  &releaseruntime.Writer{},
 		ProcessRunner:  procRunner,
 		DockerRunner:   dockercompose.NewRunner(procRunner),
 		ReleaseStore:   &release.Store{},
+		History:        &release.History{},
+		Stdin:          os.Stdin,
+		Stdout:         os.Stdout,
+		Stderr:         os.Stderr,
 	}
 }
 
 // NewDefaultChartLoader constructs the default filesystem chart loader.
-func NewDefaultChartLoader() chart.Loader {
+func NewDefaultChartLoader(charts *cache.Cache) chart.Loader {
 	fs := chart.NewFileSystemChartLoader(fileloader.NewFileSystemLoader())
-	return chart.NewCompositeLoader(fs)
+	return chart.NewCompositeLoader(fs, charts)
 }
 
 // Application provides methods that implement workflows such as install/up/down.
@@ -78,29 +96,53 @@ func NewApplication(rt *Runtime) *Application {
 
 // RenderOptions capture the shared knobs across install/template/up workflows.
 type RenderOptions struct {
-	ReleaseName    string
-	ChartSource    string
-	ValueFiles     []string
-	SetValues      map[string]string
+	ReleaseName string
+	// ChartSource defaults to the chart the release was installed from.
+	ChartSource string
+	// UseStoredChart renders from the chart copy stored with the release's current revision
+	// instead of ChartSource.
+	UseStoredChart bool
+	// ChartVersion is a version or semver range; it selects the version of `repo/chart`
+	// sources and is checked against the loaded chart otherwise.
+	ChartVersion string
+	Verify       VerifyOptions
+	ValueFiles   []string
+	SetValues    values.Overrides
+	// ReuseValues layers ValueFiles and SetValues over the values supplied to the release's
+	// current revision; ResetValues ignores them. With neither, they are reused only when no
+	// new values are given.
+	ReuseValues    bool
+	ResetValues    bool
 	RuntimeBaseDir string
 	RuntimePath    string
+	// MaxHistory caps stored revisions; 0 keeps every revision.
+	MaxHistory int
 }
 
 // InstallOptions drives chart installation into a runtime directory.
 type InstallOptions struct {
 	RenderOptions
 	AutoStart bool
+	DryRun    DryRunOptions
+	// Wait requires AutoStart.
+	Wait WaitOptions
 }
 
 // TemplateOptions render templates without invoking Docker Compose.
 type TemplateOptions struct {
 	RenderOptions
+	DryRun DryRunOptions
+	// OutputDir writes the rendered tree elsewhere without touching release metadata.
+	OutputDir string
 }
 
 // UpOptions render and run docker compose up.
 type UpOptions struct {
 	RenderOptions
 	Detach bool
+	DryRun DryRunOptions
+	// Wait implies Detach.
+	Wait WaitOptions
 }
 
 // DownOptions control docker compose down behavior.
@@ -109,6 +151,8 @@ type DownOptions struct {
 	RuntimeBaseDir string
 	RuntimePath    string
 	RemoveVolumes  bool
+	// RemoveImages is passed to --rmi ("local" or "all") when set.
+	RemoveImages string
 }
 
 // LogsOptions control docker compose logs streaming.
@@ -127,42 +171,75 @@ type PSOptions struct {
 	RuntimePath    string
 }
 
+// HistoryOptions select the release whose revisions should be listed.
+type HistoryOptions struct {
+	ReleaseName    string
+	RuntimeBaseDir string
+	RuntimePath    string
+}
+
+// RollbackOptions control restoring a previous revision.
+type RollbackOptions struct {
+	ReleaseName    string
+	RuntimeBaseDir string
+	RuntimePath    string
+	// Revision to restore; 0 selects the revision before the current one.
+	Revision   int
+	MaxHistory int
+}
+
 // InstallRelease implements the install workflow described in the PRD.
 func (a *Application) InstallRelease(ctx context.Context, opts InstallOptions) error {
-	runtimeDir, _, err := a.renderRelease(ctx, opts.RenderOptions)
+	// an install starts from the chart defaults even when it replaces an existing release
+	opts.ReuseValues, opts.ResetValues = false, true
+	if opts.DryRun.Enabled {
+		return a.dryRun(ctx, opts.RenderOptions, opts.DryRun)
+	}
+	if opts.Wait.Enabled && !opts.AutoStart {
+		return errors.New("--wait requires --auto-start")
+	}
+	runtimeDir, meta, err := a.renderRelease(ctx, opts.RenderOptions)
 	if err != nil {
 		return err
 	}
-	if !opts.AutoStart {
-		return nil
+	if opts.AutoStart {
+		args := []string{"up", "-d"}
+		if err := a.finishRevision(ctx, runtimeDir, meta, a.startRelease(ctx, runtimeDir, meta, args, opts.Wait, hooks.PreInstall, hooks.PostInstall)); err != nil {
+			return err
+		}
 	}
-	args := []string{"up", "-d"}
-	return a.Runtime.DockerRunner.Run(ctx, dockercompose.CommandOptions{
-		WorkingDir: runtimeDir,
-		Args:       args,
-	})
+	return a.printNotes(ctx, runtimeDir, meta.Revision)
 }
 
 // TemplateRelease renders templates and writes runtime files without running containers.
 func (a *Application) TemplateRelease(ctx context.Context, opts TemplateOptions) error {
+	if opts.DryRun.Enabled {
+		return a.dryRun(ctx, opts.RenderOptions, opts.DryRun)
+	}
+	if opts.OutputDir != "" {
+		return a.renderToDir(ctx, opts.RenderOptions, opts.OutputDir)
+	}
 	_, _, err := a.renderRelease(ctx, opts.RenderOptions)
 	return err
 }
 
 // UpRelease re-renders templates and invokes docker compose up.
 func (a *Application) UpRelease(ctx context.Context, opts UpOptions) error {
-	runtimeDir, _, err := a.renderRelease(ctx, opts.RenderOptions)
+	if opts.DryRun.Enabled {
+		return a.dryRun(ctx, opts.RenderOptions, opts.DryRun)
+	}
+	runtimeDir, meta, err := a.renderRelease(ctx, opts.RenderOptions)
 	if err != nil {
 		return err
 	}
 	args := []string{"up"}
-	if opts.Detach {
+	if opts.Detach || opts.Wait.Enabled {
 		args = append(args, "-d")
 	}
-	return a.Runtime.DockerRunner.Run(ctx, dockercompose.CommandOptions{
-		WorkingDir: runtimeDir,
-		Args:       args,
-	})
+	if err := a.finishRevision(ctx, runtimeDir, meta, a.startRelease(ctx, runtimeDir, meta, args, opts.Wait, hooks.PreUpgrade, hooks.PostUpgrade)); err != nil {
+		return err
+	}
+	return a.printNotes(ctx, runtimeDir, meta.Revision)
 }
 
 // DownRelease shells out to docker compose down for the given release.
@@ -172,15 +249,43 @@ func (a *Application) DownRelease(ctx context.Context, opts DownOptions) error {
 		return err
 	}
 
-	args := []string{"down"}
-	if opts.RemoveVolumes {
-		args = append(args, "--volumes")
+	meta, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+	if err != nil {
+		return err
 	}
+	if meta == nil {
+		meta = &release.Metadata{}
+	}
+	hookCount := len(meta.Hooks)
 
-	return a.Runtime.DockerRunner.Run(ctx, dockercompose.CommandOptions{
-		WorkingDir: runtimeDir,
-		Args:       args,
-	})
+	downErr := a.runHooks(ctx, runtimeDir, meta, hooks.PreDelete)
+	if downErr == nil {
+		args := append(composeFileArgs(runtimeDir), "down")
+		if opts.RemoveVolumes {
+			args = append(args, "--volumes")
+		}
+		if opts.RemoveImages != "" {
+			args = append(args, "--rmi", opts.RemoveImages)
+		}
+		downErr = a.Runtime.DockerRunner.Run(ctx, dockercompose.CommandOptions{
+			WorkingDir: runtimeDir,
+			Args:       args,
+		})
+	}
+	if downErr == nil {
+		downErr = a.runHooks(ctx, runtimeDir, meta, hooks.PostDelete)
+	}
+
+	if len(meta.Hooks) > hookCount && meta.Revision > 0 {
+		saveCtx := context.WithoutCancel(ctx)
+		if err := a.Runtime.ReleaseStore.Save(saveCtx, runtimeDir, meta); err != nil && downErr == nil {
+			return fmt.Errorf("save release metadata: %w", err)
+		}
+		if err := a.Runtime.History.SaveMetadata(saveCtx, runtimeDir, meta); err != nil && downErr == nil {
+			return fmt.Errorf("update revision: %w", err)
+		}
+	}
+	return downErr
 }
 
 // StreamLogs tails docker compose logs for the release.
@@ -198,10 +303,7 @@ func (a *Application) StreamLogs(ctx context.Context, opts LogsOptions) error {
 		args = append(args, "--tail", fmt.Sprintf("%d", opts.Tail))
 	}
 
-	return a.Runtime.DockerRunner.Run(ctx, dockercompose.CommandOptions{
-		WorkingDir: runtimeDir,
-		Args:       args,
-	})
+	return a.streamCompose(ctx, runtimeDir, args)
 }
 
 // ShowStatus surfaces docker compose ps data.
@@ -212,54 +314,265 @@ func (a *Application) ShowStatus(ctx context.Context, opts PSOptions) error {
 	}
 
 	args := []string{"ps"}
-	return a.Runtime.DockerRunner.Run(ctx, dockercompose.CommandOptions{
+	return a.streamCompose(ctx, runtimeDir, args)
+}
+
+// ReleaseHistory returns every stored revision of a release, oldest first.
+func (a *Application) ReleaseHistory(ctx context.Context, opts HistoryOptions) ([]*release.Metadata, error) {
+	_, runtimeDir, err := a.resolveRuntimeLocation(opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := a.Runtime.History.List(ctx, runtimeDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("release %s has no recorded revisions", opts.ReleaseName)
+	}
+	return revisions, nil
+}
+
+// loadRevision reads a stored revision of a release; revision 0 selects the current one.
+func (a *Application) loadRevision(ctx context.Context, releaseName, baseOverride, runtimePath string, revision int) (*release.Revision, error) {
+	_, runtimeDir, err := a.resolveRuntimeLocation(releaseName, baseOverride, runtimePath)
+	if err != nil {
+		return nil, err
+	}
+	if revision == 0 {
+		current, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, fmt.Errorf("release %s not found in %s", releaseName, runtimeDir)
+		}
+		revision = current.Revision
+	}
+	return a.Runtime.History.Get(ctx, runtimeDir, revision)
+}
+
+// RollbackRelease restores a previous revision as a new revision and runs docker compose up -d.
+func (a *Application) RollbackRelease(ctx context.Context, opts RollbackOptions) error {
+	_, runtimeDir, err := a.resolveRuntimeLocation(opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath)
+	if err != nil {
+		return err
+	}
+
+	current, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return fmt.Errorf("release %s not found in %s", opts.ReleaseName, runtimeDir)
+	}
+
+	target := opts.Revision
+	if target == 0 {
+		target = current.Revision - 1
+	}
+	if target <= 0 {
+		return fmt.Errorf("release %s has no previous revision to roll back to", opts.ReleaseName)
+	}
+	if target == current.Revision {
+		return fmt.Errorf("revision %d is already the current revision", target)
+	}
+
+	rev, err := a.Runtime.History.Get(ctx, runtimeDir, target)
+	if err != nil {
+		return err
+	}
+
+	if _, err := a.Runtime.RuntimeWriter.Write(ctx, releaseruntime.WriteOptions{
+		ReleaseName: opts.ReleaseName,
+		BaseDir:     filepath.Dir(runtimeDir),
+		ComposeYAML: rev.ComposeYAML,
+		HooksYAML:   rev.HooksYAML,
+		Files:       rev.Files,
+	}); err != nil {
+		return fmt.Errorf("restore runtime directory: %w", err)
+	}
+
+	meta := *rev.Metadata
+	meta.CreatedAt = time.Time{}
+	meta.Description = fmt.Sprintf("Rollback to %d", target)
+	meta.Readiness = nil
+	meta.Hooks = nil
+	if err := a.recordRevision(ctx, runtimeDir, &release.Revision{
+		Metadata:    &meta,
+		ComposeYAML: rev.ComposeYAML,
+		HooksYAML:   rev.HooksYAML,
+		Files:       rev.Files,
+		UserValues:  rev.UserValues,
+		Notes:       rev.Notes,
+		Provenance:  rev.Provenance,
+		Chart:       rev.Chart,
+	}, opts.MaxHistory); err != nil {
+		return err
+	}
+
+	args := []string{"up", "-d"}
+	return a.finishRevision(ctx, runtimeDir, &meta, a.startRelease(ctx, runtimeDir, &meta, args, WaitOptions{}, hooks.PreUpgrade, hooks.PostUpgrade))
+}
+
+// recordRevision saves rev.Metadata as the next numbered revision and as the current release.json.
+func (a *Application) recordRevision(ctx context.Context, runtimeDir string, rev *release.Revision, maxHistory int) error {
+	meta := rev.Metadata
+	revision, err := a.Runtime.History.NextRevision(ctx, runtimeDir)
+	if err != nil {
+		return fmt.Errorf("determine revision: %w", err)
+	}
+	meta.Revision = revision
+	meta.Status = release.StatusRendered
+	meta.FirstCreatedAt = time.Time{}
+	// an unreadable release.json is about to be replaced; the release then starts afresh
+	if previous, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir); err == nil && previous != nil {
+		meta.FirstCreatedAt = a.firstCreatedAt(ctx, runtimeDir, previous)
+	}
+
+	if err := a.Runtime.ReleaseStore.Save(ctx, runtimeDir, meta); err != nil {
+		return fmt.Errorf("save release metadata: %w", err)
+	}
+	rev.Values = meta.Values
+	if err := a.Runtime.History.Record(ctx, runtimeDir, rev, maxHistory); err != nil {
+		return fmt.Errorf("record revision: %w", err)
+	}
+	return nil
+}
+
+// firstCreatedAt returns when the release described by meta was first rendered. Releases
+// recorded before release.json kept that time fall back to their oldest remaining revision.
+func (a *Application) firstCreatedAt(ctx context.Context, runtimeDir string, meta *release.Metadata) time.Time {
+	if !meta.FirstCreatedAt.IsZero() {
+		return meta.FirstCreatedAt
+	}
+	if revisions, err := a.Runtime.History.List(ctx, runtimeDir); err == nil && len(revisions) > 0 {
+		return revisions[0].CreatedAt
+	}
+	return meta.CreatedAt
+}
+
+// finishRevision records whether docker compose accepted the revision and returns runErr unchanged.
+func (a *Application) finishRevision(ctx context.Context, runtimeDir string, meta *release.Metadata, runErr error) error {
+	meta.Status = release.StatusDeployed
+	if runErr != nil {
+		meta.Status = release.StatusFailed
+	}
+	// persist even if ctx was cancelled by an interrupted compose run
+	saveCtx := context.WithoutCancel(ctx)
+	if err := a.Runtime.ReleaseStore.Save(saveCtx, runtimeDir, meta); err != nil && runErr == nil {
+		return fmt.Errorf("save release metadata: %w", err)
+	}
+	if err := a.Runtime.History.SaveMetadata(saveCtx, runtimeDir, meta); err != nil && runErr == nil {
+		return fmt.Errorf("update revision: %w", err)
+	}
+	return runErr
+}
+
+// startRelease runs the pre hooks, docker compose up, the optional readiness wait and the post hooks.
+func (a *Application) startRelease(ctx context.Context, runtimeDir string, meta *release.Metadata, args []string, wait WaitOptions, pre, post hooks.Event) error {
+	if err := a.runHooks(ctx, runtimeDir, meta, pre); err != nil {
+		return err
+	}
+	if err := a.streamCompose(ctx, runtimeDir, args); err != nil {
+		return err
+	}
+	if wait.Enabled {
+		if err := a.waitForRelease(ctx, runtimeDir, meta, wait); err != nil {
+			return err
+		}
+	}
+	return a.runHooks(ctx, runtimeDir, meta, post)
+}
+
+// streamCompose runs docker compose with output attached to the runtime streams.
+func (a *Application) streamCompose(ctx context.Context, runtimeDir string, args []string) error {
+	return a.Runtime.DockerRunner.Stream(ctx, dockercompose.CommandOptions{
 		WorkingDir: runtimeDir,
 		Args:       args,
+		Stdin:      a.Runtime.Stdin,
+		Stdout:     a.Runtime.Stdout,
+		Stderr:     a.Runtime.Stderr,
+		TTY:        process.IsTerminal(a.Runtime.Stdout),
 	})
 }
 
-func (a *Application) renderRelease(ctx context.Context, opts RenderOptions) (string, *release.Metadata, error) {
+// renderedRelease holds the in-memory output of the render pipeline.
+type renderedRelease struct {
+	Chart        *chart.Chart
+	Source       *release.ChartSource
+	Values       map[string]any
+	UserValues   values.Layers
+	ValueSources []string
+	ComposeYAML  []byte
+	HooksYAML    []byte
+	ComposeFiles []string
+	Files        map[string][]byte
+	Notes        []byte
+	Provenance   []values.Explanation
+}
+
+// render runs chart loading, values merging, templating and compose merging without touching the runtime directory.
+func (a *Application) render(ctx context.Context, opts RenderOptions) (*renderedRelease, error) {
 	if opts.ReleaseName == "" {
-		return "", nil, errors.New("release name is required")
+		return nil, errors.New("release name is required")
 	}
-	if opts.ChartSource == "" {
-		return "", nil, errors.New("chart source must be provided")
-	}
-
-	ch, err := a.Runtime.ChartLoader.Load(ctx, opts.ChartSource)
+	ch, source, err := a.loadReleaseChart(ctx, opts)
 	if err != nil {
-		return "", nil, fmt.Errorf("load chart: %w", err)
+		return nil, err
 	}
 
-	mergedValues, valueSources, err := a.buildValues(ch, opts)
+	reused, err := a.previousValues(ctx, opts)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
-
-	rc := templating.RenderContext{
-		Values: mergedValues,
-		Env:    captureEnv(),
-		Release: templating.ReleaseInfo{
-			Name: opts.ReleaseName,
-		},
-		Chart: ch.Metadata,
-		Files: templating.NewFilesAccessor(ch.StaticFiles),
+	prov := values.NewProvenance()
+	layered, err := a.buildValues(ch, opts, reused, prov)
+	if err != nil {
+		return nil, err
 	}
+	mergedValues := layered.Values
 
-	composeFragments, err := a.Runtime.TemplateEngine.RenderComposeFragments(ctx, ch, rc)
+	env := captureEnv()
+	composeFragments, fileAssets, err := a.renderChartTree(ctx, ch, mergedValues, opts.ReleaseName, "", env)
 	if err != nil {
-		return "", nil, fmt.Errorf("render compose templates: %w", err)
+		return nil, err
 	}
 	if len(composeFragments) == 0 {
-		return "", nil, errors.New("chart produced no compose templates")
+		return nil, errors.New("chart produced no compose templates")
 	}
 
-	fileAssets, err := a.Runtime.TemplateEngine.RenderFiles(ctx, ch, rc)
+	mergedCompose, orderedFragments, err := a.mergeFragments(ctx, composeFragments, fileAssets, opts.ReleaseName)
+	if err != nil {
+		return nil, err
+	}
+	mainCompose, hooksCompose, _, err := hooks.Extract(mergedCompose)
 	if err != nil {
-		return "", nil, fmt.Errorf("render file templates: %w", err)
+		return nil, fmt.Errorf("extract hooks: %w", err)
 	}
+	// only the root chart's notes are shown; subchart notes are ignored
+	notes, err := a.Runtime.TemplateEngine.RenderNotes(ctx, ch, newRenderContext(ch, mergedValues, opts.ReleaseName, env))
+	if err != nil {
+		return nil, fmt.Errorf("render %s: %w", chart.NotesFile, err)
+	}
+
+	return &renderedRelease{
+		Chart:        ch,
+		Source:       source,
+		Values:       mergedValues,
+		UserValues:   layered.User,
+		ValueSources: layered.Sources,
+		ComposeYAML:  mainCompose,
+		HooksYAML:    hooksCompose,
+		ComposeFiles: orderedFragments,
+		Files:        fileAssets,
+		Notes:        notes,
+		Provenance:   prov.Explain(mergedValues),
+	}, nil
+}
 
-	mergedCompose, orderedFragments, err := a.mergeFragments(ctx, composeFragments, fileAssets, opts.ReleaseName)
+func (a *Application) renderRelease(ctx context.Context, opts RenderOptions) (string, *release.Metadata, error) {
+	rendered, err := a.render(ctx, opts)
 	if err != nil {
 		return "", nil, err
 	}
@@ -268,27 +581,44 @@ func (a *Application) renderRelease(ctx context.Context, opts RenderOptions) (st
 	if err != nil {
 		return "", nil, err
 	}
+	chartArchive, err := archiveChart(rendered.Chart)
+	if err != nil {
+		return "", nil, err
+	}
 
 	runtimeDir, err := a.Runtime.RuntimeWriter.Write(ctx, releaseruntime.WriteOptions{
 		ReleaseName: opts.ReleaseName,
 		BaseDir:     baseDir,
-		ComposeYAML: mergedCompose,
-		Files:       fileAssets,
+		ComposeYAML: rendered.ComposeYAML,
+		HooksYAML:   rendered.HooksYAML,
+		Files:       rendered.Files,
 	})
 	if err != nil {
 		return "", nil, fmt.Errorf("write runtime directory: %w", err)
 	}
 
 	meta := &release.Metadata{
-		ReleaseName:   opts.ReleaseName,
-		ChartMetadata: ch.Metadata,
-		Values:        deepCopyMap(mergedValues),
-		ValuesSources: valueSources,
-		ComposeFiles:  orderedFragments,
-	}
-
-	if err := a.Runtime.ReleaseStore.Save(ctx, runtimeDir, meta); err != nil {
-		return "", nil, fmt.Errorf("save release metadata: %w", err)
+		ReleaseName:    opts.ReleaseName,
+		ChartMetadata:  rendered.Chart.Metadata,
+		ChartDigest:    rendered.Chart.Digest,
+		ChartSource:    rendered.Source,
+		RenderedDigest: release.RenderedDigest(rendered.ComposeYAML, rendered.HooksYAML, rendered.Files),
+		Values:         deepCopyMap(rendered.Values),
+		ValuesSources:  rendered.ValueSources,
+		ComposeFiles:   rendered.ComposeFiles,
+	}
+
+	if err := a.recordRevision(ctx, runtimeDir, &release.Revision{
+		Metadata:    meta,
+		ComposeYAML: rendered.ComposeYAML,
+		HooksYAML:   rendered.HooksYAML,
+		Files:       rendered.Files,
+		UserValues:  rendered.UserValues,
+		Notes:       rendered.Notes,
+		Provenance:  rendered.Provenance,
+		Chart:       chartArchive,
+	}, opts.MaxHistory); err != nil {
+		return "", nil, err
 	}
 
 	return runtimeDir, meta, nil
@@ -329,69 +659,129 @@ func (a *Application) resolveRuntimeLocation(release, baseOverride, runtimePath
 	return base, filepath.Join(base, release), nil
 }
 
-func (a *Application) buildValues(ch *chart.Chart, opts RenderOptions) (map[string]any, []string, error) {
-	var result map[string]any
-	if ch.Values != nil {
-		copied := deepCopyMap(ch.Values)
-		result = copied
-	} else {
-		result = map[string]any{}
+// layeredValues is the outcome of layering a release's values.
+type layeredValues struct {
+	// Values are the chart defaults with every user-supplied layer on top.
+	Values map[string]any
+	// User holds only the user-supplied layers (reused values, values files, --set flags).
+	User    values.Layers
+	Sources []string
+}
+
+func (a *Application) buildValues(ch *chart.Chart, opts RenderOptions, reused *reusedValues, prov *values.Provenance) (*layeredValues, error) {
+	layered, err := mergeValues(ch, opts, reused, prov)
+	if err != nil {
+		return nil, err
+	}
+	if err := values.Validate(ch.ValuesSchema, layered.Values); err != nil {
+		return nil, fmt.Errorf("validate values: %w", err)
 	}
+	return layered, nil
+}
 
-	sources := []string{"chart:values.yaml"}
+// mergeValues layers the chart defaults, reused values, values files and --set overrides without
+// validating them. A non-nil prov records where every value came from.
+func mergeValues(ch *chart.Chart, opts RenderOptions, reused *reusedValues, prov *values.Provenance) (*layeredValues, error) {
+	layered := &layeredValues{Sources: []string{"chart:values.yaml"}}
+	if prov != nil && ch.FS != nil {
+		// the loader keeps only the parsed values; re-read them for line numbers
+		data, _ := fs.ReadFile(ch.FS, chart.ValuesFile)
+		prov.Record("chart:"+chart.ValuesFile, ch.Values, data)
+	}
+
+	if reused != nil {
+		source := fmt.Sprintf("revision:%d", reused.Revision)
+		layered.User = layered.User.Append(reused.Layers...)
+		layered.Sources = append(layered.Sources, source)
+		for _, layer := range reused.Layers {
+			prov.Record(source, layer.Values, nil)
+			prov.RecordAssignments(source, layer.Set)
+		}
+	}
 
 	for _, path := range opts.ValueFiles {
-		contents, err := loadValuesFile(path)
+		contents, data, err := loadValuesFile(path)
 		if err != nil {
-			return nil, nil, fmt.Errorf("load values file %s: %w", path, err)
+			return nil, fmt.Errorf("load values file %s: %w", path, err)
 		}
-		result, err = values.Merge(result, contents)
+		layered.User = layered.User.Append(values.Layer{Values: contents})
+		layered.Sources = append(layered.Sources, path)
+		prov.Record(path, contents, data)
+	}
+
+	if !opts.SetValues.IsZero() {
+		layer, err := opts.SetValues.Layer()
 		if err != nil {
-			return nil, nil, fmt.Errorf("merge values file %s: %w", path, err)
+			return nil, err
+		}
+		layered.User = layered.User.Append(layer)
+		layered.Sources = append(layered.Sources, opts.SetValues.Sources()...)
+		if err := prov.RecordOverrides(opts.SetValues); err != nil {
+			return nil, err
 		}
-		sources = append(sources, path)
 	}
 
-	if len(opts.SetValues) > 0 {
-		setOverrides := buildSetOverrides(opts.SetValues)
-		if len(setOverrides) > 0 {
-			var err error
-			result, err = values.Merge(result, setOverrides)
-			if err != nil {
-				return nil, nil, fmt.Errorf("apply --set overrides: %w", err)
-			}
-			sources = append(sources, "cli:set")
-		}
+	// assignments are replayed over the chart defaults, so list indexes and nulls address them
+	merged, err := layered.User.Apply(ch.Values)
+	if err != nil {
+		return nil, err
+	}
+	layered.Values = merged
+	return layered, nil
+}
+
+// mergeFragments merges rendered fragments into one compose file using the configured merge engine.
+func (a *Application) mergeFragments(ctx context.Context, fragments []composeFragment, files map[string][]byte, releaseName string) ([]byte, []string, error) {
+	names := make([]string, 0, len(fragments))
+	for _, fragment := range fragments {
+		names = append(names, fragment.Name)
+	}
+
+	engine, err := dockercompose.ParseMergeEngine(a.Runtime.Config.MergeEngine)
+	if err != nil {
+		return nil, nil, err
+	}
+	if engine == dockercompose.MergeEngineNative {
+		data, err := mergeNative(fragments, releaseName)
+		return data, names, err
 	}
 
-	if err := values.Validate(ch.ValuesSchema, result); err != nil {
-		return nil, nil, fmt.Errorf("validate values: %w", err)
+	data, err := a.mergeWithDocker(ctx, fragments, files, releaseName)
+	if err != nil && engine == dockercompose.MergeEngineAuto && process.IsNotFound(err) {
+		// the native output is not normalized like docker's, so say which engine produced it
+		a.Runtime.Logger.Warn("docker compose not found; merged %s with the native engine", releaseName)
+		data, err = mergeNative(fragments, releaseName)
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, names, nil
+}
 
-	return result, sources, nil
+func mergeNative(fragments []composeFragment, releaseName string) ([]byte, error) {
+	docs := make([]dockercompose.Fragment, 0, len(fragments))
+	for _, fragment := range fragments {
+		docs = append(docs, dockercompose.Fragment{Name: fragment.Name, Data: fragment.Data})
+	}
+	return dockercompose.MergeNative(docs, releaseName)
 }
 
-func (a *Application) mergeFragments(ctx context.Context, fragments map[string][]byte, files map[string][]byte, releaseName string) ([]byte, []string, error) {
+// mergeWithDocker writes fragments and files to a temp dir and runs `docker compose config`.
+func (a *Application) mergeWithDocker(ctx context.Context, fragments []composeFragment, files map[string][]byte, releaseName string) ([]byte, error) {
 	tempDir, err := os.MkdirTemp("", "composepack-fragments-*")
 	if err != nil {
-		return nil, nil, fmt.Errorf("create temp directory: %w", err)
+		return nil, fmt.Errorf("create temp directory: %w", err)
 	}
 	defer os.RemoveAll(tempDir)
 
-	names := make([]string, 0, len(fragments))
-	for name := range fragments {
-		names = append(names, name)
-	}
-	sort.Strings(names)
-
 	var fragmentPaths []string
-	for _, name := range names {
-		dest := filepath.Join(tempDir, name)
+	for _, fragment := range fragments {
+		dest := filepath.Join(tempDir, filepath.FromSlash(fragment.Name))
 		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-			return nil, nil, fmt.Errorf("prepare fragment directory: %w", err)
+			return nil, fmt.Errorf("prepare fragment directory: %w", err)
 		}
-		if err := os.WriteFile(dest, fragments[name], 0o644); err != nil {
-			return nil, nil, fmt.Errorf("write fragment %s: %w", name, err)
+		if err := os.WriteFile(dest, fragment.Data, 0o644); err != nil {
+			return nil, fmt.Errorf("write fragment %s: %w", fragment.Name, err)
 		}
 		fragmentPaths = append(fragmentPaths, dest)
 	}
@@ -401,10 +791,10 @@ func (a *Application) mergeFragments(ctx context.Context, fragments map[string][
 		for path, data := range files {
 			target := filepath.Join(filesRoot, path)
 			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-				return nil, nil, fmt.Errorf("prepare file asset directory: %w", err)
+				return nil, fmt.Errorf("prepare file asset directory: %w", err)
 			}
 			if err := os.WriteFile(target, data, 0o644); err != nil {
-				return nil, nil, fmt.Errorf("write file asset %s: %w", path, err)
+				return nil, fmt.Errorf("write file asset %s: %w", path, err)
 			}
 		}
 	}
@@ -415,53 +805,27 @@ func (a *Application) mergeFragments(ctx context.Context, fragments map[string][
 		ProjectName:   releaseName,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	rendered := strings.ReplaceAll(string(data), tempDir, ".")
-	return []byte(rendered), names, nil
+	return []byte(strings.ReplaceAll(string(data), tempDir, ".")), nil
 }
 
-func loadValuesFile(path string) (map[string]any, error) {
+// loadValuesFile parses a values file and also returns its raw bytes.
+func loadValuesFile(path string) (map[string]any, []byte, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(data) == 0 {
-		return map[string]any{}, nil
+		return map[string]any{}, data, nil
 	}
 
 	var out map[string]any
 	if err := yaml.Unmarshal(data, &out); err != nil {
-		return nil, err
-	}
-	return out, nil
-}
-
-func buildSetOverrides(in map[string]string) map[string]any {
-	out := make(map[string]any, len(in))
-	for key, val := range in {
-		assignSetValue(out, strings.Split(key, "."), val)
-	}
-	return out
-}
-
-func assignSetValue(dst map[string]any, path []string, value string) {
-	if len(path) == 0 {
-		return
-	}
-	key := path[0]
-	if len(path) == 1 {
-		dst[key] = value
-		return
-	}
-
-	next, ok := dst[key].(map[string]any)
-	if !ok {
-		next = map[string]any{}
-		dst[key] = next
+		return nil, nil, err
 	}
-	assignSetValue(next, path[1:], value)
+	return out, data, nil
 }
 
 func deepCopyMap(src map[string]any) map[string]any {
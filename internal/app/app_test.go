@@ -0,0 +1,137 @@
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"composepack/internal/infra/cache"
+	"composepack/internal/infra/config"
+)
+
+// newTestApp merges natively and keeps releases under the default relative base directory.
+func newTestApp(t *testing.T) *Application {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cfg := config.Default()
+	cfg.MergeEngine = "native"
+	rt := NewRuntime(cfg, nil, nil, &cache.Cache{Dir: t.TempDir()})
+	rt.Stdin = strings.NewReader("")
+	rt.Stdout, rt.Stderr = io.Discard, io.Discard
+	return NewApplication(rt)
+}
+
+// writeTestChart writes a minimal chart; files maps chart-relative paths to extra content.
+func writeTestChart(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	all := map[string]string{
+		"Chart.yaml":                     "name: demo\nversion: 0.1.0\n",
+		"values.yaml":                    "image: nginx\n",
+		"templates/compose/app.tpl.yaml": "services:\n  web:\n    image: {{ .Values.image }}\n",
+	}
+	for name, content := range files {
+		all[name] = content
+	}
+	for name, content := range all {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func archiveNames(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return names
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+}
+
+func TestRenderFromInsideChartDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTestChart(t, dir, map[string]string{
+		"tests/web_test.yaml": "suite: web\ntests: []\n",
+		"Chart.yaml~":         "editor backup\n",
+	})
+	chdir(t, dir)
+	a := newTestApp(t)
+	ctx := context.Background()
+
+	var digests []string
+	var sizes []int64
+	for i := 0; i < 4; i++ {
+		err := a.TemplateRelease(ctx, TemplateOptions{RenderOptions: RenderOptions{ReleaseName: "demo", ChartSource: "."}})
+		if err != nil {
+			t.Fatalf("render %d: %v", i+1, err)
+		}
+		runtimeDir := filepath.Join(dir, ".cpack-releases", "demo")
+		meta, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		digests = append(digests, meta.ChartDigest)
+
+		archive := a.Runtime.History.ChartPath(runtimeDir, meta.Revision)
+		info, err := os.Stat(archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0o600 {
+			t.Errorf("stored chart has mode %v, want 0600", perm)
+		}
+		sizes = append(sizes, info.Size())
+		for _, name := range archiveNames(t, archive) {
+			if strings.HasPrefix(name, ".cpack-releases") || strings.HasPrefix(name, "tests") || strings.HasSuffix(name, "~") {
+				t.Errorf("stored chart contains %s", name)
+			}
+		}
+	}
+	for i := 1; i < len(digests); i++ {
+		if digests[i] != digests[0] || sizes[i] != sizes[0] {
+			t.Errorf("render %d: digest %s, %d bytes; first render: %s, %d bytes", i+1, digests[i], sizes[i], digests[0], sizes[0])
+		}
+	}
+	if !bytes.HasPrefix([]byte(digests[0]), []byte("sha256:")) {
+		t.Errorf("chart digest = %q", digests[0])
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/core/templating"
+	"composepack/internal/core/values"
+)
+
+// globalValuesKey is shared from a parent chart with every subchart.
+const globalValuesKey = "global"
+
+// composeFragment is one rendered compose template named relative to the root chart.
+type composeFragment struct {
+	Name string
+	Data []byte
+}
+
+// renderChartTree renders a chart and its enabled subcharts. Subchart fragments come first so the
+// parent chart can override them; all rendered files share the single release files/ tree.
+func (a *Application) renderChartTree(ctx context.Context, ch *chart.Chart, vals map[string]any, releaseName, prefix string, env map[string]string) ([]composeFragment, map[string][]byte, error) {
+	var fragments []composeFragment
+	files := map[string][]byte{}
+
+	for _, sc := range ch.Subcharts {
+		if !dependencyEnabled(sc.Dependency, vals) {
+			continue
+		}
+		key := sc.Dependency.Key()
+		subVals, err := subchartValues(sc, vals)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := values.Validate(sc.Chart.ValuesSchema, subVals); err != nil {
+			return nil, nil, fmt.Errorf("validate values for dependency %s: %w", key, err)
+		}
+
+		subPrefix := prefix + chart.ChartsDir + "/" + key + "/"
+		subFragments, subFiles, err := a.renderChartTree(ctx, sc.Chart, subVals, releaseName, subPrefix, env)
+		if err != nil {
+			return nil, nil, fmt.Errorf("dependency %s: %w", key, err)
+		}
+		fragments = append(fragments, subFragments...)
+		if err := mergeFileAssets(files, subFiles, key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	rc := newRenderContext(ch, vals, releaseName, env)
+
+	rendered, err := a.Runtime.TemplateEngine.RenderComposeFragments(ctx, ch, rc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("render compose templates: %w", err)
+	}
+	names := make([]string, 0, len(rendered))
+	for name := range rendered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fragments = append(fragments, composeFragment{Name: prefix + name, Data: rendered[name]})
+	}
+
+	ownFiles, err := a.Runtime.TemplateEngine.RenderFiles(ctx, ch, rc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("render file templates: %w", err)
+	}
+	if err := mergeFileAssets(files, ownFiles, ch.Metadata.Name); err != nil {
+		return nil, nil, err
+	}
+
+	return fragments, files, nil
+}
+
+// newRenderContext builds the data a chart's templates render against.
+func newRenderContext(ch *chart.Chart, vals map[string]any, releaseName string, env map[string]string) templating.RenderContext {
+	return templating.RenderContext{
+		Values: vals,
+		Env:    env,
+		Release: templating.ReleaseInfo{
+			Name: releaseName,
+		},
+		Chart: ch.Metadata,
+		Files: templating.NewFilesAccessor(ch.StaticFiles),
+	}
+}
+
+// subchartValues builds a subchart's `.Values`: its defaults, overridden by the parent's
+// `<alias|name>:` section, plus the parent's `global:` section.
+func subchartValues(sc *chart.Subchart, parent map[string]any) (map[string]any, error) {
+	own, _ := parent[sc.Dependency.Key()].(map[string]any)
+	merged, err := values.Merge(sc.Chart.Values, own)
+	if err != nil {
+		return nil, fmt.Errorf("merge values for dependency %s: %w", sc.Dependency.Key(), err)
+	}
+
+	if parentGlobal, ok := parent[globalValuesKey].(map[string]any); ok {
+		subGlobal, _ := merged[globalValuesKey].(map[string]any)
+		global, err := values.Merge(subGlobal, parentGlobal)
+		if err != nil {
+			return nil, fmt.Errorf("merge global values for dependency %s: %w", sc.Dependency.Key(), err)
+		}
+		merged[globalValuesKey] = global
+	}
+	return merged, nil
+}
+
+// dependencyEnabled evaluates a Helm-style condition: comma-separated dotted paths where the
+// first path resolving to a bool (or bool string) decides. Missing or non-bool values leave the dependency enabled.
+func dependencyEnabled(dep chart.Dependency, vals map[string]any) bool {
+	if dep.Condition == "" {
+		return true
+	}
+	for _, path := range strings.Split(dep.Condition, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		switch v := lookupPath(vals, strings.Split(path, ".")).(type) {
+		case bool:
+			return v
+		case string:
+			// --set-string values arrive as strings.
+			if enabled, err := strconv.ParseBool(v); err == nil {
+				return enabled
+			}
+		}
+	}
+	return true
+}
+
+func lookupPath(vals map[string]any, path []string) any {
+	var current any = vals
+	for _, key := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil
+		}
+	}
+	return current
+}
+
+// mergeFileAssets adds src to dst; charts may ship the same file only with identical content.
+func mergeFileAssets(dst, src map[string][]byte, owner string) error {
+	for name, data := range src {
+		if existing, exists := dst[name]; exists && !bytes.Equal(existing, data) {
+			return fmt.Errorf("file %s from %s conflicts with a file rendered by another chart", name, owner)
+		}
+		dst[name] = data
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"context"
+	"path"
+	"sort"
+
+	"composepack/internal/core/diff"
+	"composepack/internal/core/hooks"
+)
+
+// DiffOptions render a release in memory and compare it with its runtime directory.
+type DiffOptions struct {
+	RenderOptions
+	// Context is the number of unchanged lines shown around each change.
+	Context int
+}
+
+// FileDiff is the unified diff of a single runtime file.
+type FileDiff struct {
+	Path    string
+	Unified string
+}
+
+// DiffResult describes what an upgrade would change in the runtime directory.
+type DiffResult struct {
+	Files    []FileDiff
+	Services []diff.ServiceChange
+}
+
+// Changed reports whether the rendered release differs from the runtime directory.
+func (r *DiffResult) Changed() bool {
+	return len(r.Files) > 0 || len(r.Services) > 0
+}
+
+// DiffRelease runs the full render pipeline in memory and diffs it against the current runtime.
+func (a *Application) DiffRelease(ctx context.Context, opts DiffOptions) (*DiffResult, error) {
+	rendered, err := a.render(ctx, opts.RenderOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	_, runtimeDir, err := a.resolveRuntimeLocation(opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := a.Runtime.RuntimeWriter.Read(ctx, runtimeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{}
+	if unified := diff.Unified("current/docker-compose.yaml", "rendered/docker-compose.yaml", current.ComposeYAML, rendered.ComposeYAML, opts.Context); unified != "" {
+		result.Files = append(result.Files, FileDiff{Path: "docker-compose.yaml", Unified: unified})
+	}
+	if unified := diff.Unified("current/"+hooks.FileName, "rendered/"+hooks.FileName, current.HooksYAML, rendered.HooksYAML, opts.Context); unified != "" {
+		result.Files = append(result.Files, FileDiff{Path: hooks.FileName, Unified: unified})
+	}
+
+	names := map[string]struct{}{}
+	for name := range current.Files {
+		names[name] = struct{}{}
+	}
+	for name := range rendered.Files {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		rel := path.Join("files", name)
+		before, hadBefore := current.Files[name]
+		after, hasAfter := rendered.Files[name]
+		beforeName, afterName := "current/"+rel, "rendered/"+rel
+		if !hadBefore {
+			beforeName = "/dev/null"
+		}
+		if !hasAfter {
+			afterName = "/dev/null"
+		}
+		if unified := diff.Unified(beforeName, afterName, before, after, opts.Context); unified != "" {
+			result.Files = append(result.Files, FileDiff{Path: rel, Unified: unified})
+		}
+	}
+
+	services, err := diff.Services(current.ComposeYAML, rendered.ComposeYAML)
+	if err != nil {
+		return nil, err
+	}
+	result.Services = services
+
+	return result, nil
+}
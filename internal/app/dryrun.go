@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+
+	"composepack/internal/core/hooks"
+	releaseruntime "composepack/internal/core/runtime"
+)
+
+// DryRunOptions make install/up/template print the rendered release instead of writing it.
+type DryRunOptions struct {
+	Enabled bool
+	// ShowFiles lists rendered files/ entries after the compose YAML.
+	ShowFiles bool
+	// ShowFileContents prints every rendered file (implies ShowFiles).
+	ShowFileContents bool
+}
+
+// dryRun renders the release in memory and prints the result to the runtime stdout.
+func (a *Application) dryRun(ctx context.Context, opts RenderOptions, dry DryRunOptions) error {
+	rendered, err := a.render(ctx, opts)
+	if err != nil {
+		return err
+	}
+	return writeDryRun(a.Runtime.Stdout, rendered, dry)
+}
+
+// renderToDir writes the rendered compose file and files/ tree into dir without release metadata.
+func (a *Application) renderToDir(ctx context.Context, opts RenderOptions, dir string) error {
+	rendered, err := a.render(ctx, opts)
+	if err != nil {
+		return err
+	}
+	target, err := a.Runtime.RuntimeWriter.Write(ctx, releaseruntime.WriteOptions{
+		Dir:         dir,
+		ComposeYAML: rendered.ComposeYAML,
+		HooksYAML:   rendered.HooksYAML,
+		Files:       rendered.Files,
+	})
+	if err != nil {
+		return fmt.Errorf("write output directory: %w", err)
+	}
+	fmt.Fprintf(a.Runtime.Stdout, "Rendered %s into %s\n", opts.ReleaseName, target)
+	return nil
+}
+
+func writeDryRun(out io.Writer, rendered *renderedRelease, dry DryRunOptions) error {
+	if out == nil {
+		out = io.Discard
+	}
+
+	fmt.Fprintln(out, "# Source: docker-compose.yaml")
+	if _, err := out.Write(rendered.ComposeYAML); err != nil {
+		return err
+	}
+	if len(rendered.HooksYAML) > 0 {
+		fmt.Fprintln(out, "---")
+		fmt.Fprintf(out, "# Source: %s\n", hooks.FileName)
+		if _, err := out.Write(rendered.HooksYAML); err != nil {
+			return err
+		}
+	}
+
+	if !dry.ShowFiles && !dry.ShowFileContents {
+		return nil
+	}
+
+	names := make([]string, 0, len(rendered.Files))
+	for name := range rendered.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !dry.ShowFileContents {
+		fmt.Fprintln(out, "---")
+		fmt.Fprintln(out, "# Files:")
+		for _, name := range names {
+			fmt.Fprintf(out, "#   %s (%d bytes)\n", path.Join("files", name), len(rendered.Files[name]))
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		fmt.Fprintln(out, "---")
+		fmt.Fprintf(out, "# Source: %s\n", path.Join("files", name))
+		data := rendered.Files[name]
+		if _, err := out.Write(data); err != nil {
+			return err
+		}
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			fmt.Fprintln(out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"composepack/internal/core/release"
+)
+
+func TestRollbackLifecycle(t *testing.T) {
+	state := fakeDocker(t)
+	chartDir := t.TempDir()
+	writeTestChart(t, chartDir, nil)
+	chdir(t, t.TempDir())
+	a := newTestApp(t)
+	ctx := context.Background()
+	render := RenderOptions{ReleaseName: "demo", ChartSource: chartDir, ResetValues: true, MaxHistory: 2}
+	runtimeDir := filepath.Join(".cpack-releases", "demo")
+	setImage := func(image string) {
+		writeTestChart(t, chartDir, map[string]string{"values.yaml": "image: " + image + "\n"})
+	}
+	setExit := func(code string) {
+		if err := os.WriteFile(filepath.Join(state, "exit"), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	statuses := func() map[int]release.Status {
+		t.Helper()
+		revisions, err := a.ReleaseHistory(ctx, HistoryOptions{ReleaseName: "demo"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := map[int]release.Status{}
+		for _, meta := range revisions {
+			out[meta.Revision] = meta.Status
+		}
+		return out
+	}
+	expect := func(step string, want map[int]release.Status) {
+		t.Helper()
+		got := statuses()
+		if len(got) != len(want) {
+			t.Fatalf("%s: revisions = %v, want %v", step, got, want)
+		}
+		for revision, status := range want {
+			if got[revision] != status {
+				t.Fatalf("%s: revisions = %v, want %v", step, got, want)
+			}
+		}
+	}
+
+	if err := a.InstallRelease(ctx, InstallOptions{RenderOptions: render, AutoStart: true}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	expect("install", map[int]release.Status{1: release.StatusDeployed})
+
+	setImage("redis")
+	if err := a.UpRelease(ctx, UpOptions{RenderOptions: render, Detach: true}); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	expect("upgrade", map[int]release.Status{1: release.StatusSuperseded, 2: release.StatusDeployed})
+
+	setImage("broken")
+	setExit("1")
+	if err := a.UpRelease(ctx, UpOptions{RenderOptions: render, Detach: true}); err == nil {
+		t.Fatal("failing upgrade succeeded")
+	}
+	// recording revision 3 supersedes 2 and prunes 1 before compose runs
+	expect("failed upgrade", map[int]release.Status{2: release.StatusSuperseded, 3: release.StatusFailed})
+
+	setExit("0")
+	if err := a.RollbackRelease(ctx, RollbackOptions{ReleaseName: "demo", MaxHistory: 2}); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	expect("rollback", map[int]release.Status{3: release.StatusFailed, 4: release.StatusDeployed})
+
+	current, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Revision != 4 || current.Status != release.StatusDeployed || current.Description != "Rollback to 2" {
+		t.Errorf("release.json = revision %d, %s, %q", current.Revision, current.Status, current.Description)
+	}
+	compose, err := os.ReadFile(filepath.Join(runtimeDir, "docker-compose.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(compose), "image: redis") {
+		t.Errorf("rollback did not restore revision 2:\n%s", compose)
+	}
+
+	if err := a.RollbackRelease(ctx, RollbackOptions{ReleaseName: "demo", Revision: 1, MaxHistory: 2}); err == nil || !strings.Contains(err.Error(), "revision 1 not found") {
+		t.Errorf("rollback to a pruned revision: %v", err)
+	}
+}
+
+func TestListKeepsFirstCreatedAfterPruning(t *testing.T) {
+	chartDir := t.TempDir()
+	writeTestChart(t, chartDir, nil)
+	chdir(t, t.TempDir())
+	a := newTestApp(t)
+	ctx := context.Background()
+	runtimeDir := filepath.Join(".cpack-releases", "demo")
+
+	var first, last *release.Metadata
+	for i := 0; i < 3; i++ {
+		err := a.TemplateRelease(ctx, TemplateOptions{RenderOptions: RenderOptions{ReleaseName: "demo", ChartSource: chartDir, MaxHistory: 1}})
+		if err != nil {
+			t.Fatalf("render %d: %v", i+1, err)
+		}
+		meta, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == nil {
+			first = meta
+		}
+		last = meta
+	}
+	if revisions, _ := a.ReleaseHistory(ctx, HistoryOptions{ReleaseName: "demo"}); len(revisions) != 1 {
+		t.Fatalf("kept %d revisions, want 1", len(revisions))
+	}
+
+	releases, err := a.ListReleases(ctx, ListOptions{SkipLiveStatus: true})
+	if err != nil || len(releases) != 1 {
+		t.Fatalf("ListReleases = %v, %v", releases, err)
+	}
+	if got := releases[0]; !got.Created.Equal(first.CreatedAt) || !got.Updated.Equal(last.CreatedAt) || got.Created.Equal(got.Updated) {
+		t.Errorf("created %v, updated %v; want %v and %v", got.Created, got.Updated, first.CreatedAt, last.CreatedAt)
+	}
+	if !last.FirstCreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("release.json firstCreatedAt = %v, want %v", last.FirstCreatedAt, first.CreatedAt)
+	}
+}
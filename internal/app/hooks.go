@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"composepack/internal/core/dockercompose"
+	"composepack/internal/core/hooks"
+	"composepack/internal/core/release"
+	"composepack/internal/infra/process"
+)
+
+// runHooks runs every hook subscribed to event in weight order with `docker compose run`,
+// appending the outcome to meta. The first failing hook aborts the remaining ones.
+func (a *Application) runHooks(ctx context.Context, runtimeDir string, meta *release.Metadata, event hooks.Event) error {
+	data, err := os.ReadFile(filepath.Join(runtimeDir, hooks.FileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("read hooks file: %w", err)
+	}
+	defined, err := hooks.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks.ForEvent(defined, event) {
+		container := hookContainerName(runtimeDir, hook.Service)
+		// a container kept by an earlier run (a failed hook-succeeded hook, say) would make
+		// `run --name` fail, so it always goes first, as with Helm's before-hook-creation default
+		if err := a.Runtime.DockerRunner.RemoveContainer(ctx, container); err != nil {
+			return fmt.Errorf("%s hook %s: %w", event, hook.Service, err)
+		}
+
+		args := []string{"-f", "docker-compose.yaml", "-f", hooks.FileName, "run", "--name", container}
+		if hook.AutoRemove() {
+			args = append(args, "--rm")
+		}
+		if event == hooks.PostDelete {
+			// the release's services are already gone; do not start them again
+			args = append(args, "--no-deps")
+		}
+		if !process.IsTerminal(a.Runtime.Stdout) {
+			args = append(args, "-T")
+		}
+		args = append(args, hook.Service)
+
+		fmt.Fprintf(a.Runtime.Stderr, "Running %s hook %s\n", event, hook.Service)
+		start := time.Now()
+		runErr := a.Runtime.DockerRunner.Stream(ctx, dockercompose.CommandOptions{
+			WorkingDir: runtimeDir,
+			Args:       args,
+			Stdin:      a.Runtime.Stdin,
+			Stdout:     a.Runtime.Stdout,
+			Stderr:     a.Runtime.Stderr,
+			TTY:        process.IsTerminal(a.Runtime.Stdout),
+		})
+
+		run := release.HookRun{
+			Service:   hook.Service,
+			Event:     string(event),
+			Succeeded: runErr == nil,
+			StartedAt: start.UTC(),
+			Duration:  time.Since(start).Round(time.Millisecond).String(),
+		}
+		if runErr != nil {
+			run.Error = runErr.Error()
+		}
+		meta.Hooks = append(meta.Hooks, run)
+
+		if (runErr == nil && hook.HasPolicy(hooks.HookSucceeded)) || (runErr != nil && hook.HasPolicy(hooks.HookFailed)) {
+			if err := a.Runtime.DockerRunner.RemoveContainer(context.WithoutCancel(ctx), container); err != nil && runErr == nil {
+				return fmt.Errorf("%s hook %s: %w", event, hook.Service, err)
+			}
+		}
+		if runErr != nil {
+			return fmt.Errorf("%s hook %s failed: %w", event, hook.Service, runErr)
+		}
+	}
+	return nil
+}
+
+// hookContainerName mirrors Compose's default project name (the runtime directory name).
+func hookContainerName(runtimeDir, service string) string {
+	project := strings.ToLower(filepath.Base(runtimeDir))
+	return fmt.Sprintf("%s-%s-hook", project, service)
+}
+
+// composeFileArgs selects the hooks file alongside the main compose file when it exists so
+// commands such as down also clean up hook containers.
+func composeFileArgs(runtimeDir string) []string {
+	if _, err := os.Stat(filepath.Join(runtimeDir, hooks.FileName)); err != nil {
+		return nil
+	}
+	return []string{"-f", "docker-compose.yaml", "-f", hooks.FileName}
+}
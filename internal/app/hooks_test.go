@@ -0,0 +1,118 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"composepack/internal/core/hooks"
+	"composepack/internal/core/release"
+)
+
+// fakeDocker puts a docker on PATH that logs its arguments and tracks named containers the
+// way the daemon does: `run --name` fails while a container of that name exists, and only
+// `--rm` or `rm -f` gets rid of one. Every command but rm exits with the code in the exit file.
+func fakeDocker(t *testing.T) (state string) {
+	t.Helper()
+	bin, state := t.TempDir(), t.TempDir()
+	script := `#!/bin/sh
+state=` + state + `
+echo "$*" >> "$state/calls.log"
+if [ "$1" = rm ]; then
+  rm -f "$state/container-$3"
+  exit 0
+fi
+name= autoremove=
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --name) name=$2; shift ;;
+    --rm) autoremove=1 ;;
+  esac
+  shift
+done
+if [ -n "$name" ]; then
+  if [ -e "$state/container-$name" ]; then
+    echo "Conflict. The container name \"/$name\" is already in use." >&2
+    exit 1
+  fi
+  [ -z "$autoremove" ] && touch "$state/container-$name"
+fi
+exit $(cat "$state/exit" 2>/dev/null || echo 0)
+`
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return state
+}
+
+func TestRunHooksRerunsAfterFailure(t *testing.T) {
+	state := fakeDocker(t)
+	runtimeDir := filepath.Join(t.TempDir(), "demo")
+	hooksFile := `services:
+  migrate:
+    image: busybox
+    x-composepack-hook: {events: pre-upgrade, delete-policy: hook-succeeded}
+`
+	if err := os.MkdirAll(runtimeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runtimeDir, hooks.FileName), []byte(hooksFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t)
+	ctx := context.Background()
+	container := filepath.Join(state, "container-demo-migrate-hook")
+
+	os.WriteFile(filepath.Join(state, "exit"), []byte("3"), 0o644)
+	meta := &release.Metadata{}
+	if err := a.runHooks(ctx, runtimeDir, meta, hooks.PreUpgrade); err == nil {
+		t.Fatal("failing hook did not abort")
+	}
+	if _, err := os.Stat(container); err != nil {
+		t.Error("hook-succeeded removed the container of a failed hook")
+	}
+
+	os.WriteFile(filepath.Join(state, "exit"), []byte("0"), 0o644)
+	if err := a.runHooks(ctx, runtimeDir, meta, hooks.PreUpgrade); err != nil {
+		t.Fatalf("rerun after a failure: %v", err)
+	}
+	if _, err := os.Stat(container); !os.IsNotExist(err) {
+		t.Error("succeeded hook left its container behind")
+	}
+	if len(meta.Hooks) != 2 || meta.Hooks[0].Succeeded || !meta.Hooks[1].Succeeded {
+		t.Errorf("recorded hook runs = %+v", meta.Hooks)
+	}
+
+	calls, err := os.ReadFile(filepath.Join(state, "calls.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range strings.Split(strings.TrimSpace(string(calls)), "\n") {
+		if strings.Contains(call, " run ") && strings.Contains(call, "--rm") {
+			t.Errorf("hook with a delete policy ran with --rm: %s", call)
+		}
+	}
+}
+
+func TestRunHooksWithoutPolicyAutoRemoves(t *testing.T) {
+	state := fakeDocker(t)
+	runtimeDir := filepath.Join(t.TempDir(), "demo")
+	hooksFile := "services:\n  seed:\n    image: busybox\n    x-composepack-hook: post-install\n"
+	if err := os.MkdirAll(runtimeDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runtimeDir, hooks.FileName), []byte(hooksFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a := newTestApp(t)
+	os.WriteFile(filepath.Join(state, "exit"), []byte("1"), 0o644)
+	if err := a.runHooks(context.Background(), runtimeDir, &release.Metadata{}, hooks.PostInstall); err == nil {
+		t.Fatal("failing hook did not abort")
+	}
+	if _, err := os.Stat(filepath.Join(state, "container-demo-seed-hook")); !os.IsNotExist(err) {
+		t.Error("hook without a delete policy kept its container")
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/core/lint"
+	"composepack/internal/core/values"
+)
+
+// lintReleaseName stands in for the release name while linting.
+const lintReleaseName = "release-name"
+
+// LintOptions select the chart to lint and the optional values it is also rendered with.
+type LintOptions struct {
+	ChartSource  string
+	ChartVersion string
+	ValueFiles   []string
+	SetValues    values.Overrides
+}
+
+// LintChart loads, validates and renders a chart and reports everything suspicious about it.
+// Problems with the chart become report issues; the error is reserved for lint itself failing.
+func (a *Application) LintChart(ctx context.Context, opts LintOptions) (*lint.Report, error) {
+	if opts.ChartSource == "" {
+		return nil, errors.New("chart source must be provided")
+	}
+
+	report := &lint.Report{Chart: opts.ChartSource}
+	ch, err := a.loadChart(ctx, RenderOptions{ChartSource: opts.ChartSource, ChartVersion: opts.ChartVersion})
+	if err != nil {
+		report.Add(lint.Issue{Severity: lint.SeverityError, Message: err.Error()})
+		return report, nil
+	}
+	report.Chart = ch.Metadata.Name
+	report.Version = ch.Metadata.Version
+
+	report.Add(lint.CheckMetadata(ch.Metadata)...)
+	report.Add(lint.CheckHelpers(ch, "")...)
+
+	a.lintRender(ctx, report, ch, RenderOptions{ReleaseName: lintReleaseName}, "")
+	if len(opts.ValueFiles) > 0 || !opts.SetValues.IsZero() {
+		a.lintRender(ctx, report, ch, RenderOptions{
+			ReleaseName: lintReleaseName,
+			ValueFiles:  opts.ValueFiles,
+			SetValues:   opts.SetValues,
+		}, "with supplied values: ")
+	}
+	return report, nil
+}
+
+// lintRender renders the chart with opts' values, merges fragments natively (lint never needs
+// docker) and checks the result. label prefixes issues that only this render produced.
+func (a *Application) lintRender(ctx context.Context, report *lint.Report, ch *chart.Chart, opts RenderOptions, label string) {
+	fail := func(path string, err error) {
+		report.Add(lint.Issue{Severity: lint.SeverityError, Path: path, Message: label + err.Error()})
+	}
+
+	layered, err := mergeValues(ch, opts, nil, nil)
+	if err != nil {
+		fail("", err)
+		return
+	}
+	vals := layered.Values
+	if err := values.Validate(ch.ValuesSchema, vals); err != nil {
+		path := ""
+		if label == "" {
+			path = chart.ValuesFile
+		}
+		fail(path, fmt.Errorf("does not match %s: %w", chart.ValuesSchemaFile, err))
+	}
+
+	env := captureEnv()
+	if _, err := a.Runtime.TemplateEngine.RenderNotes(ctx, ch, newRenderContext(ch, vals, opts.ReleaseName, env)); err != nil {
+		fail(chart.NotesFile, err)
+	}
+
+	fragments, files, err := a.renderChartTree(ctx, ch, vals, opts.ReleaseName, "", env)
+	if err != nil {
+		fail("", err)
+		return
+	}
+	if len(fragments) == 0 {
+		fail(chart.TemplatesCompose, errors.New("chart produced no compose templates"))
+		return
+	}
+	merged, err := mergeNative(fragments, opts.ReleaseName)
+	if err != nil {
+		fail("", fmt.Errorf("merge compose fragments: %w", err))
+		return
+	}
+	for _, issue := range lint.CheckCompose(merged, files) {
+		if !report.Has(issue) {
+			issue.Message = label + issue.Message
+			report.Add(issue)
+		}
+	}
+}
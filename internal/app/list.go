@@ -0,0 +1,135 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"composepack/internal/core/release"
+)
+
+// Live statuses reported by ListReleases.
+const (
+	LiveStatusRunning = "running"
+	LiveStatusPartial = "partial"
+	LiveStatusStopped = "stopped"
+	LiveStatusUnknown = "unknown"
+)
+
+// ListOptions filter and tune release enumeration.
+type ListOptions struct {
+	RuntimeBaseDir string
+	// ChartName keeps only releases installed from this chart.
+	ChartName string
+	// SkipLiveStatus avoids calling docker compose ps for every release.
+	SkipLiveStatus bool
+}
+
+// ReleaseSummary is one row of `composepack list`.
+type ReleaseSummary struct {
+	Name           string         `json:"name"`
+	Chart          string         `json:"chart"`
+	ChartVersion   string         `json:"chartVersion"`
+	ChartDigest    string         `json:"chartDigest,omitempty"`
+	RenderedDigest string         `json:"renderedDigest,omitempty"`
+	Created        time.Time      `json:"created"`
+	Updated        time.Time      `json:"updated"`
+	Revision       int            `json:"revision"`
+	Status         release.Status `json:"status"`
+	Live           string         `json:"live"`
+	Containers     string         `json:"containers,omitempty"`
+	RuntimePath    string         `json:"runtimePath"`
+}
+
+// ListReleases scans the releases base directory and summarizes every release.json found.
+func (a *Application) ListReleases(ctx context.Context, opts ListOptions) ([]ReleaseSummary, error) {
+	baseDir, err := a.resolveBaseDir(opts.RuntimeBaseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read releases directory: %w", err)
+	}
+
+	var out []ReleaseSummary
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		runtimeDir := filepath.Join(baseDir, entry.Name())
+		meta, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+		if err != nil {
+			a.Runtime.Logger.Warn("skipping release %s: %v", entry.Name(), err)
+			continue
+		}
+		if meta == nil {
+			continue
+		}
+		if opts.ChartName != "" && meta.ChartMetadata.Name != opts.ChartName {
+			continue
+		}
+
+		summary := ReleaseSummary{
+			Name:           meta.ReleaseName,
+			Chart:          meta.ChartMetadata.Name,
+			ChartVersion:   meta.ChartMetadata.Version,
+			ChartDigest:    meta.ChartDigest,
+			RenderedDigest: meta.RenderedDigest,
+			Created:        a.firstCreatedAt(ctx, runtimeDir, meta),
+			Updated:        meta.CreatedAt,
+			Revision:       meta.Revision,
+			Status:         meta.Status,
+			Live:           LiveStatusUnknown,
+			RuntimePath:    runtimeDir,
+		}
+		if summary.Name == "" {
+			summary.Name = entry.Name()
+		}
+		if !opts.SkipLiveStatus {
+			summary.Live, summary.Containers = a.liveStatus(ctx, runtimeDir)
+		}
+		out = append(out, summary)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// liveStatus derives a coarse state from docker compose ps; failures degrade to "unknown".
+func (a *Application) liveStatus(ctx context.Context, runtimeDir string) (string, string) {
+	containers, err := a.Runtime.DockerRunner.PS(ctx, runtimeDir)
+	if err != nil {
+		return LiveStatusUnknown, ""
+	}
+	if len(containers) == 0 {
+		return LiveStatusStopped, "0/0"
+	}
+
+	running := 0
+	for _, c := range containers {
+		if c.Running() {
+			running++
+		}
+	}
+	counts := fmt.Sprintf("%d/%d", running, len(containers))
+	switch {
+	case running == len(containers):
+		return LiveStatusRunning, counts
+	case running == 0:
+		return LiveStatusStopped, counts
+	default:
+		return LiveStatusPartial, counts
+	}
+}
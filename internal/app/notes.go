@@ -0,0 +1,40 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// NotesOptions select the release revision whose notes should be shown.
+type NotesOptions struct {
+	ReleaseName    string
+	RuntimeBaseDir string
+	RuntimePath    string
+	// Revision selects a stored revision; 0 selects the current one.
+	Revision int
+}
+
+// ReleaseNotes returns the rendered NOTES.txt stored with a release revision; it is empty
+// when the chart ships no notes.
+func (a *Application) ReleaseNotes(ctx context.Context, opts NotesOptions) ([]byte, error) {
+	rev, err := a.loadRevision(ctx, opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath, opts.Revision)
+	if err != nil {
+		return nil, err
+	}
+	return rev.Notes, nil
+}
+
+// printNotes writes the notes recorded with revision to stdout after a successful install or up.
+func (a *Application) printNotes(ctx context.Context, runtimeDir string, revision int) error {
+	rev, err := a.Runtime.History.Get(ctx, runtimeDir, revision)
+	if err != nil {
+		return fmt.Errorf("read release notes: %w", err)
+	}
+	notes := bytes.TrimRight(rev.Notes, "\n")
+	if len(bytes.TrimSpace(notes)) == 0 {
+		return nil
+	}
+	_, err = fmt.Fprintf(a.Runtime.Stdout, "\nNOTES:\n%s\n", notes)
+	return err
+}
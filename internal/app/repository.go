@@ -0,0 +1,144 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/core/release"
+	"composepack/internal/packager"
+	"composepack/internal/repo"
+)
+
+// loadChart loads the chart source, first resolving `repo/chart` references through the
+// configured repository indexes, and enforces the requested version and verification.
+func (a *Application) loadChart(ctx context.Context, opts RenderOptions) (*chart.Chart, error) {
+	version := opts.ChartVersion
+	resolved, err := a.resolveChartSource(ctx, opts.ChartSource, version)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := a.loadVerified(ctx, resolved, opts.Verify)
+	if err != nil {
+		return nil, fmt.Errorf("load chart: %w", err)
+	}
+	if version != "" && !chart.VersionMatches(version, ch.Metadata.Version) {
+		return nil, fmt.Errorf("chart %s version %s does not satisfy --version %s", ch.Metadata.Name, ch.Metadata.Version, version)
+	}
+	return ch, nil
+}
+
+// resolveChartSource maps `repo/chart` to a download URL when repo is a configured
+// repository; existing paths and every other source are returned unchanged.
+func (a *Application) resolveChartSource(ctx context.Context, source, version string) (string, error) {
+	if _, err := os.Stat(source); err == nil {
+		return source, nil
+	}
+	repoName, chartName, ok := repo.SplitChartRef(source)
+	if !ok {
+		return source, nil
+	}
+	manager, err := repo.NewManager()
+	if err != nil {
+		return "", err
+	}
+	manager.Offline = a.Runtime.Config.Offline
+	if _, known, err := manager.Get(repoName); err != nil || !known {
+		return source, err
+	}
+	url, _, err := manager.Resolve(ctx, repoName, chartName, version)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", source, err)
+	}
+	return url, nil
+}
+
+// loadReleaseChart loads opts.ChartSource or, when it is empty, the chart the release's current
+// revision was rendered from: its recorded source, or with UseStoredChart the copy stored in the
+// revision. The returned source is what the next revision should record.
+func (a *Application) loadReleaseChart(ctx context.Context, opts RenderOptions) (*chart.Chart, *release.ChartSource, error) {
+	if opts.ChartSource != "" {
+		if opts.UseStoredChart {
+			return nil, nil, errors.New("--use-stored-chart cannot be combined with --chart")
+		}
+		ch, err := a.loadChart(ctx, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ch, newChartSource(opts.ChartSource, ch), nil
+	}
+
+	_, runtimeDir, err := a.resolveRuntimeLocation(opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current == nil {
+		return nil, nil, fmt.Errorf("chart source must be provided: release %s is not installed in %s", opts.ReleaseName, runtimeDir)
+	}
+
+	if opts.UseStoredChart {
+		if opts.Verify.Enabled || opts.Verify.Keyring != "" || opts.Verify.Checksum != "" {
+			return nil, nil, errors.New("--use-stored-chart cannot be combined with chart verification")
+		}
+		path := a.Runtime.History.ChartPath(runtimeDir, current.Revision)
+		if _, err := os.Stat(path); err != nil {
+			return nil, nil, fmt.Errorf("revision %d of release %s has no stored chart; pass --chart", current.Revision, opts.ReleaseName)
+		}
+		ch, err := a.loadChart(ctx, RenderOptions{ChartSource: path, ChartVersion: opts.ChartVersion})
+		if err != nil {
+			return nil, nil, fmt.Errorf("stored chart of revision %d: %w", current.Revision, err)
+		}
+		return ch, current.ChartSource, nil
+	}
+
+	src := current.ChartSource
+	if src == nil {
+		return nil, nil, fmt.Errorf("chart source must be provided: release %s does not record the chart it was installed from", opts.ReleaseName)
+	}
+	load := opts
+	load.ChartSource = src.Ref
+	if load.ChartVersion == "" {
+		load.ChartVersion = src.Version
+	}
+	ch, err := a.loadChart(ctx, load)
+	if err != nil {
+		return nil, nil, fmt.Errorf("chart %s of release %s is unavailable (pass --chart, or --use-stored-chart to reuse the copy stored with revision %d): %w", src.Ref, opts.ReleaseName, current.Revision, err)
+	}
+	if src.Digest != "" && ch.ArchiveDigest != "" && src.Digest != ch.ArchiveDigest {
+		fmt.Fprintf(a.Runtime.Stderr, "Warning: chart %s changed since revision %d (digest %s, was %s)\n", src.Ref, current.Revision, ch.ArchiveDigest, src.Digest)
+	}
+	return ch, newChartSource(src.Ref, ch), nil
+}
+
+// newChartSource describes where ch was loaded from. Paths are made absolute so the release can
+// be upgraded from any directory, and `repo/chart` references are pinned to the loaded version.
+func newChartSource(ref string, ch *chart.Chart) *release.ChartSource {
+	src := &release.ChartSource{Ref: ref, Digest: ch.ArchiveDigest}
+	if _, err := os.Stat(ref); err == nil {
+		if abs, err := filepath.Abs(ref); err == nil {
+			src.Ref = abs
+		}
+		return src
+	}
+	if _, _, ok := repo.SplitChartRef(ref); ok {
+		src.Version = ch.Metadata.Version
+	}
+	return src
+}
+
+// archiveChart packs ch for storage with a revision.
+func archiveChart(ch *chart.Chart) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := packager.ArchiveChart(&buf, ch); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"composepack/internal/core/charttest"
+	"composepack/internal/core/values"
+)
+
+// TestChartOptions select the chart directory whose tests/ suites should run.
+type TestChartOptions struct {
+	ChartDir        string
+	UpdateSnapshots bool
+}
+
+// TestChart runs a chart's template unit tests. Every test renders the chart in memory with
+// the native merge engine, so no docker and no release directory are involved.
+func (a *Application) TestChart(ctx context.Context, opts TestChartOptions) (*charttest.Result, error) {
+	info, err := os.Stat(opts.ChartDir)
+	if err != nil {
+		return nil, fmt.Errorf("chart directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a chart directory; tests run against unpackaged charts", opts.ChartDir)
+	}
+
+	ch, err := a.loadChart(ctx, RenderOptions{ChartSource: opts.ChartDir})
+	if err != nil {
+		return nil, err
+	}
+
+	render := func(ctx context.Context, in charttest.RenderInput) (*charttest.Rendered, error) {
+		vals, err := values.Merge(deepCopyMap(ch.Values), in.Values)
+		if err != nil {
+			return nil, err
+		}
+		if err := values.Validate(ch.ValuesSchema, vals); err != nil {
+			return nil, fmt.Errorf("validate values: %w", err)
+		}
+		fragments, files, err := a.renderChartTree(ctx, ch, vals, in.Release, "", in.Env)
+		if err != nil {
+			return nil, err
+		}
+		if len(fragments) == 0 {
+			return nil, errors.New("chart produced no compose templates")
+		}
+		compose, err := mergeNative(fragments, in.Release)
+		if err != nil {
+			return nil, err
+		}
+		return &charttest.Rendered{ComposeYAML: compose, Files: files}, nil
+	}
+
+	return charttest.Run(ctx, opts.ChartDir, ch.Metadata.Name, render, charttest.Options{UpdateSnapshots: opts.UpdateSnapshots})
+}
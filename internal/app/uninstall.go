@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"composepack/internal/core/hooks"
+	"composepack/internal/core/release"
+)
+
+// UninstallOptions control tearing down and removing a release.
+type UninstallOptions struct {
+	ReleaseName    string
+	RuntimeBaseDir string
+	RuntimePath    string
+	RemoveVolumes  bool
+	// RemoveImages is passed to `docker compose down --rmi` ("local" or "all") when set.
+	RemoveImages string
+	// KeepHistory retains release.json and revisions/ for auditing and only removes runtime artifacts.
+	KeepHistory bool
+}
+
+// UninstallRelease runs docker compose down and removes the release runtime directory. It refuses
+// directories without a release.json.
+func (a *Application) UninstallRelease(ctx context.Context, opts UninstallOptions) error {
+	if opts.RemoveImages != "" && opts.RemoveImages != "local" && opts.RemoveImages != "all" {
+		return fmt.Errorf("invalid --rmi value %q (use local or all)", opts.RemoveImages)
+	}
+
+	_, runtimeDir, err := a.resolveRuntimeLocation(opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(runtimeDir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("release %s not found in %s", opts.ReleaseName, runtimeDir)
+		}
+		return err
+	}
+	// never tear down or delete a directory that merely shares the release's name
+	meta, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		return fmt.Errorf("%s is not a composepack release (no release.json)", runtimeDir)
+	}
+
+	composePath := filepath.Join(runtimeDir, "docker-compose.yaml")
+	if _, err := os.Stat(composePath); err == nil {
+		if err := a.DownRelease(ctx, DownOptions{
+			ReleaseName:    opts.ReleaseName,
+			RuntimeBaseDir: opts.RuntimeBaseDir,
+			RuntimePath:    opts.RuntimePath,
+			RemoveVolumes:  opts.RemoveVolumes,
+			RemoveImages:   opts.RemoveImages,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !opts.KeepHistory {
+		if err := os.RemoveAll(runtimeDir); err != nil {
+			return fmt.Errorf("remove runtime directory: %w", err)
+		}
+		return nil
+	}
+
+	for _, name := range []string{"docker-compose.yaml", hooks.FileName, "files"} {
+		if err := os.RemoveAll(filepath.Join(runtimeDir, name)); err != nil {
+			return fmt.Errorf("remove %s: %w", name, err)
+		}
+	}
+
+	meta.Status = release.StatusUninstalled
+	meta.Description = "Uninstalled"
+	if err := a.Runtime.ReleaseStore.Save(ctx, runtimeDir, meta); err != nil {
+		return fmt.Errorf("save release metadata: %w", err)
+	}
+	if meta.Revision > 0 {
+		if err := a.Runtime.History.SaveMetadata(ctx, runtimeDir, meta); err != nil {
+			return fmt.Errorf("update revision: %w", err)
+		}
+	}
+	return nil
+}
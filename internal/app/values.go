@@ -0,0 +1,97 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"composepack/internal/core/values"
+)
+
+// ValuesOptions select the release revision whose values should be shown.
+type ValuesOptions struct {
+	ReleaseName    string
+	RuntimeBaseDir string
+	RuntimePath    string
+	// Revision selects a stored revision; 0 selects the current one.
+	Revision int
+	// All returns the merged values including chart defaults.
+	All bool
+}
+
+// ReleaseValues returns the values stored with a release revision together with their
+// provenance, which is nil for revisions recorded before provenance was tracked. Only the
+// user-supplied values are returned unless opts.All asks for the fully merged ones.
+func (a *Application) ReleaseValues(ctx context.Context, opts ValuesOptions) (map[string]any, []values.Explanation, error) {
+	rev, err := a.loadRevision(ctx, opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath, opts.Revision)
+	if err != nil {
+		return nil, nil, err
+	}
+	vals := rev.Values
+	if !opts.All {
+		if vals, err = rev.UserValues.Apply(nil); err != nil {
+			return nil, nil, err
+		}
+	}
+	if vals == nil {
+		vals = map[string]any{}
+	}
+	return vals, rev.Provenance, nil
+}
+
+// ExplainValues merges the values a render with opts would use and explains where each one came
+// from. Values are not validated against the chart schema, so invalid values can be explained too.
+func (a *Application) ExplainValues(ctx context.Context, opts RenderOptions) ([]values.Explanation, error) {
+	ch, _, err := a.loadReleaseChart(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	reused, err := a.previousValues(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	prov := values.NewProvenance()
+	layered, err := mergeValues(ch, opts, reused, prov)
+	if err != nil {
+		return nil, err
+	}
+	return prov.Explain(layered.Values), nil
+}
+
+// reusedValues are the user-supplied values of the revision an upgrade builds on.
+type reusedValues struct {
+	Revision int
+	Layers   values.Layers
+}
+
+// previousValues returns the user-supplied values of the release's current revision when opts
+// reuses them: always with ReuseValues, never with ResetValues, and otherwise only when no new
+// values are given (Helm's upgrade behaviour). A release that does not exist yet reuses nothing.
+func (a *Application) previousValues(ctx context.Context, opts RenderOptions) (*reusedValues, error) {
+	if opts.ReuseValues && opts.ResetValues {
+		return nil, errors.New("--reuse-values and --reset-values cannot be used together")
+	}
+	if opts.ResetValues {
+		return nil, nil
+	}
+	if !opts.ReuseValues && (len(opts.ValueFiles) > 0 || !opts.SetValues.IsZero()) {
+		return nil, nil
+	}
+
+	_, runtimeDir, err := a.resolveRuntimeLocation(opts.ReleaseName, opts.RuntimeBaseDir, opts.RuntimePath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := a.Runtime.ReleaseStore.Load(ctx, runtimeDir)
+	if err != nil || current == nil || current.Revision == 0 {
+		return nil, err
+	}
+	rev, err := a.Runtime.History.Get(ctx, runtimeDir, current.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("load values of revision %d: %w", current.Revision, err)
+	}
+	if len(rev.UserValues) == 0 {
+		return nil, nil
+	}
+	return &reusedValues{Revision: current.Revision, Layers: rev.UserValues}, nil
+}
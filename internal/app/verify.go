@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/config"
+	"composepack/internal/provenance"
+)
+
+// VerifyOptions refuse to load charts that fail integrity checks.
+type VerifyOptions struct {
+	// Enabled requires a provenance file signed by a key in Keyring.
+	Enabled bool
+	// Keyring is a PEM public key file or directory (implies Enabled); defaults to DefaultKeyring.
+	Keyring string
+	// Checksum is an expected `sha256:<hex>` digest of the chart archive.
+	Checksum string
+}
+
+// DefaultKeyring returns the keyring used when --verify is given without --keyring.
+func DefaultKeyring() (string, error) {
+	dir, err := config.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "keyring"), nil
+}
+
+// loadVerified loads source through the chart loader, verifying the archive first when
+// opts ask for it.
+func (a *Application) loadVerified(ctx context.Context, source string, opts VerifyOptions) (*chart.Chart, error) {
+	opts.Enabled = opts.Enabled || opts.Keyring != ""
+	if !opts.Enabled && opts.Checksum == "" {
+		return a.Runtime.ChartLoader.Load(ctx, source)
+	}
+	loader, ok := a.Runtime.ChartLoader.(chart.VerifyingLoader)
+	if !ok {
+		return nil, errors.New("the configured chart loader cannot verify charts")
+	}
+
+	verifier := &provenance.Verifier{Checksum: opts.Checksum}
+	if opts.Enabled {
+		path := opts.Keyring
+		if path == "" {
+			var err error
+			if path, err = DefaultKeyring(); err != nil {
+				return nil, err
+			}
+		}
+		keyring, err := provenance.LoadKeyring(path)
+		if err != nil {
+			return nil, err
+		}
+		verifier.Keyring = keyring
+	}
+
+	ch, err := loader.LoadVerified(ctx, source, verifier)
+	if err != nil {
+		return nil, err
+	}
+	if result := verifier.Result; result != nil && result.Statement != nil {
+		fmt.Fprintf(a.Runtime.Stderr, "Verified %s %s (digest %s, signed by key %s)\n", result.Statement.Name, result.Statement.Version, result.Statement.Digest, result.KeyID)
+	}
+	return ch, nil
+}
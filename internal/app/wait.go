@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/dockercompose"
+	"composepack/internal/core/release"
+)
+
+// DefaultWaitTimeout bounds --wait when no --timeout is given.
+const DefaultWaitTimeout = 5 * time.Minute
+
+const (
+	waitPollInterval = 2 * time.Second
+	waitLogTail      = 20
+)
+
+// WaitOptions control waiting for services to become ready after docker compose up.
+type WaitOptions struct {
+	Enabled bool
+	Timeout time.Duration
+}
+
+// waitForRelease polls `docker compose ps` until every service is running (and healthy when it
+// has a healthcheck) or has exited 0, recording the outcome on meta.Readiness.
+func (a *Application) waitForRelease(ctx context.Context, runtimeDir string, meta *release.Metadata, opts WaitOptions) error {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+
+	snap, err := a.Runtime.RuntimeWriter.Read(ctx, runtimeDir)
+	if err != nil {
+		return fmt.Errorf("read runtime compose file: %w", err)
+	}
+	services, err := expectedServices(snap.ComposeYAML)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	readiness := &release.Readiness{Timeout: timeout.String()}
+	meta.Readiness = readiness
+	var lastSummary string
+	for {
+		containers, err := a.Runtime.DockerRunner.PS(waitCtx, runtimeDir)
+		if err != nil && waitCtx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			states := serviceStates(services, containers)
+			readiness.Services = states
+			readiness.Elapsed = time.Since(start).Round(time.Second).String()
+
+			pending := pendingServices(states)
+			if len(pending) == 0 {
+				readiness.Ready = true
+				fmt.Fprintf(a.Runtime.Stderr, "All %d services ready after %s\n", len(services), readiness.Elapsed)
+				return nil
+			}
+			summary := fmt.Sprintf("Waiting for services: %d/%d ready (%s)", len(services)-len(pending), len(services), describeStates(states, pending))
+			if summary != lastSummary {
+				fmt.Fprintln(a.Runtime.Stderr, summary)
+				lastSummary = summary
+			}
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			readiness.Elapsed = time.Since(start).Round(time.Second).String()
+			pending := pendingServices(readiness.Services)
+			if readiness.Services == nil {
+				pending = services
+			}
+			readiness.Message = fmt.Sprintf("timed out after %s waiting for %s", timeout, describeStates(readiness.Services, pending))
+			a.printServiceLogs(context.WithoutCancel(ctx), runtimeDir, pending)
+			return errors.New(readiness.Message)
+		case <-time.After(waitPollInterval):
+		}
+	}
+}
+
+// printServiceLogs shows the last log lines of services that never became ready.
+func (a *Application) printServiceLogs(ctx context.Context, runtimeDir string, services []string) {
+	for _, svc := range services {
+		logs, err := a.Runtime.DockerRunner.Logs(ctx, runtimeDir, []string{svc}, waitLogTail)
+		if err != nil {
+			fmt.Fprintf(a.Runtime.Stderr, "could not read logs for %s: %v\n", svc, err)
+			continue
+		}
+		fmt.Fprintf(a.Runtime.Stderr, "--- %s (last %d log lines) ---\n%s", svc, waitLogTail, logs)
+		if len(logs) > 0 && !strings.HasSuffix(string(logs), "\n") {
+			fmt.Fprintln(a.Runtime.Stderr)
+		}
+	}
+}
+
+// expectedServices lists the services `docker compose up` starts by default (no profiles).
+func expectedServices(composeYAML []byte) ([]string, error) {
+	var doc struct {
+		Services map[string]struct {
+			Profiles []string `json:"profiles"`
+		} `json:"services"`
+	}
+	if err := yaml.Unmarshal(composeYAML, &doc); err != nil {
+		return nil, fmt.Errorf("parse runtime compose file: %w", err)
+	}
+	var out []string
+	for name, svc := range doc.Services {
+		if len(svc.Profiles) == 0 {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// serviceStates maps every expected service to the least ready state among its containers.
+func serviceStates(services []string, containers []dockercompose.ContainerStatus) map[string]string {
+	byService := map[string][]dockercompose.ContainerStatus{}
+	for _, c := range containers {
+		byService[c.Service] = append(byService[c.Service], c)
+	}
+
+	states := make(map[string]string, len(services))
+	for _, svc := range services {
+		state := "not created"
+		for i, c := range byService[svc] {
+			current := containerState(c)
+			if i == 0 || !isReadyState(current) {
+				state = current
+			}
+			if !isReadyState(current) {
+				break
+			}
+		}
+		states[svc] = state
+	}
+	return states
+}
+
+func containerState(c dockercompose.ContainerStatus) string {
+	switch strings.ToLower(c.State) {
+	case "running":
+		switch strings.ToLower(c.Health) {
+		case "", "healthy":
+			return "running"
+		default:
+			return "health: " + strings.ToLower(c.Health)
+		}
+	case "exited":
+		if c.ExitCode == 0 {
+			return "completed"
+		}
+		return fmt.Sprintf("exited (%d)", c.ExitCode)
+	default:
+		return strings.ToLower(c.State)
+	}
+}
+
+func isReadyState(state string) bool {
+	return state == "running" || state == "completed"
+}
+
+func pendingServices(states map[string]string) []string {
+	var out []string
+	for svc, state := range states {
+		if !isReadyState(state) {
+			out = append(out, svc)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func describeStates(states map[string]string, services []string) string {
+	parts := make([]string, 0, len(services))
+	for _, svc := range services {
+		state := states[svc]
+		if state == "" {
+			state = "unknown"
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", svc, state))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/infra/cache"
+)
+
+// NewCacheCommand groups commands that inspect and clear the local chart cache.
+func NewCacheCommand(application *app.Application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect or clear the local cache of downloaded charts",
+	}
+
+	cmd.AddCommand(
+		newCacheListCommand(application),
+		newCacheCleanCommand(application),
+	)
+
+	return cmd
+}
+
+func newCacheListCommand(application *app.Application) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List cached charts and where they were downloaded from",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := application.Runtime.ChartCache.List()
+			if err != nil {
+				return err
+			}
+			if entries == nil {
+				entries = []cache.Entry{}
+			}
+			return printStructured(cmd.OutOrStdout(), output, entries, func(w io.Writer) error {
+				if len(entries) == 0 {
+					fmt.Fprintln(w, "Chart cache is empty")
+					return nil
+				}
+				tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(tw, "SOURCE\tDIGEST\tSIZE\tFETCHED")
+				for _, e := range entries {
+					fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", e.Source, shortDigest(e.Digest), e.Size, e.FetchedAt.Local().Format(time.RFC3339))
+				}
+				return tw.Flush()
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json or yaml")
+
+	return cmd
+}
+
+func newCacheCleanCommand(application *app.Application) *cobra.Command {
+	return &cobra.Command{
+		Use:   "clean",
+		Short: "Remove every cached chart",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			count, size, err := application.Runtime.ChartCache.Clean()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %d cached charts (%d bytes)\n", count, size)
+			return nil
+		},
+	}
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > len("sha256:")+12 {
+		return digest[:len("sha256:")+12]
+	}
+	return digest
+}
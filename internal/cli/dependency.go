@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/dependency"
+)
+
+// NewDependencyCommand groups subcommands that manage a chart's dependencies.
+func NewDependencyCommand(application *app.Application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "dependency",
+		Aliases: []string{"dep"},
+		Short:   "Manage the dependencies declared in a chart's Chart.yaml",
+	}
+
+	cmd.AddCommand(
+		newDependencyUpdateCommand(application),
+		newDependencyBuildCommand(application),
+		newDependencyListCommand(application),
+	)
+
+	return cmd
+}
+
+func newDependencyUpdateCommand(application *app.Application) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update <chart-dir>",
+		Short: "Resolve dependencies, vendor them into charts/ and rewrite Chart.lock",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager := dependency.NewManager(application.Runtime.ChartLoader)
+			lock, err := manager.Update(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			printLockSummary(cmd.OutOrStdout(), lock)
+			return nil
+		},
+	}
+}
+
+func newDependencyBuildCommand(application *app.Application) *cobra.Command {
+	return &cobra.Command{
+		Use:   "build <chart-dir>",
+		Short: "Rebuild charts/ from the versions pinned in Chart.lock",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager := dependency.NewManager(application.Runtime.ChartLoader)
+			lock, err := manager.Build(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			printLockSummary(cmd.OutOrStdout(), lock)
+			return nil
+		},
+	}
+}
+
+func newDependencyListCommand(application *app.Application) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "list <chart-dir>",
+		Short: "List declared dependencies and whether they are available",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager := dependency.NewManager(application.Runtime.ChartLoader)
+			deps, err := manager.List(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if deps == nil {
+				deps = []dependency.Status{}
+			}
+			return printStructured(cmd.OutOrStdout(), output, deps, func(w io.Writer) error {
+				return printDependencyTable(w, deps)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json or yaml")
+
+	return cmd
+}
+
+func printLockSummary(out io.Writer, lock *dependency.Lock) {
+	for _, dep := range lock.Dependencies {
+		fmt.Fprintf(out, "Saved %s %s\n", dep.Name, dep.Version)
+	}
+	fmt.Fprintf(out, "%d dependencies vendored into charts/\n", len(lock.Dependencies))
+}
+
+func printDependencyTable(out io.Writer, deps []dependency.Status) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tALIAS\tVERSION\tLOCKED\tREPOSITORY\tSTATUS")
+	for _, d := range deps {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", d.Name, d.Alias, d.Version, d.Locked, d.Repository, d.Status)
+	}
+	return w.Flush()
+}
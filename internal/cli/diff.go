@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/core/diff"
+	"composepack/internal/core/values"
+	"composepack/internal/infra/process"
+)
+
+// diffExitCode is returned when the rendered release differs from the runtime directory.
+const diffExitCode = 2
+
+// NewDiffCommand previews what an upgrade would change without touching the runtime.
+func NewDiffCommand(application *app.Application) *cobra.Command {
+	var (
+		valueFiles   []string
+		setValues    values.Overrides
+		reuseValues  bool
+		resetValues  bool
+		chartSrc     string
+		storedChart  bool
+		chartVersion string
+		runtimeDir   string
+		context      int
+		noColor      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <release>",
+		Short: "Show what rendering the chart would change in a release runtime",
+		Long:  "Render the release in memory and print a unified diff against its runtime directory. Exits with status 2 when there are differences.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			opts := app.DiffOptions{
+				RenderOptions: app.RenderOptions{
+					ReleaseName:    args[0],
+					ChartSource:    chartSrc,
+					UseStoredChart: storedChart,
+					ChartVersion:   chartVersion,
+					ValueFiles:     append([]string{}, valueFiles...),
+					SetValues:      setValues,
+					ReuseValues:    reuseValues,
+					ResetValues:    resetValues,
+					RuntimeBaseDir: releaseDir,
+					RuntimePath:    runtimeDir,
+				},
+				Context: context,
+			}
+
+			result, err := application.DiffRelease(cmd.Context(), opts)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !result.Changed() {
+				fmt.Fprintf(out, "Release %s is up to date\n", args[0])
+				return nil
+			}
+
+			color := !noColor && process.IsTerminal(out)
+			printDiff(out, result, color)
+			return &exitError{code: diffExitCode, msg: fmt.Sprintf("release %s has pending changes", args[0])}
+		},
+	}
+
+	cmd.Flags().StringVar(&chartSrc, "chart", "", "chart directory, archive, URL or repo/chart reference (defaults to the chart the release was installed from)")
+	cmd.Flags().BoolVar(&storedChart, "use-stored-chart", false, "render from the copy of the chart stored with the release's current revision")
+	cmd.MarkFlagsMutuallyExclusive("chart", "use-stored-chart")
+	cmd.Flags().StringVar(&chartVersion, "version", "", "chart version or semver range (e.g. ^1.2) to use from a repository")
+	cmd.Flags().StringArrayVarP(&valueFiles, "values", "f", nil, "values files to include")
+	cmd.Flags().StringArrayVar(&setValues.Values, "set", nil, "set values on the command line (a.b=1,list[0]=x,tags={a,b})")
+	cmd.Flags().StringArrayVar(&setValues.Strings, "set-string", nil, "set STRING values on the command line, without type inference")
+	cmd.Flags().StringArrayVar(&setValues.JSON, "set-json", nil, "set JSON values on the command line (key=<json>)")
+	cmd.Flags().StringArrayVar(&setValues.Files, "set-file", nil, "set values from files (key=path)")
+	cmd.Flags().BoolVar(&reuseValues, "reuse-values", false, "merge -f/--set values over the values supplied to the current revision")
+	cmd.Flags().BoolVar(&resetValues, "reset-values", false, "ignore the values supplied to the current revision and start from the chart defaults")
+	cmd.MarkFlagsMutuallyExclusive("reuse-values", "reset-values")
+	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to existing release directory (overrides --release-dir)")
+	cmd.Flags().IntVar(&context, "context", diff.DefaultContext, "number of unchanged lines to show around changes")
+	cmd.Flags().BoolVar(&noColor, "no-color", false, "disable colorized output")
+
+	return cmd
+}
+
+func printDiff(out io.Writer, result *app.DiffResult, color bool) {
+	for _, file := range result.Files {
+		text := file.Unified
+		if color {
+			text = diff.Colorize(text)
+		}
+		fmt.Fprint(out, text)
+	}
+
+	if len(result.Services) == 0 {
+		return
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Services:")
+	for _, change := range result.Services {
+		fmt.Fprintf(out, "  %s\n", change.String())
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+)
+
+// NewGetCommand groups commands that show details stored with a release.
+func NewGetCommand(application *app.Application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Show details stored with a release",
+	}
+
+	cmd.AddCommand(
+		newGetNotesCommand(application),
+		newGetValuesCommand(application),
+	)
+
+	return cmd
+}
+
+func newGetNotesCommand(application *app.Application) *cobra.Command {
+	var (
+		runtimeDir string
+		revision   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "notes <release>",
+		Short: "Show the rendered NOTES.txt of a release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			notes, err := application.ReleaseNotes(cmd.Context(), app.NotesOptions{
+				ReleaseName:    args[0],
+				RuntimeBaseDir: releaseDir,
+				RuntimePath:    runtimeDir,
+				Revision:       revision,
+			})
+			if err != nil {
+				return err
+			}
+			if len(notes) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "release %s has no notes\n", args[0])
+				return nil
+			}
+			_, err = cmd.OutOrStdout().Write(notes)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to release directory (overrides --release-dir)")
+	cmd.Flags().IntVar(&revision, "revision", 0, "show the notes of this revision instead of the current one")
+
+	return cmd
+}
+
+func newGetValuesCommand(application *app.Application) *cobra.Command {
+	var (
+		runtimeDir string
+		revision   int
+		explain    bool
+		all        bool
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "values <release>",
+		Short: "Show the values of a release",
+		Long: "Print the values supplied to a release revision (--all adds the chart defaults). With --explain, " +
+			"list every merged key's value together with the chart default, values file line or --set flag that set it.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			vals, explanations, err := application.ReleaseValues(cmd.Context(), app.ValuesOptions{
+				ReleaseName:    args[0],
+				RuntimeBaseDir: releaseDir,
+				RuntimePath:    runtimeDir,
+				Revision:       revision,
+				All:            all,
+			})
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if !explain {
+				if output == "" || output == "table" {
+					output = "yaml"
+				}
+				return printStructured(out, output, vals, nil)
+			}
+			if explanations == nil {
+				return fmt.Errorf("release %s: the revision was recorded without values provenance; run up to record a new one", args[0])
+			}
+			return printStructured(out, output, explanations, func(w io.Writer) error {
+				return printExplanations(w, explanations)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to release directory (overrides --release-dir)")
+	cmd.Flags().IntVar(&revision, "revision", 0, "show the values of this revision instead of the current one")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "show the merged values including chart defaults")
+	cmd.Flags().BoolVar(&explain, "explain", false, "show where every value came from")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "output format: yaml or json (table, json or yaml with --explain)")
+
+	return cmd
+}
@@ -1,27 +1,16 @@
 package cli
 
-import (
-	"fmt"
-	"strings"
-)
-
-func parseSetFlags(values []string) (map[string]string, error) {
-	if len(values) == 0 {
-		return map[string]string{}, nil
-	}
-
-	out := make(map[string]string, len(values))
-	for _, raw := range values {
-		if raw == "" {
-			continue
-		}
+// exitError asks main to exit with a specific status code.
+type exitError struct {
+	code int
+	msg  string
+}
 
-		parts := strings.SplitN(raw, "=", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("invalid --set value %q; must be key=value", raw)
-		}
-		out[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
-	}
+func (e *exitError) Error() string {
+	return e.msg
+}
 
-	return out, nil
+// ExitCode implements the contract main uses to pick the process exit status.
+func (e *exitError) ExitCode() int {
+	return e.code
 }
@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+)
+
+// NewHistoryCommand lists the stored revisions of a release.
+func NewHistoryCommand(application *app.Application) *cobra.Command {
+	var runtimeDir string
+
+	cmd := &cobra.Command{
+		Use:   "history <release>",
+		Short: "Show the revision history of a release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			revisions, err := application.ReleaseHistory(cmd.Context(), app.HistoryOptions{
+				ReleaseName:    args[0],
+				RuntimeBaseDir: releaseDir,
+				RuntimePath:    runtimeDir,
+			})
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "REVISION\tUPDATED\tSTATUS\tCHART\tVERSION\tDESCRIPTION")
+			for _, rev := range revisions {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+					rev.Revision,
+					rev.CreatedAt.Local().Format(time.RFC3339),
+					rev.Status,
+					rev.ChartMetadata.Name,
+					rev.ChartMetadata.Version,
+					rev.Description,
+				)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to release directory (overrides --release-dir)")
+
+	return cmd
+}
@@ -6,15 +6,21 @@ import (
 	"github.com/spf13/cobra"
 
 	"composepack/internal/app"
+	"composepack/internal/core/values"
 )
 
 // NewInstallCommand returns the `composepack install` Cobra command skeleton.
 func NewInstallCommand(application *app.Application) *cobra.Command {
 	var (
-		releaseName string
-		valueFiles  []string
-		setValues   []string
-		autoStart   bool
+		releaseName  string
+		chartVersion string
+		valueFiles   []string
+		setValues    values.Overrides
+		autoStart    bool
+		maxHistory   int
+		dryRun       app.DryRunOptions
+		wait         app.WaitOptions
+		verify       app.VerifyOptions
 	)
 
 	cmd := &cobra.Command{
@@ -32,11 +38,6 @@ func NewInstallCommand(application *app.Application) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			chartSource := args[0]
 
-			overrides, err := parseSetFlags(setValues)
-			if err != nil {
-				return err
-			}
-
 			releaseDir, err := cmd.Flags().GetString("release-dir")
 			if err != nil {
 				return err
@@ -46,11 +47,16 @@ func NewInstallCommand(application *app.Application) *cobra.Command {
 				RenderOptions: app.RenderOptions{
 					ReleaseName:    releaseName,
 					ChartSource:    chartSource,
+					ChartVersion:   chartVersion,
+					Verify:         verify,
 					ValueFiles:     append([]string{}, valueFiles...),
-					SetValues:      overrides,
+					SetValues:      setValues,
 					RuntimeBaseDir: releaseDir,
+					MaxHistory:     maxHistory,
 				},
 				AutoStart: autoStart,
+				DryRun:    dryRun,
+				Wait:      wait,
 			}
 
 			return application.InstallRelease(cmd.Context(), opts)
@@ -58,9 +64,22 @@ func NewInstallCommand(application *app.Application) *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&releaseName, "name", "", "release name to use for the installation")
+	cmd.Flags().StringVar(&chartVersion, "version", "", "chart version or semver range (e.g. ^1.2) to use from a repository")
+	cmd.Flags().BoolVar(&verify.Enabled, "verify", false, "refuse to load the chart unless its provenance file is signed by a key in the keyring")
+	cmd.Flags().StringVar(&verify.Keyring, "keyring", "", "public key file or directory used by --verify (default $XDG_CONFIG_HOME/composepack/keyring)")
+	cmd.Flags().StringVar(&verify.Checksum, "checksum", "", "refuse to load the chart unless its archive has this sha256:<hex> digest")
 	cmd.Flags().StringArrayVarP(&valueFiles, "values", "f", nil, "values files to include (can specify multiple)")
-	cmd.Flags().StringArrayVar(&setValues, "set", nil, "direct value overrides (key=value)")
+	cmd.Flags().StringArrayVar(&setValues.Values, "set", nil, "set values on the command line (a.b=1,list[0]=x,tags={a,b})")
+	cmd.Flags().StringArrayVar(&setValues.Strings, "set-string", nil, "set STRING values on the command line, without type inference")
+	cmd.Flags().StringArrayVar(&setValues.JSON, "set-json", nil, "set JSON values on the command line (key=<json>)")
+	cmd.Flags().StringArrayVar(&setValues.Files, "set-file", nil, "set values from files (key=path)")
 	cmd.Flags().BoolVar(&autoStart, "auto-start", false, "run docker compose up after installation")
+	cmd.Flags().IntVar(&maxHistory, "history-max", application.Runtime.Config.MaxHistory, "maximum number of revisions kept per release (0 for no limit)")
+	cmd.Flags().BoolVar(&dryRun.Enabled, "dry-run", false, "render and print the compose YAML without writing the runtime directory")
+	cmd.Flags().BoolVar(&dryRun.ShowFiles, "show-files", false, "with --dry-run, list rendered files/ entries")
+	cmd.Flags().BoolVar(&dryRun.ShowFileContents, "show-file-contents", false, "with --dry-run, print the contents of rendered files/ entries")
+	cmd.Flags().BoolVar(&wait.Enabled, "wait", false, "wait until every service is running and healthy (or exited 0)")
+	cmd.Flags().DurationVar(&wait.Timeout, "timeout", app.DefaultWaitTimeout, "how long --wait waits before failing")
 
 	return cmd
 }
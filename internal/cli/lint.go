@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/core/lint"
+	"composepack/internal/core/values"
+)
+
+// lintExitCode is returned when a chart fails lint.
+const lintExitCode = 1
+
+// NewLintCommand checks a chart for mistakes before it is installed.
+func NewLintCommand(application *app.Application) *cobra.Command {
+	var (
+		valueFiles   []string
+		setValues    values.Overrides
+		chartVersion string
+		strict       bool
+		output       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint <chart>",
+		Short: "Check a chart for problems",
+		Long: "Load the chart, validate Chart.yaml and values against values.schema.json, render it with its " +
+			"default values (and again with any -f/--set values), validate the merged compose file against the " +
+			"Compose schema and flag common mistakes. Exits with status 1 on errors, or on warnings with --strict.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := application.LintChart(cmd.Context(), app.LintOptions{
+				ChartSource:  args[0],
+				ChartVersion: chartVersion,
+				ValueFiles:   append([]string{}, valueFiles...),
+				SetValues:    setValues,
+			})
+			if err != nil {
+				return err
+			}
+			if report.Issues == nil {
+				report.Issues = []lint.Issue{}
+			}
+
+			if err := printStructured(cmd.OutOrStdout(), output, report, func(w io.Writer) error {
+				return printLintReport(w, report)
+			}); err != nil {
+				return err
+			}
+			if report.Failed(strict) {
+				return &exitError{code: lintExitCode, msg: fmt.Sprintf("chart %s failed lint", report.Chart)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&valueFiles, "values", "f", nil, "values files to also render the chart with")
+	cmd.Flags().StringArrayVar(&setValues.Values, "set", nil, "set values on the command line (a.b=1,list[0]=x,tags={a,b})")
+	cmd.Flags().StringArrayVar(&setValues.Strings, "set-string", nil, "set STRING values on the command line, without type inference")
+	cmd.Flags().StringArrayVar(&setValues.JSON, "set-json", nil, "set JSON values on the command line (key=<json>)")
+	cmd.Flags().StringArrayVar(&setValues.Files, "set-file", nil, "set values from files (key=path)")
+	cmd.Flags().StringVar(&chartVersion, "version", "", "chart version or semver range (e.g. ^1.2) to use from a repository")
+	cmd.Flags().BoolVar(&strict, "strict", false, "fail on warnings as well as errors")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json or yaml")
+
+	return cmd
+}
+
+func printLintReport(out io.Writer, report *lint.Report) error {
+	name := report.Chart
+	if report.Version != "" {
+		name += " " + report.Version
+	}
+	fmt.Fprintf(out, "==> Linting %s\n", name)
+	for _, issue := range report.Issues {
+		fmt.Fprintln(out, issue.String())
+	}
+	_, err := fmt.Fprintf(out, "\n%d error(s), %d warning(s)\n", report.Count(lint.SeverityError), report.Count(lint.SeverityWarning))
+	return err
+}
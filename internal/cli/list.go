@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/app"
+)
+
+// NewListCommand enumerates releases under the releases base directory.
+func NewListCommand(application *app.Application) *cobra.Command {
+	var (
+		output   string
+		chart    string
+		noStatus bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List releases under the releases base directory",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			releases, err := application.ListReleases(cmd.Context(), app.ListOptions{
+				RuntimeBaseDir: releaseDir,
+				ChartName:      chart,
+				SkipLiveStatus: noStatus,
+			})
+			if err != nil {
+				return err
+			}
+			if releases == nil {
+				releases = []app.ReleaseSummary{}
+			}
+
+			return printStructured(cmd.OutOrStdout(), output, releases, func(w io.Writer) error {
+				return printReleaseTable(w, releases)
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json or yaml")
+	cmd.Flags().StringVar(&chart, "chart", "", "only list releases installed from this chart name")
+	cmd.Flags().BoolVar(&noStatus, "no-status", false, "skip querying docker compose for live container status")
+
+	return cmd
+}
+
+func printReleaseTable(out io.Writer, releases []app.ReleaseSummary) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCHART\tVERSION\tCREATED\tREVISION\tSTATUS\tLIVE")
+	for _, r := range releases {
+		live := r.Live
+		if r.Containers != "" {
+			live = fmt.Sprintf("%s (%s)", r.Live, r.Containers)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			r.Name,
+			r.Chart,
+			r.ChartVersion,
+			r.Created.Local().Format(time.RFC3339),
+			r.Revision,
+			r.Status,
+			live,
+		)
+	}
+	return w.Flush()
+}
+
+// printStructured writes data as JSON or YAML, or delegates to table for the default format.
+func printStructured(out io.Writer, format string, data any, table func(io.Writer) error) error {
+	switch format {
+	case "", "table":
+		return table(out)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(data)
+	case "yaml":
+		raw, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(raw)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q (use table, json or yaml)", format)
+	}
+}
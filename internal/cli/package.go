@@ -7,6 +7,7 @@ import (
 
 	"composepack/internal/app"
 	"composepack/internal/packager"
+	"composepack/internal/provenance"
 )
 
 // NewPackageCommand creates chart archives (.cpack.tgz).
@@ -15,6 +16,8 @@ func NewPackageCommand(application *app.Application) *cobra.Command {
 		destination string
 		outputName  string
 		force       bool
+		sign        bool
+		key         string
 	)
 
 	cmd := &cobra.Command{
@@ -22,17 +25,27 @@ func NewPackageCommand(application *app.Application) *cobra.Command {
 		Short: "Package a chart directory into a .cpack.tgz archive",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if sign && key == "" {
+				return fmt.Errorf("--sign requires --key")
+			}
+			if !sign {
+				key = ""
+			}
 			opts := packager.Options{
 				ChartPath:   args[0],
 				Destination: destination,
 				OutputName:  outputName,
 				Force:       force,
+				SigningKey:  key,
 			}
 			path, err := packager.PackageChart(cmd.Context(), application.Runtime.ChartLoader, opts)
 			if err != nil {
 				return err
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Created %s\n", path)
+			if sign {
+				fmt.Fprintf(cmd.OutOrStdout(), "Signed %s%s\n", path, provenance.Extension)
+			}
 			return nil
 		},
 	}
@@ -40,6 +53,8 @@ func NewPackageCommand(application *app.Application) *cobra.Command {
 	cmd.Flags().StringVarP(&destination, "destination", "d", ".", "output directory for the packaged chart")
 	cmd.Flags().StringVarP(&outputName, "output", "o", "", "output filename (defaults to <name>-<version>.cpack.tgz)")
 	cmd.Flags().BoolVar(&force, "force", false, "overwrite existing output file")
+	cmd.Flags().BoolVar(&sign, "sign", false, "write a signed provenance file (<archive>.prov) next to the archive")
+	cmd.Flags().StringVar(&key, "key", "", "ed25519 private key (PKCS#8 PEM) used by --sign")
 
 	return cmd
 }
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/oci"
+	"composepack/internal/packager"
+)
+
+// NewPushCommand uploads a packaged chart to an OCI registry.
+func NewPushCommand(application *app.Application) *cobra.Command {
+	var plainHTTP bool
+
+	cmd := &cobra.Command{
+		Use:   "push <archive> oci://<registry>/<repository>",
+		Short: "Push a .cpack.tgz chart archive to an OCI registry",
+		Long: "Push a packaged chart to an OCI registry as <repository>/<name>:<version>.\n" +
+			"Credentials are read from Docker's config.json (see `composepack registry login`).",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := oci.NewClient()
+			client.PlainHTTP = plainHTTP
+			result, err := packager.Push(cmd.Context(), application.Runtime.ChartLoader, client, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pushed %s\nDigest: %s\n", result.Ref, result.Digest)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&plainHTTP, "plain-http", false, "use HTTP instead of HTTPS for the registry")
+
+	return cmd
+}
+
+// NewPullCommand downloads a chart from an OCI registry.
+func NewPullCommand() *cobra.Command {
+	var (
+		destination string
+		plainHTTP   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull oci://<registry>/<repository>/<chart>:<version>",
+		Short: "Download a chart archive from an OCI registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := oci.NewClient()
+			client.PlainHTTP = plainHTTP
+			client.MaxBlobSize = chart.DefaultExtractLimits.MaxTotalSize
+			path, err := packager.Pull(cmd.Context(), client, args[0], destination)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pulled %s\n", path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&destination, "destination", "d", ".", "directory to write the chart archive to")
+	cmd.Flags().BoolVar(&plainHTTP, "plain-http", false, "use HTTP instead of HTTPS for the registry")
+
+	return cmd
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/infra/oci"
+)
+
+// NewRegistryCommand groups commands that manage OCI registry credentials.
+func NewRegistryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Log in to and out of OCI registries",
+	}
+
+	cmd.AddCommand(
+		newRegistryLoginCommand(),
+		newRegistryLogoutCommand(),
+	)
+
+	return cmd
+}
+
+func newRegistryLoginCommand() *cobra.Command {
+	var (
+		username      string
+		password      string
+		passwordStdin bool
+		plainHTTP     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "login <registry>",
+		Short: "Verify credentials and store them in Docker's config.json",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if passwordStdin {
+				if password != "" {
+					return errors.New("--password and --password-stdin are mutually exclusive")
+				}
+				secret, err := readPassword(cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				password = secret
+			}
+			if username == "" || password == "" {
+				return errors.New("--username and --password (or --password-stdin) are required")
+			}
+
+			client := oci.NewClient()
+			client.PlainHTTP = plainHTTP
+			if err := client.Login(cmd.Context(), args[0], oci.Credential{Username: username, Password: password}); err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Login Succeeded")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&username, "username", "u", "", "registry username")
+	cmd.Flags().StringVarP(&password, "password", "p", "", "registry password or token")
+	cmd.Flags().BoolVar(&passwordStdin, "password-stdin", false, "read the password from stdin")
+	cmd.Flags().BoolVar(&plainHTTP, "plain-http", false, "use HTTP instead of HTTPS for the registry")
+
+	return cmd
+}
+
+func newRegistryLogoutCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <registry>",
+		Short: "Remove stored credentials for a registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := oci.NewClient().Logout(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed login credentials for %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func readPassword(in io.Reader) (string, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/repo"
+)
+
+// NewRepoCommand groups commands that manage chart repositories.
+func NewRepoCommand(application *app.Application) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo",
+		Short: "Add, list, remove, update and index chart repositories",
+	}
+
+	cmd.AddCommand(
+		newRepoAddCommand(),
+		newRepoListCommand(),
+		newRepoRemoveCommand(),
+		newRepoUpdateCommand(),
+		newRepoIndexCommand(application),
+	)
+
+	return cmd
+}
+
+func newRepoAddCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "add <name> <url>",
+		Short: "Add a chart repository",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := repo.NewManager()
+			if err != nil {
+				return err
+			}
+			if err := manager.Add(cmd.Context(), args[0], args[1], force); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%q has been added to your repositories\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force-update", false, "replace the URL of an existing repository")
+
+	return cmd
+}
+
+func newRepoListCommand() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List configured chart repositories",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := repo.NewManager()
+			if err != nil {
+				return err
+			}
+			entries, err := manager.List()
+			if err != nil {
+				return err
+			}
+			if entries == nil {
+				entries = []repo.Entry{}
+			}
+			return printStructured(cmd.OutOrStdout(), output, entries, func(w io.Writer) error {
+				if len(entries) == 0 {
+					fmt.Fprintln(w, "No repositories configured")
+					return nil
+				}
+				tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(tw, "NAME\tURL")
+				for _, entry := range entries {
+					fmt.Fprintf(tw, "%s\t%s\n", entry.Name, entry.URL)
+				}
+				return tw.Flush()
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json or yaml")
+
+	return cmd
+}
+
+func newRepoRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <name>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove chart repositories",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := repo.NewManager()
+			if err != nil {
+				return err
+			}
+			for _, name := range args {
+				if err := manager.Remove(cmd.Context(), name); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%q has been removed from your repositories\n", name)
+			}
+			return nil
+		},
+	}
+}
+
+func newRepoUpdateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [name...]",
+		Short: "Refresh the cached index of every (or the named) repository",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manager, err := repo.NewManager()
+			if err != nil {
+				return err
+			}
+			updated, err := manager.Update(cmd.Context(), args)
+			for _, entry := range updated {
+				fmt.Fprintf(cmd.OutOrStdout(), "Successfully got an update from the %q chart repository\n", entry.Name)
+			}
+			return err
+		},
+	}
+}
+
+func newRepoIndexCommand(application *app.Application) *cobra.Command {
+	var baseURL string
+
+	cmd := &cobra.Command{
+		Use:   "index <dir>",
+		Short: "Generate index.yaml for the packaged charts in a directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			index, err := repo.IndexDirectory(cmd.Context(), application.Runtime.ChartLoader, args[0], baseURL)
+			if err != nil {
+				return err
+			}
+			path := filepath.Join(args[0], repo.IndexFileName)
+			if err := index.WriteFile(cmd.Context(), path); err != nil {
+				return err
+			}
+			count := 0
+			for _, versions := range index.Entries {
+				count += len(versions)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d chart versions into %s\n", count, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&baseURL, "url", "", "absolute URL prefix for chart downloads (defaults to URLs relative to index.yaml)")
+
+	return cmd
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+)
+
+// NewRollbackCommand restores a previous revision of a release and restarts it.
+func NewRollbackCommand(application *app.Application) *cobra.Command {
+	var (
+		runtimeDir string
+		maxHistory int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rollback <release> [revision]",
+		Short: "Restore a previous release revision and run docker compose up -d",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			revision := 0
+			if len(args) == 2 {
+				revision, err = strconv.Atoi(args[1])
+				if err != nil || revision <= 0 {
+					return fmt.Errorf("invalid revision %q; must be a positive integer", args[1])
+				}
+			}
+
+			opts := app.RollbackOptions{
+				ReleaseName:    args[0],
+				RuntimeBaseDir: releaseDir,
+				RuntimePath:    runtimeDir,
+				Revision:       revision,
+				MaxHistory:     maxHistory,
+			}
+
+			return application.RollbackRelease(cmd.Context(), opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to release directory (overrides --release-dir)")
+	cmd.Flags().IntVar(&maxHistory, "history-max", application.Runtime.Config.MaxHistory, "maximum number of revisions kept per release (0 for no limit)")
+
+	return cmd
+}
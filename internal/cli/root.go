@@ -4,6 +4,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"composepack/internal/app"
+	"composepack/internal/core/dockercompose"
 )
 
 // NewRootCommand wires together the CLI commands described in PRD/CLAUDE.
@@ -21,22 +22,58 @@ func NewRootCommand(application *app.Application) *cobra.Command {
 			if releaseDir != "" {
 				application.Runtime.Config.ReleasesBaseDir = releaseDir
 			}
+			mergeEngine, err := cmd.Flags().GetString("merge-engine")
+			if err != nil {
+				return err
+			}
+			engine, err := dockercompose.ParseMergeEngine(mergeEngine)
+			if err != nil {
+				return err
+			}
+			application.Runtime.Config.MergeEngine = string(engine)
+			offline, err := cmd.Flags().GetBool("offline")
+			if err != nil {
+				return err
+			}
+			application.Runtime.Config.Offline = offline
+			application.Runtime.ChartCache.Offline = offline
+			application.Runtime.Stdin = cmd.InOrStdin()
+			application.Runtime.Stdout = cmd.OutOrStdout()
+			application.Runtime.Stderr = cmd.ErrOrStderr()
 			return nil
 		},
 	}
 
 	cmd.PersistentFlags().String("release-dir", application.Runtime.Config.ReleasesBaseDir, "override default releases base directory")
+	cmd.PersistentFlags().String("merge-engine", application.Runtime.Config.MergeEngine, "how compose fragments are merged: auto (docker if installed, else native), docker or native")
+	cmd.PersistentFlags().Bool("offline", application.Runtime.Config.Offline, "use only charts and repository indexes already in the local cache")
 
 	cmd.AddCommand(
 		NewInstallCommand(application),
 		NewTemplateCommand(application),
 		NewUpCommand(application),
+		NewDiffCommand(application),
 		NewDownCommand(application),
+		NewUninstallCommand(application),
 		NewLogsCommand(application),
 		NewPSCommand(application),
+		NewListCommand(application),
+		NewHistoryCommand(application),
+		NewGetCommand(application),
+		NewRollbackCommand(application),
 		NewVersionCommand(),
 		NewInitCommand(),
 		NewPackageCommand(application),
+		NewVerifyCommand(),
+		NewLintCommand(application),
+		NewTestChartCommand(application),
+		NewDependencyCommand(application),
+		NewPushCommand(application),
+		NewPullCommand(),
+		NewRegistryCommand(),
+		NewRepoCommand(application),
+		NewSearchCommand(),
+		NewCacheCommand(application),
 	)
 
 	return cmd
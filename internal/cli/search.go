@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/repo"
+)
+
+// NewSearchCommand searches charts in the configured repositories.
+func NewSearchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Search for charts",
+	}
+
+	cmd.AddCommand(newSearchRepoCommand())
+
+	return cmd
+}
+
+func newSearchRepoCommand() *cobra.Command {
+	var (
+		versions   bool
+		constraint string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "repo [term]",
+		Short: "Search the indexes of added repositories by name and description",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			term := ""
+			if len(args) == 1 {
+				term = args[0]
+			}
+			manager, err := repo.NewManager()
+			if err != nil {
+				return err
+			}
+			results, err := manager.Search(cmd.Context(), term, constraint, versions)
+			if err != nil {
+				return err
+			}
+			if results == nil {
+				results = []repo.SearchResult{}
+			}
+			return printStructured(cmd.OutOrStdout(), output, results, func(w io.Writer) error {
+				if len(results) == 0 {
+					fmt.Fprintln(w, "No results found")
+					return nil
+				}
+				tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(tw, "NAME\tVERSION\tDESCRIPTION")
+				for _, r := range results {
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, r.Version, r.Description)
+				}
+				return tw.Flush()
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&versions, "versions", "l", false, "show every version instead of only the newest")
+	cmd.Flags().StringVar(&constraint, "version", "", "only show versions matching this semver range")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "output format: table, json or yaml")
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/core/charttest"
+)
+
+// testChartExitCode is returned when any chart test fails.
+const testChartExitCode = 1
+
+// NewTestChartCommand runs the YAML test suites a chart ships under tests/.
+func NewTestChartCommand(application *app.Application) *cobra.Command {
+	var (
+		updateSnapshots bool
+		junitPath       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "test-chart <chart-dir>",
+		Short: "Run a chart's template unit tests",
+		Long: "Run every *_test.yaml suite under <chart-dir>/tests. Each test sets values, renders the chart in memory and " +
+			"asserts on the merged compose document and rendered files. Snapshots are kept in tests/__snapshot__/.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			result, err := application.TestChart(cmd.Context(), app.TestChartOptions{
+				ChartDir:        args[0],
+				UpdateSnapshots: updateSnapshots,
+			})
+			if err != nil {
+				return err
+			}
+
+			printTestResult(cmd.OutOrStdout(), result)
+			if junitPath != "" {
+				if err := writeJUnitFile(junitPath, result); err != nil {
+					return err
+				}
+			}
+
+			if _, failed := result.Counts(); failed > 0 {
+				return &exitError{code: testChartExitCode, msg: fmt.Sprintf("%d chart test(s) failed", failed)}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&updateSnapshots, "update-snapshots", "u", false, "rewrite snapshots that no longer match (and drop unused ones from passing suites)")
+	cmd.Flags().StringVar(&junitPath, "junit", "", "also write a JUnit XML report to this file")
+
+	return cmd
+}
+
+func printTestResult(out io.Writer, result *charttest.Result) {
+	suitesFailed := 0
+	for _, suite := range result.Suites {
+		status := "PASS"
+		if suite.Failed() > 0 {
+			status = "FAIL"
+			suitesFailed++
+		}
+		fmt.Fprintf(out, "%s %s (%s)\n", status, suite.Name, suite.Path)
+		for _, test := range suite.Tests {
+			if test.Passed() {
+				fmt.Fprintf(out, "  ok   %s\n", test.Name)
+				continue
+			}
+			fmt.Fprintf(out, "  FAIL %s\n", test.Name)
+			for _, failure := range test.Failures {
+				fmt.Fprintf(out, "       - %s\n", strings.ReplaceAll(strings.TrimRight(failure, "\n"), "\n", "\n         "))
+			}
+		}
+	}
+
+	total, failed := result.Counts()
+	fmt.Fprintf(out, "\nSuites: %d passed, %d failed\n", len(result.Suites)-suitesFailed, suitesFailed)
+	fmt.Fprintf(out, "Tests:  %d passed, %d failed\n", total-failed, failed)
+	if result.SnapshotsWritten > 0 || result.SnapshotsUpdated > 0 {
+		fmt.Fprintf(out, "Snapshots: %d written, %d updated\n", result.SnapshotsWritten, result.SnapshotsUpdated)
+	}
+}
+
+func writeJUnitFile(path string, result *charttest.Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create junit report: %w", err)
+	}
+	if err := charttest.WriteJUnit(file, result); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
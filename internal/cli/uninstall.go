@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+)
+
+// NewUninstallCommand tears a release down and removes its runtime directory.
+func NewUninstallCommand(application *app.Application) *cobra.Command {
+	var (
+		removeVolumes bool
+		removeImages  string
+		keepHistory   bool
+		yes           bool
+		runtimeDir    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "uninstall <release>",
+		Short: "Run docker compose down and delete a release runtime",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseDir, err := cmd.Flags().GetString("release-dir")
+			if err != nil {
+				return err
+			}
+
+			if !yes {
+				action := "delete its runtime directory"
+				if keepHistory {
+					action = "delete its runtime files (keeping history)"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Uninstall release %s and %s? [y/N]: ", args[0], action)
+				answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+				answer = strings.ToLower(strings.TrimSpace(answer))
+				if answer != "y" && answer != "yes" {
+					return fmt.Errorf("uninstall of %s aborted", args[0])
+				}
+			}
+
+			opts := app.UninstallOptions{
+				ReleaseName:    args[0],
+				RuntimeBaseDir: releaseDir,
+				RuntimePath:    runtimeDir,
+				RemoveVolumes:  removeVolumes,
+				RemoveImages:   removeImages,
+				KeepHistory:    keepHistory,
+			}
+
+			if err := application.UninstallRelease(cmd.Context(), opts); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Release %s uninstalled\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&removeVolumes, "volumes", false, "remove named volumes declared by the release")
+	cmd.Flags().StringVar(&removeImages, "rmi", "", "remove images used by services (local or all)")
+	cmd.Flags().BoolVar(&keepHistory, "keep-history", false, "keep release.json and revisions for auditing")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "do not ask for confirmation")
+	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to release directory (overrides --release-dir)")
+
+	return cmd
+}
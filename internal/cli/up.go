@@ -4,16 +4,25 @@ import (
 	"github.com/spf13/cobra"
 
 	"composepack/internal/app"
+	"composepack/internal/core/values"
 )
 
 // NewUpCommand wires the `composepack up` command skeleton.
 func NewUpCommand(application *app.Application) *cobra.Command {
 	var (
-		valueFiles []string
-		setValues  []string
-		chartSrc   string
-		detach     bool
-		runtimeDir string
+		valueFiles   []string
+		setValues    values.Overrides
+		reuseValues  bool
+		resetValues  bool
+		chartSrc     string
+		storedChart  bool
+		chartVersion string
+		detach       bool
+		runtimeDir   string
+		maxHistory   int
+		dryRun       app.DryRunOptions
+		wait         app.WaitOptions
+		verify       app.VerifyOptions
 	)
 
 	cmd := &cobra.Command{
@@ -21,11 +30,6 @@ func NewUpCommand(application *app.Application) *cobra.Command {
 		Short: "Render and run docker compose up for a release",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			overrides, err := parseSetFlags(setValues)
-			if err != nil {
-				return err
-			}
-
 			releaseDir, err := cmd.Flags().GetString("release-dir")
 			if err != nil {
 				return err
@@ -35,23 +39,49 @@ func NewUpCommand(application *app.Application) *cobra.Command {
 				RenderOptions: app.RenderOptions{
 					ReleaseName:    args[0],
 					ChartSource:    chartSrc,
+					UseStoredChart: storedChart,
+					ChartVersion:   chartVersion,
+					Verify:         verify,
 					ValueFiles:     append([]string{}, valueFiles...),
-					SetValues:      overrides,
+					SetValues:      setValues,
+					ReuseValues:    reuseValues,
+					ResetValues:    resetValues,
 					RuntimeBaseDir: releaseDir,
 					RuntimePath:    runtimeDir,
+					MaxHistory:     maxHistory,
 				},
 				Detach: detach,
+				DryRun: dryRun,
+				Wait:   wait,
 			}
 
 			return application.UpRelease(cmd.Context(), opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&chartSrc, "chart", "", "optional chart directory or archive")
+	cmd.Flags().StringVar(&chartSrc, "chart", "", "chart directory, archive, URL or repo/chart reference (defaults to the chart the release was installed from)")
+	cmd.Flags().BoolVar(&storedChart, "use-stored-chart", false, "render from the copy of the chart stored with the release's current revision")
+	cmd.MarkFlagsMutuallyExclusive("chart", "use-stored-chart")
+	cmd.Flags().StringVar(&chartVersion, "version", "", "chart version or semver range (e.g. ^1.2) to use from a repository")
+	cmd.Flags().BoolVar(&verify.Enabled, "verify", false, "refuse to load the chart unless its provenance file is signed by a key in the keyring")
+	cmd.Flags().StringVar(&verify.Keyring, "keyring", "", "public key file or directory used by --verify (default $XDG_CONFIG_HOME/composepack/keyring)")
+	cmd.Flags().StringVar(&verify.Checksum, "checksum", "", "refuse to load the chart unless its archive has this sha256:<hex> digest")
 	cmd.Flags().StringArrayVarP(&valueFiles, "values", "f", nil, "values files to include")
-	cmd.Flags().StringArrayVar(&setValues, "set", nil, "direct values to set")
+	cmd.Flags().StringArrayVar(&setValues.Values, "set", nil, "set values on the command line (a.b=1,list[0]=x,tags={a,b})")
+	cmd.Flags().StringArrayVar(&setValues.Strings, "set-string", nil, "set STRING values on the command line, without type inference")
+	cmd.Flags().StringArrayVar(&setValues.JSON, "set-json", nil, "set JSON values on the command line (key=<json>)")
+	cmd.Flags().StringArrayVar(&setValues.Files, "set-file", nil, "set values from files (key=path)")
+	cmd.Flags().BoolVar(&reuseValues, "reuse-values", false, "merge -f/--set values over the values supplied to the current revision")
+	cmd.Flags().BoolVar(&resetValues, "reset-values", false, "ignore the values supplied to the current revision and start from the chart defaults")
+	cmd.MarkFlagsMutuallyExclusive("reuse-values", "reset-values")
 	cmd.Flags().BoolVarP(&detach, "detach", "d", false, "pass --detach to docker compose up")
 	cmd.Flags().StringVar(&runtimeDir, "runtime-dir", "", "path to existing release directory (overrides --release-dir)")
+	cmd.Flags().IntVar(&maxHistory, "history-max", application.Runtime.Config.MaxHistory, "maximum number of revisions kept per release (0 for no limit)")
+	cmd.Flags().BoolVar(&dryRun.Enabled, "dry-run", false, "render and print the compose YAML without writing the runtime directory")
+	cmd.Flags().BoolVar(&dryRun.ShowFiles, "show-files", false, "with --dry-run, list rendered files/ entries")
+	cmd.Flags().BoolVar(&dryRun.ShowFileContents, "show-file-contents", false, "with --dry-run, print the contents of rendered files/ entries")
+	cmd.Flags().BoolVar(&wait.Enabled, "wait", false, "wait until every service is running and healthy (or exited 0)")
+	cmd.Flags().DurationVar(&wait.Timeout, "timeout", app.DefaultWaitTimeout, "how long --wait waits before failing")
 
 	return cmd
 }
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"composepack/internal/core/values"
+)
+
+// maxExplainValue truncates long values (such as --set-file contents) in explain tables.
+const maxExplainValue = 60
+
+// printExplanations prints each key's effective value followed by the layers that set it,
+// earliest first; a layer that set a parent or child key shows that key too.
+func printExplanations(out io.Writer, explanations []values.Explanation) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, exp := range explanations {
+		fmt.Fprintf(w, "%s = %s\n", exp.Key, formatExplainValue(exp.Value))
+		for _, origin := range exp.Chain {
+			value := formatExplainValue(origin.Value)
+			if origin.Path != exp.Key {
+				value = origin.Path + " = " + value
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", origin.Source, value)
+		}
+	}
+	return w.Flush()
+}
+
+// formatExplainValue renders a value as compact JSON so strings and numbers stay distinguishable.
+func formatExplainValue(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := string(raw)
+	if len(s) > maxExplainValue {
+		s = s[:maxExplainValue-3] + "..."
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"composepack/internal/app"
+	"composepack/internal/provenance"
+)
+
+// NewVerifyCommand checks a packaged chart against its provenance file.
+func NewVerifyCommand() *cobra.Command {
+	var (
+		keyringPath string
+		provPath    string
+		checksum    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "verify <archive>",
+		Short: "Verify a chart archive's signature (and optionally its checksum)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			archive := args[0]
+			if keyringPath == "" {
+				var err error
+				if keyringPath, err = app.DefaultKeyring(); err != nil {
+					return err
+				}
+			}
+			if provPath == "" {
+				provPath = archive + provenance.Extension
+			}
+
+			if checksum != "" {
+				if err := provenance.VerifyChecksum(archive, checksum); err != nil {
+					return err
+				}
+			}
+			keyring, err := provenance.LoadKeyring(keyringPath)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(provPath)
+			if err != nil {
+				return fmt.Errorf("read provenance file: %w", err)
+			}
+			result, err := provenance.Verify(archive, data, keyring)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Signed by key: %s\n", result.KeyID)
+			fmt.Fprintf(out, "Chart: %s %s\n", result.Statement.Name, result.Statement.Version)
+			fmt.Fprintf(out, "Digest: %s\n", result.Statement.Digest)
+			fmt.Fprintf(out, "Signed at: %s\n", result.Statement.Created.Local().Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyringPath, "keyring", "", "public key file or directory (default $XDG_CONFIG_HOME/composepack/keyring)")
+	cmd.Flags().StringVar(&provPath, "provenance", "", "provenance file (default <archive>.prov)")
+	cmd.Flags().StringVar(&checksum, "checksum", "", "also require this sha256:<hex> archive digest")
+
+	return cmd
+}
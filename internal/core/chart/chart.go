@@ -2,6 +2,7 @@ package chart
 
 import (
 	"context"
+	"io/fs"
 
 	"composepack/internal/util/fileloader"
 )
@@ -14,7 +15,10 @@ const (
 	TemplatesCompose   = "templates/compose"
 	TemplatesFiles     = "templates/files"
 	TemplatesHelpers   = "templates/helpers"
+	NotesFile          = "templates/NOTES.txt"
 	FilesDir           = "files"
+	ChartsDir          = "charts"
+	LockFile           = "Chart.lock"
 	TemplateFileSuffix = ".tpl"
 )
 
@@ -33,22 +37,55 @@ func (f LoaderFunc) Load(ctx context.Context, source string) (*Chart, error) {
 
 // ChartMetadata mirrors Helm-style metadata fields.
 type ChartMetadata struct {
-	Name        string   `yaml:"name"`
-	Version     string   `yaml:"version"`
-	Description string   `yaml:"description,omitempty"`
-	Maintainers []string `yaml:"maintainers,omitempty"`
+	Name         string       `yaml:"name"`
+	Version      string       `yaml:"version"`
+	Description  string       `yaml:"description,omitempty"`
+	Maintainers  []string     `yaml:"maintainers,omitempty"`
+	Dependencies []Dependency `yaml:"dependencies,omitempty"`
+}
+
+// Dependency declares a subchart in Chart.yaml.
+type Dependency struct {
+	Name    string `yaml:"name"`
+	Version string `yaml:"version"`
+	// Repository is an HTTP(S) base URL, an `oci://` registry path or a `file://` path relative
+	// to the chart directory.
+	Repository string `yaml:"repository,omitempty"`
+	// Alias renames the subchart; values and fragments are namespaced under it.
+	Alias string `yaml:"alias,omitempty"`
+	// Condition is a dotted values path that disables the subchart when it resolves to false.
+	Condition string `yaml:"condition,omitempty"`
+}
+
+// Key returns the name the dependency is addressed by (alias when set).
+func (d Dependency) Key() string {
+	if d.Alias != "" {
+		return d.Alias
+	}
+	return d.Name
+}
+
+// Subchart is a resolved dependency together with the declaration that pulled it in.
+type Subchart struct {
+	Dependency Dependency
+	Chart      *Chart
 }
 
 // Chart captures a fully loaded chart from disk/archive.
 type Chart struct {
 	Metadata      ChartMetadata
-	BaseDir       string
+	BaseDir       string // chart directory on disk; empty for archives and embedded charts
+	FS            fs.FS  // the chart's files, rooted at Chart.yaml
+	ArchiveDigest string // sha256:<hex> of the archive the chart was read from; empty for directories
+	Digest        string // sha256:<hex> of the chart's contents, see ContentDigest
 	Values        map[string]any
 	ValuesSchema  []byte
 	ComposeTpls   map[string]string // templates/compose/*.tpl.yaml (rendered to Compose YAML)
 	FileTemplates map[string]string // templates/files/**/*.tpl (rendered to runtime files)
 	HelperTpls    map[string]string // templates/helpers/**/*.tpl (include-only snippets)
+	NotesTpl      string            // templates/NOTES.txt (rendered and shown after install/up)
 	StaticFiles   map[string][]byte // files/**/* (non-templated assets copied verbatim)
+	Subcharts     []*Subchart       // resolved Chart.yaml dependencies, in declaration order
 }
 
 // LoadFromDirectory is a convenience wrapper around the filesystem loader.
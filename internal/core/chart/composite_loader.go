@@ -1,50 +1,58 @@
 package chart
 
 import (
-	"archive/tar"
-	"compress/gzip"
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+
+	"composepack/internal/infra/cache"
+	"composepack/internal/infra/oci"
 )
 
 // CompositeLoader delegates to filesystem or archive loader based on source path.
 type CompositeLoader struct {
-	fs *FileSystemChartLoader
+	fs    *FileSystemChartLoader
+	cache *cache.Cache
 }
 
-// NewCompositeLoader builds a loader that supports directories and archives.
-func NewCompositeLoader(fsLoader *FileSystemChartLoader) *CompositeLoader {
-	return &CompositeLoader{fs: fsLoader}
+// NewCompositeLoader builds a loader that supports directories and archives; remote charts
+// are kept in charts so later loads revalidate instead of downloading again.
+func NewCompositeLoader(fsLoader *FileSystemChartLoader, charts *cache.Cache) *CompositeLoader {
+	if charts == nil {
+		charts = cache.New()
+	}
+	if charts.MaxSize == 0 {
+		charts.MaxSize = DefaultExtractLimits.MaxTotalSize
+	}
+	return &CompositeLoader{fs: fsLoader, cache: charts}
 }
 
-// Load inspects the source and loads from tar/tgz archives or directories.
+// Load inspects the source and loads from tar/tgz archives, directories, URLs or OCI references.
 func (l *CompositeLoader) Load(ctx context.Context, source string) (*Chart, error) {
 	if source == "" {
 		return nil, fmt.Errorf("chart source must be provided")
 	}
-	resolved := source
-	cleanup := func() {}
-	if isURL(source) {
-		var err error
-		resolved, cleanup, err = l.downloadIfURL(ctx, source)
+	if isURL(source) || oci.IsReference(source) {
+		cached, err := l.fetchRemote(ctx, source)
 		if err != nil {
 			return nil, err
 		}
-		defer cleanup()
-		source = resolved
+		source = cached
 	}
 
 	if info, err := os.Stat(source); err == nil {
 		if info.IsDir() {
-			return l.fs.Load(ctx, source)
+			return l.loadDir(ctx, source, 0)
 		}
 		if looksLikeArchive(source) {
-			return l.loadArchive(ctx, source)
+			return l.loadArchiveAt(ctx, source, 0)
 		}
 		return nil, fmt.Errorf("chart source %q is not a directory", source)
 	} else if !os.IsNotExist(err) {
@@ -52,91 +60,95 @@ func (l *CompositeLoader) Load(ctx context.Context, source string) (*Chart, erro
 	}
 
 	if looksLikeArchive(source) {
-		return l.loadArchive(ctx, source)
+		return l.loadArchiveAt(ctx, source, 0)
 	}
 
 	return nil, fmt.Errorf("chart source %q not found", source)
 }
 
-func (l *CompositeLoader) loadArchive(ctx context.Context, source string) (*Chart, error) {
-	tmpDir, err := os.MkdirTemp("", "composepack-chart-*")
+// loadDir loads a chart directory and resolves its dependencies.
+func (l *CompositeLoader) loadDir(ctx context.Context, dir string, depth int) (*Chart, error) {
+	ch, err := l.fs.Load(ctx, dir)
 	if err != nil {
-		return nil, fmt.Errorf("create temp dir: %w", err)
+		return nil, err
 	}
-	defer os.RemoveAll(tmpDir)
-	if err := extractArchive(source, tmpDir); err != nil {
+	if err := l.resolveDependencies(ctx, ch, depth); err != nil {
 		return nil, err
 	}
-	root, err := findChartRoot(tmpDir)
-	if err != nil {
+	if ch.Digest, err = ContentDigest(ch); err != nil {
 		return nil, err
 	}
-	return l.fs.Load(ctx, root)
+	return ch, nil
 }
 
-func looksLikeArchive(path string) bool {
-	lower := strings.ToLower(path)
-	return strings.HasSuffix(lower, ".tar") ||
-		strings.HasSuffix(lower, ".tar.gz") ||
-		strings.HasSuffix(lower, ".tgz") ||
-		strings.HasSuffix(lower, ".cpack") ||
-		strings.HasSuffix(lower, ".cpack.tgz")
+// LoadFS loads the chart rooted at fsys (for example a sub-tree of an embed.FS) and resolves
+// the dependencies vendored under its charts/ directory.
+func (l *CompositeLoader) LoadFS(ctx context.Context, fsys fs.FS) (*Chart, error) {
+	return l.loadFS(ctx, fsys, "", 0)
 }
 
-func extractArchive(path, dest string) error {
-	file, err := os.Open(path)
+// loadFS loads a chart from fsys; baseDir is its directory on disk, if any, for resolving
+// `file://` dependencies.
+func (l *CompositeLoader) loadFS(ctx context.Context, fsys fs.FS, baseDir string, depth int) (*Chart, error) {
+	ch, err := l.fs.LoadFS(ctx, fsys)
 	if err != nil {
-		return fmt.Errorf("open archive: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	var reader io.Reader = file
-	if strings.HasSuffix(strings.ToLower(path), ".gz") || strings.HasSuffix(strings.ToLower(path), ".tgz") {
-		gz, err := gzip.NewReader(file)
-		if err != nil {
-			return fmt.Errorf("create gzip reader: %w", err)
-		}
-		defer gz.Close()
-		reader = gz
+	ch.BaseDir = baseDir
+	if err := l.resolveDependencies(ctx, ch, depth); err != nil {
+		return nil, err
 	}
+	if ch.Digest, err = ContentDigest(ch); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
 
-	tr := tar.NewReader(reader)
-	for {
-		header, err := tr.Next()
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("read archive: %w", err)
-		}
+// loadArchiveAt loads the archive at source and records its digest on the chart.
+func (l *CompositeLoader) loadArchiveAt(ctx context.Context, source string, depth int) (*Chart, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	ch, err := l.loadArchive(ctx, source, io.TeeReader(file, hash), depth)
+	if err != nil {
+		return nil, err
+	}
+	// the reader may stop before trailing padding; hash the whole file
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, fmt.Errorf("hash archive: %w", err)
+	}
+	ch.ArchiveDigest = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	return ch, nil
+}
 
-		name := header.Name
-		if shouldSkipArchiveEntry(name) {
-			continue
-		}
-		target := filepath.Join(dest, name)
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
-				return fmt.Errorf("create dir %s: %w", target, err)
-			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-				return fmt.Errorf("create file dir: %w", err)
-			}
-			out, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR|os.O_TRUNC, os.FileMode(header.Mode))
-			if err != nil {
-				return fmt.Errorf("create file %s: %w", target, err)
-			}
-			if _, err := io.Copy(out, tr); err != nil {
-				out.Close()
-				return fmt.Errorf("write file %s: %w", target, err)
-			}
-			out.Close()
-		}
+// loadArchive reads an archive into memory and loads the chart inside it; nothing is
+// written to disk.
+func (l *CompositeLoader) loadArchive(ctx context.Context, name string, r io.Reader, depth int) (*Chart, error) {
+	archive, err := readArchive(name, r)
+	if err != nil {
+		return nil, err
 	}
+	root, err := findChartRoot(archive)
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := fs.Sub(archive, root)
+	if err != nil {
+		return nil, err
+	}
+	return l.loadFS(ctx, fsys, "", depth)
+}
 
-	return nil
+func looksLikeArchive(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".tar") ||
+		strings.HasSuffix(lower, ".tar.gz") ||
+		strings.HasSuffix(lower, ".tgz") ||
+		strings.HasSuffix(lower, ".cpack") ||
+		strings.HasSuffix(lower, ".cpack.tgz")
 }
 
 func shouldSkipArchiveEntry(name string) bool {
@@ -144,38 +156,37 @@ func shouldSkipArchiveEntry(name string) bool {
 	if strings.HasPrefix(base, "._") || base == ".DS_Store" {
 		return true
 	}
-	if strings.HasPrefix(name, "__MACOSX/") {
+	if name == "__MACOSX" || strings.HasPrefix(name, "__MACOSX/") {
 		return true
 	}
 	return false
 }
 
-func findChartRoot(base string) (string, error) {
-	var chartDir string
-	err := filepath.WalkDir(base, func(path string, d os.DirEntry, err error) error {
+// findChartRoot returns the shallowest directory containing Chart.yaml so vendored
+// subcharts under charts/ are never mistaken for the archive's root chart.
+func findChartRoot(fsys fs.FS) (string, error) {
+	chartDir := ""
+	depth := -1
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		rel, relErr := filepath.Rel(base, path)
-		if relErr != nil {
-			return relErr
-		}
-		if rel == "." {
-			rel = ""
-		}
-		if d.IsDir() && shouldSkipArchiveEntry(rel) {
-			return filepath.SkipDir
+		if d.IsDir() && name != "." && shouldSkipArchiveEntry(name) {
+			return fs.SkipDir
 		}
 		if !d.IsDir() && strings.EqualFold(d.Name(), MetadataFile) {
-			chartDir = filepath.Dir(path)
-			return io.EOF
+			level := strings.Count(name, "/")
+			if depth < 0 || level < depth {
+				chartDir = path.Dir(name)
+				depth = level
+			}
 		}
 		return nil
 	})
-	if err != nil && err != io.EOF {
+	if err != nil {
 		return "", err
 	}
-	if chartDir == "" {
+	if depth < 0 {
 		return "", fmt.Errorf("chart archive missing %s", MetadataFile)
 	}
 	return chartDir, nil
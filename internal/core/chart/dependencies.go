@@ -0,0 +1,163 @@
+package chart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// maxDependencyDepth guards against dependency cycles between charts.
+const maxDependencyDepth = 10
+
+// VersionMatches reports whether version satisfies constraint (an exact version or semver range).
+// An empty constraint matches everything.
+func VersionMatches(constraint, version string) bool {
+	if constraint == "" || constraint == version {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
+
+// IsLocalRepository reports whether a dependency repository points at a local chart directory.
+func IsLocalRepository(repository string) bool {
+	return strings.HasPrefix(repository, "file://")
+}
+
+// LocalRepositoryPath resolves a `file://` repository relative to the declaring chart directory.
+func LocalRepositoryPath(chartDir, repository string) string {
+	path := strings.TrimPrefix(repository, "file://")
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(chartDir, filepath.FromSlash(path))
+}
+
+// resolveDependencies loads every dependency declared in Chart.yaml from `charts/`
+// (directories or archives) or, for `file://` repositories, straight from disk.
+func (l *CompositeLoader) resolveDependencies(ctx context.Context, ch *Chart, depth int) error {
+	if len(ch.Metadata.Dependencies) == 0 {
+		return nil
+	}
+	if depth >= maxDependencyDepth {
+		return fmt.Errorf("chart %s: dependency nesting exceeds %d levels (cycle?)", ch.Metadata.Name, maxDependencyDepth)
+	}
+
+	vendored, err := l.loadVendoredCharts(ctx, ch, depth)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]struct{}{}
+	for _, dep := range ch.Metadata.Dependencies {
+		if dep.Name == "" {
+			return fmt.Errorf("chart %s: dependency name is required", ch.Metadata.Name)
+		}
+		key := dep.Key()
+		if _, dup := seen[key]; dup {
+			return fmt.Errorf("chart %s: duplicate dependency %q (use alias to include a chart twice)", ch.Metadata.Name, key)
+		}
+		seen[key] = struct{}{}
+
+		sub := matchVendored(vendored, dep)
+		if sub == nil && IsLocalRepository(dep.Repository) {
+			if ch.BaseDir == "" {
+				return fmt.Errorf("chart %s: dependency %s uses %s, which only works for chart directories; vendor it under %s/ before packaging", ch.Metadata.Name, dep.Name, dep.Repository, ChartsDir)
+			}
+			path := LocalRepositoryPath(ch.BaseDir, dep.Repository)
+			sub, err = l.loadDir(ctx, path, depth+1)
+			if err != nil {
+				return fmt.Errorf("chart %s: load dependency %s from %s: %w", ch.Metadata.Name, dep.Name, path, err)
+			}
+			if sub.Metadata.Name != dep.Name {
+				return fmt.Errorf("chart %s: dependency %s resolved to chart %s", ch.Metadata.Name, dep.Name, sub.Metadata.Name)
+			}
+			if !VersionMatches(dep.Version, sub.Metadata.Version) {
+				return fmt.Errorf("chart %s: dependency %s version %s does not satisfy %s", ch.Metadata.Name, dep.Name, sub.Metadata.Version, dep.Version)
+			}
+		}
+		if sub == nil {
+			return fmt.Errorf("chart %s: dependency %s %s not found in %s/ (run composepack dependency build)", ch.Metadata.Name, dep.Name, dep.Version, ChartsDir)
+		}
+
+		ch.Subcharts = append(ch.Subcharts, &Subchart{Dependency: dep, Chart: sub})
+	}
+	return nil
+}
+
+// loadVendoredCharts loads every chart directory or archive found under `<chart>/charts/`,
+// reading through the chart's own filesystem so archived charts stay in memory.
+func (l *CompositeLoader) loadVendoredCharts(ctx context.Context, ch *Chart, depth int) ([]*Chart, error) {
+	entries, err := fs.ReadDir(ch.FS, ChartsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", ChartsDir, err)
+	}
+
+	var out []*Chart
+	for _, entry := range entries {
+		name := entry.Name()
+		if shouldSkipArchiveEntry(name) || strings.HasPrefix(name, ".") {
+			continue
+		}
+		var sub *Chart
+		switch {
+		case entry.IsDir():
+			sub, err = l.loadVendoredDir(ctx, ch, name, depth+1)
+		case looksLikeArchive(name):
+			sub, err = l.loadVendoredArchive(ctx, ch, name, depth+1)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("load %s/%s: %w", ChartsDir, name, err)
+		}
+		out = append(out, sub)
+	}
+	return out, nil
+}
+
+func (l *CompositeLoader) loadVendoredDir(ctx context.Context, ch *Chart, name string, depth int) (*Chart, error) {
+	fsys, err := fs.Sub(ch.FS, path.Join(ChartsDir, name))
+	if err != nil {
+		return nil, err
+	}
+	baseDir := ""
+	if ch.BaseDir != "" {
+		baseDir = filepath.Join(ch.BaseDir, ChartsDir, name)
+	}
+	return l.loadFS(ctx, fsys, baseDir, depth)
+}
+
+func (l *CompositeLoader) loadVendoredArchive(ctx context.Context, ch *Chart, name string, depth int) (*Chart, error) {
+	file, err := ch.FS.Open(path.Join(ChartsDir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return l.loadArchive(ctx, name, file, depth)
+}
+
+func matchVendored(charts []*Chart, dep Dependency) *Chart {
+	for _, ch := range charts {
+		if ch.Metadata.Name == dep.Name && VersionMatches(dep.Version, ch.Metadata.Version) {
+			return ch
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package chart
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+// ContentDigest fingerprints a chart by what it contains: the path and content of every file in
+// its layout (see InLayout), in sorted order, followed by the digest of each resolved dependency.
+// Timestamps, permissions, packaging and unrelated files in the chart directory do not count, so
+// a chart directory and an archive of it share a digest.
+func ContentDigest(ch *Chart) (string, error) {
+	if ch.FS == nil {
+		return "", fmt.Errorf("chart %s has no files", ch.Metadata.Name)
+	}
+	var names []string
+	err := fs.WalkDir(ch.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		// vendored charts count through the dependencies they resolve to, and the lock only
+		// pins what those digests already cover
+		if name == ChartsDir || name == LockFile || !InLayout(name, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("digest chart %s: %w", ch.Metadata.Name, err)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		data, err := fs.ReadFile(ch.FS, name)
+		if err != nil {
+			return "", fmt.Errorf("digest chart %s: %w", ch.Metadata.Name, err)
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(data))
+		hash.Write(data)
+	}
+	for _, sub := range ch.Subcharts {
+		fmt.Fprintf(hash, "%s/%s\x00%s\x00", ChartsDir, sub.Dependency.Key(), sub.Chart.Digest)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,145 @@
+package chart_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/cache"
+	"composepack/internal/packager"
+	"composepack/internal/util/fileloader"
+)
+
+func testLoader(t *testing.T) *chart.CompositeLoader {
+	fsLoader := chart.NewFileSystemChartLoader(fileloader.NewFileSystemLoader())
+	return chart.NewCompositeLoader(fsLoader, &cache.Cache{Dir: t.TempDir()})
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+var demoChart = map[string]string{
+	"Chart.yaml":                     "name: demo\nversion: 0.1.0\n",
+	"values.yaml":                    "image: nginx\n",
+	"values.schema.json":             `{"type": "object"}`,
+	"templates/compose/app.tpl.yaml": "services:\n  web:\n    image: {{ .Values.image }}\n",
+	"templates/files/app.conf.tpl":   "image={{ .Values.image }}\n",
+	"files/static.txt":               "static\n",
+}
+
+func TestContentDigestDirectoryAndArchive(t *testing.T) {
+	ctx := context.Background()
+	loader := testLoader(t)
+	dir := filepath.Join(t.TempDir(), "demo")
+	writeFiles(t, dir, demoChart)
+
+	fromDir, err := loader.Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("load directory: %v", err)
+	}
+	archive, err := packager.PackageChart(ctx, loader, packager.Options{ChartPath: dir, Destination: t.TempDir()})
+	if err != nil {
+		t.Fatalf("package: %v", err)
+	}
+	fromArchive, err := loader.Load(ctx, archive)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	if fromDir.Digest == "" || fromDir.Digest != fromArchive.Digest {
+		t.Errorf("directory digest %q, archive digest %q", fromDir.Digest, fromArchive.Digest)
+	}
+}
+
+func TestContentDigestIgnoresUnrelatedFiles(t *testing.T) {
+	ctx := context.Background()
+	loader := testLoader(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, demoChart)
+	base, err := loader.Load(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFiles(t, dir, map[string]string{
+		".cpack-releases/demo/release.json":     "{}",
+		"tests/web_test.yaml":                   "suite: web\ntests: []\n",
+		"tests/__snapshot__/web_test.yaml.snap": "a 1: x\n",
+		"README.md":                             "# demo\n",
+		"Chart.lock":                            "dependencies: []\n",
+		".git/HEAD":                             "ref: refs/heads/main\n",
+		"files/.DS_Store":                       "junk",
+		"files/.#static.txt":                    "lock",
+		"values.yaml~":                          "image: old\n",
+		"files/.static.txt.swp":                 "swap",
+	})
+	cluttered, err := loader.Load(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluttered.Digest != base.Digest {
+		t.Errorf("unrelated files changed the digest from %s to %s", base.Digest, cluttered.Digest)
+	}
+
+	for name, content := range map[string]string{
+		"values.yaml":      "image: redis\n",
+		"files/static.txt": "changed\n",
+		"values-prod.yaml": "image: nginx:prod\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, demoChart)
+			writeFiles(t, dir, map[string]string{name: content})
+			changed, err := loader.Load(ctx, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed.Digest == base.Digest {
+				t.Errorf("changing %s kept the digest", name)
+			}
+		})
+	}
+}
+
+func TestInLayout(t *testing.T) {
+	tests := map[string]bool{
+		"Chart.yaml":                        true,
+		"Chart.lock":                        true,
+		"values.yaml":                       true,
+		"values.schema.json":                true,
+		"values-prod.yaml":                  true,
+		"templates/compose/app.tpl.yaml":    true,
+		"files/config/app.conf":             true,
+		"charts/child-1.0.0/Chart.yaml":     true,
+		"README.md":                         false,
+		"tests/web_test.yaml":               false,
+		".cpack-releases/demo/release.json": false,
+		"values/extra.yaml":                 false,
+		"files/.DS_Store":                   false,
+		"templates/compose/app.tpl.yaml~":   false,
+	}
+	for name, want := range tests {
+		if got := chart.InLayout(name, false); got != want {
+			t.Errorf("InLayout(%q) = %v, want %v", name, got, want)
+		}
+	}
+	for _, dir := range []string{"templates", "files", "charts"} {
+		if !chart.InLayout(dir, true) {
+			t.Errorf("InLayout(%q, dir) = false", dir)
+		}
+	}
+	if chart.InLayout(".cpack-releases", true) {
+		t.Error("the release directory is in the layout")
+	}
+}
@@ -0,0 +1,160 @@
+package chart
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"composepack/internal/util/fileloader"
+)
+
+// ExtractLimits bound what a chart archive may expand to.
+type ExtractLimits struct {
+	// MaxEntries caps files plus directories.
+	MaxEntries int
+	// MaxFileSize caps a single file's uncompressed size.
+	MaxFileSize int64
+	// MaxTotalSize caps the sum of all uncompressed file sizes.
+	MaxTotalSize int64
+}
+
+// DefaultExtractLimits are generous for real charts yet stop decompression bombs.
+var DefaultExtractLimits = ExtractLimits{
+	MaxEntries:   10000,
+	MaxFileSize:  64 << 20,
+	MaxTotalSize: 256 << 20,
+}
+
+// ErrUnsafeArchive is wrapped by every rejection of a hostile or oversized archive.
+var ErrUnsafeArchive = errors.New("unsafe chart archive")
+
+// readArchive loads a tar or gzipped tar chart archive into memory; name only selects the
+// compression by its extension.
+func readArchive(name string, r io.Reader) (*fileloader.MemFS, error) {
+	return readArchiveLimits(name, r, DefaultExtractLimits)
+}
+
+func readArchiveLimits(name string, r io.Reader, limits ExtractLimits) (*fileloader.MemFS, error) {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("create gzip reader: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return ReadTar(r, limits)
+}
+
+// ReadTar loads an uncompressed tar stream into an in-memory filesystem without touching
+// disk. It only accepts regular files and directories, normalizes modes to 0644/0755, and
+// rejects absolute or escaping paths, links, device entries, duplicates and anything
+// exceeding limits.
+func ReadTar(r io.Reader, limits ExtractLimits) (*fileloader.MemFS, error) {
+	tr := tar.NewReader(r)
+	fsys := fileloader.NewMemFS()
+	seen := map[string]struct{}{}
+	var total int64
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return fsys, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeXGlobalHeader, tar.TypeXHeader:
+			continue
+		case tar.TypeDir, tar.TypeReg:
+		case tar.TypeSymlink, tar.TypeLink:
+			return nil, fmt.Errorf("%w: entry %q is a link (links are not allowed in charts)", ErrUnsafeArchive, header.Name)
+		default:
+			return nil, fmt.Errorf("%w: entry %q has unsupported type %q", ErrUnsafeArchive, header.Name, string(header.Typeflag))
+		}
+
+		name, err := sanitizeEntryName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" || shouldSkipArchiveEntry(name) {
+			continue
+		}
+		if _, dup := seen[name]; dup {
+			return nil, fmt.Errorf("%w: entry %q appears more than once", ErrUnsafeArchive, name)
+		}
+		seen[name] = struct{}{}
+		if limits.MaxEntries > 0 && len(seen) > limits.MaxEntries {
+			return nil, fmt.Errorf("%w: more than %d entries", ErrUnsafeArchive, limits.MaxEntries)
+		}
+
+		if header.Typeflag == tar.TypeDir {
+			if err := fsys.AddDir(name); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+			}
+			continue
+		}
+
+		if header.Size < 0 || (limits.MaxFileSize > 0 && header.Size > limits.MaxFileSize) {
+			return nil, fmt.Errorf("%w: entry %q is %d bytes (limit %d)", ErrUnsafeArchive, name, header.Size, limits.MaxFileSize)
+		}
+		total += header.Size
+		if limits.MaxTotalSize > 0 && total > limits.MaxTotalSize {
+			return nil, fmt.Errorf("%w: contents exceed %d bytes", ErrUnsafeArchive, limits.MaxTotalSize)
+		}
+		data, err := io.ReadAll(io.LimitReader(tr, header.Size+1))
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: entry %s is shorter than its header says (%d bytes)", ErrUnsafeArchive, name, header.Size)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		if int64(len(data)) != header.Size {
+			return nil, fmt.Errorf("%w: entry %s has %d bytes, header says %d", ErrUnsafeArchive, name, len(data), header.Size)
+		}
+		if err := fsys.AddFile(name, data, safeMode(header.Mode), header.ModTime); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsafeArchive, err)
+		}
+	}
+}
+
+// sanitizeEntryName normalizes a tar entry name and rejects names that would land outside
+// the extraction directory. The root entry ("./") maps to "".
+func sanitizeEntryName(name string) (string, error) {
+	if strings.ContainsRune(name, 0) || strings.Contains(name, `\`) {
+		return "", fmt.Errorf("%w: entry %q has an invalid name", ErrUnsafeArchive, name)
+	}
+	if strings.HasPrefix(name, "/") || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: entry %q has an absolute path", ErrUnsafeArchive, name)
+	}
+	cleaned := path.Clean(name)
+	if cleaned == "." {
+		return "", nil
+	}
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: entry %q escapes the archive root", ErrUnsafeArchive, name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: entry %q contains '..'", ErrUnsafeArchive, name)
+		}
+	}
+	return cleaned, nil
+}
+
+// safeMode keeps only the executable bit of an entry's permissions.
+func safeMode(mode int64) fs.FileMode {
+	if mode&0o111 != 0 {
+		return 0o755
+	}
+	return 0o644
+}
@@ -0,0 +1,195 @@
+package chart
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testLimits keep the oversized cases small enough to build in memory.
+var testLimits = ExtractLimits{MaxEntries: 100, MaxFileSize: 1 << 20, MaxTotalSize: 4 << 20}
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     []byte
+	// size overrides the header size; the body is written as is.
+	size     int64
+	linkname string
+}
+
+// buildTar writes entries without the end-of-archive trailer checks tar.Writer enforces, so
+// headers may lie about their sizes.
+func buildTar(entries ...tarEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		typeflag := e.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		size := int64(len(e.body))
+		if e.size != 0 {
+			size = e.size
+		}
+		if typeflag != tar.TypeReg {
+			size = 0
+		}
+		var hdr bytes.Buffer
+		tw := tar.NewWriter(&hdr)
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     e.name,
+			Typeflag: typeflag,
+			Size:     size,
+			Mode:     0o644,
+			Linkname: e.linkname,
+			ModTime:  time.Unix(0, 0),
+			Format:   tar.FormatPAX,
+		}); err != nil {
+			panic(err)
+		}
+		// WriteHeader flushes the header blocks; the body is appended raw
+		buf.Write(hdr.Bytes())
+		buf.Write(e.body)
+		if pad := len(e.body) % 512; pad != 0 {
+			buf.Write(make([]byte, 512-pad))
+		}
+	}
+	buf.Write(make([]byte, 1024))
+	return buf.Bytes()
+}
+
+func gzipped(data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	gz.Close()
+	return buf.Bytes()
+}
+
+// hostileArchives must all be rejected with ErrUnsafeArchive.
+func hostileArchives() map[string][]byte {
+	zeros := make([]byte, 900<<10)
+	var bomb []tarEntry
+	for i := 0; i < 8; i++ {
+		bomb = append(bomb, tarEntry{name: fmt.Sprintf("files/zero-%d", i), body: zeros})
+	}
+	var crowd []tarEntry
+	for i := 0; i <= testLimits.MaxEntries; i++ {
+		crowd = append(crowd, tarEntry{name: fmt.Sprintf("f%d", i)})
+	}
+	return map[string][]byte{
+		"dotdot":         buildTar(tarEntry{name: "../evil", body: []byte("x")}),
+		"nested dotdot":  buildTar(tarEntry{name: "chart/../../evil", body: []byte("x")}),
+		"dotdot segment": buildTar(tarEntry{name: "chart/../Chart.yaml", body: []byte("x")}),
+		"absolute":       buildTar(tarEntry{name: "/etc/passwd", body: []byte("x")}),
+		"backslash":      buildTar(tarEntry{name: `..\evil`, body: []byte("x")}),
+		"symlink":        buildTar(tarEntry{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}),
+		"hardlink":       buildTar(tarEntry{name: "link", typeflag: tar.TypeLink, linkname: "Chart.yaml"}),
+		"device":         buildTar(tarEntry{name: "dev", typeflag: tar.TypeChar}),
+		"fifo":           buildTar(tarEntry{name: "fifo", typeflag: tar.TypeFifo}),
+		"duplicate":      buildTar(tarEntry{name: "Chart.yaml", body: []byte("a")}, tarEntry{name: "./Chart.yaml", body: []byte("b")}),
+		"dir under file": buildTar(tarEntry{name: "values.yaml", body: []byte("x")}, tarEntry{name: "values.yaml/x", body: []byte("y")}),
+		"short body":     buildTar(tarEntry{name: "Chart.yaml", body: []byte("name: x"), size: 4096})[:1024],
+		"oversized file": gzipped(buildTar(tarEntry{name: "big", body: make([]byte, 2<<20)})),
+		"gzip bomb":      gzipped(buildTar(bomb...)),
+		"too many":       buildTar(crowd...),
+	}
+}
+
+// readTestArchive picks the decompression the way the loader does, by content here.
+func readTestArchive(data []byte) (fs.FS, error) {
+	name := "chart.tar"
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		name = "chart.tgz"
+	}
+	return readArchiveLimits(name, bytes.NewReader(data), testLimits)
+}
+
+func TestReadTarRejectsHostileArchives(t *testing.T) {
+	for name, data := range hostileArchives() {
+		t.Run(name, func(t *testing.T) {
+			_, err := readTestArchive(data)
+			if !errors.Is(err, ErrUnsafeArchive) {
+				t.Errorf("error = %v, want ErrUnsafeArchive", err)
+			}
+		})
+	}
+}
+
+func TestReadTar(t *testing.T) {
+	data := buildTar(
+		tarEntry{name: "./", typeflag: tar.TypeDir},
+		tarEntry{name: "Chart.yaml", body: []byte("name: demo\n")},
+		tarEntry{name: "templates/compose/app.tpl.yaml", body: []byte("services: {}\n")},
+		tarEntry{name: "._Chart.yaml", body: []byte("resource fork")},
+		tarEntry{name: "__MACOSX/Chart.yaml", body: []byte("resource fork")},
+	)
+	fsys, err := readTestArchive(gzipped(data))
+	if err != nil {
+		t.Fatalf("readArchive: %v", err)
+	}
+	got, err := fs.ReadFile(fsys, "templates/compose/app.tpl.yaml")
+	if err != nil || string(got) != "services: {}\n" {
+		t.Errorf("ReadFile = %q, %v", got, err)
+	}
+	for _, skipped := range []string{"._Chart.yaml", "__MACOSX"} {
+		if _, err := fs.Stat(fsys, skipped); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s was extracted", skipped)
+		}
+	}
+}
+
+func FuzzReadTar(f *testing.F) {
+	for _, data := range hostileArchives() {
+		f.Add(data)
+	}
+	f.Add(buildTar(tarEntry{name: "Chart.yaml", body: []byte("name: demo\n")}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fsys, err := readTestArchive(data)
+		if err != nil {
+			return
+		}
+		var total int64
+		entries := 0
+		err = fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if name == "." {
+				return nil
+			}
+			entries++
+			if !fs.ValidPath(name) || strings.Contains(name, `\`) {
+				t.Errorf("extracted invalid path %q", name)
+			}
+			if !d.IsDir() && !d.Type().IsRegular() {
+				t.Errorf("extracted %q with type %v", name, d.Type())
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() {
+				if info.Size() > testLimits.MaxFileSize {
+					t.Errorf("%q is %d bytes, over the file limit", name, info.Size())
+				}
+				total += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walk extracted archive: %v", err)
+		}
+		// parent directories are implied, so entries may exceed what the archive listed
+		if total > testLimits.MaxTotalSize {
+			t.Errorf("extracted %d bytes, over the total limit", total)
+		}
+	})
+}
@@ -3,53 +3,52 @@ package chart
 import (
 	"context"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
 	"strings"
-)
 
-func (l *CompositeLoader) downloadIfURL(ctx context.Context, source string) (string, func(), error) {
-	if !isURL(source) {
-		return source, func() {}, nil
-	}
+	"composepack/internal/infra/cache"
+	"composepack/internal/infra/oci"
+)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
-	if err != nil {
-		return "", nil, fmt.Errorf("build request: %w", err)
+// fetchRemote returns a cached local archive for an http(s) URL or `oci://` reference.
+func (l *CompositeLoader) fetchRemote(ctx context.Context, source string) (string, error) {
+	if oci.IsReference(source) {
+		return l.pullOCI(ctx, source)
 	}
+	return l.cache.Fetch(ctx, source)
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// pullOCI serves digest-pinned references (and every reference when offline) from the cache
+// and otherwise pulls the artifact, since tags may move.
+func (l *CompositeLoader) pullOCI(ctx context.Context, source string) (string, error) {
+	ref, err := oci.ParseReference(source)
 	if err != nil {
-		return "", nil, fmt.Errorf("download chart: %w", err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return "", nil, fmt.Errorf("download chart: unexpected status %s", resp.Status)
-	}
-	defer resp.Body.Close()
-
-	tmpFile, err := os.CreateTemp("", "composepack-chart-*.cpack.tgz")
+		return "", err
+	}
+	if ref.Digest != "" || l.cache.Offline {
+		entry, ok, err := l.cache.Lookup(ref.String())
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return entry.Path, nil
+		}
+		if l.cache.Offline {
+			return "", fmt.Errorf("%s: %w (offline)", ref, cache.ErrNotCached)
+		}
+	}
+
+	client := oci.NewClient()
+	client.MaxBlobSize = DefaultExtractLimits.MaxTotalSize
+	artifact, _, err := client.Pull(ctx, ref)
 	if err != nil {
-		return "", nil, fmt.Errorf("create temp file: %w", err)
-	}
-
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		tmpFile.Close()
-		os.Remove(tmpFile.Name())
-		return "", nil, fmt.Errorf("save downloaded chart: %w", err)
+		return "", fmt.Errorf("pull %s: %w", ref, err)
 	}
-
-	if err := tmpFile.Close(); err != nil {
-		os.Remove(tmpFile.Name())
-		return "", nil, err
+	if len(artifact.Provenance) > 0 {
+		if _, err := l.cache.Put(ctx, ref.String()+provenanceExtension, artifact.Provenance); err != nil {
+			return "", err
+		}
 	}
-
-	cleanup := func() {
-		os.Remove(tmpFile.Name())
-	}
-
-	return tmpFile.Name(), cleanup, nil
+	return l.cache.Put(ctx, ref.String(), artifact.Archive)
 }
 
 func isURL(source string) bool {
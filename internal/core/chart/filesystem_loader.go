@@ -3,7 +3,8 @@ package chart
 import (
 	"context"
 	"fmt"
-	"path/filepath"
+	"io/fs"
+	"os"
 	"strings"
 
 	"sigs.k8s.io/yaml"
@@ -11,7 +12,7 @@ import (
 	"composepack/internal/util/fileloader"
 )
 
-// FileSystemChartLoader loads charts from directories on disk.
+// FileSystemChartLoader loads charts from directories on disk or from any fs.FS rooted at a chart.
 type FileSystemChartLoader struct {
 	files *fileloader.FileSystemLoader
 }
@@ -30,9 +31,18 @@ func (l *FileSystemChartLoader) Load(ctx context.Context, source string) (*Chart
 	if err != nil {
 		return nil, err
 	}
+	ch, err := l.LoadFS(ctx, os.DirFS(baseDir))
+	if err != nil {
+		return nil, err
+	}
+	ch.BaseDir = baseDir
+	return ch, nil
+}
 
+// LoadFS loads the chart whose Chart.yaml sits at the root of fsys. Dependencies are not resolved.
+func (l *FileSystemChartLoader) LoadFS(ctx context.Context, fsys fs.FS) (*Chart, error) {
 	ch := &Chart{
-		BaseDir:       baseDir,
+		FS:            fsys,
 		ComposeTpls:   map[string]string{},
 		FileTemplates: map[string]string{},
 		HelperTpls:    map[string]string{},
@@ -63,6 +73,10 @@ func (l *FileSystemChartLoader) Load(ctx context.Context, source string) (*Chart
 		return nil, err
 	}
 
+	if err := l.loadNotes(ch); err != nil {
+		return nil, err
+	}
+
 	if err := l.loadStaticFiles(ctx, ch); err != nil {
 		return nil, err
 	}
@@ -71,8 +85,7 @@ func (l *FileSystemChartLoader) Load(ctx context.Context, source string) (*Chart
 }
 
 func (l *FileSystemChartLoader) loadMetadata(ch *Chart) error {
-	metadataPath := filepath.Join(ch.BaseDir, MetadataFile)
-	data, err := l.files.ReadFile(metadataPath)
+	data, err := l.files.ReadFile(ch.FS, MetadataFile)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", MetadataFile, err)
 	}
@@ -90,8 +103,7 @@ func (l *FileSystemChartLoader) loadMetadata(ch *Chart) error {
 }
 
 func (l *FileSystemChartLoader) loadValues(ch *Chart) error {
-	valuesPath := filepath.Join(ch.BaseDir, ValuesFile)
-	data, err := l.files.ReadFileIfExists(valuesPath)
+	data, err := l.files.ReadFileIfExists(ch.FS, ValuesFile)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", ValuesFile, err)
 	}
@@ -108,8 +120,7 @@ func (l *FileSystemChartLoader) loadValues(ch *Chart) error {
 }
 
 func (l *FileSystemChartLoader) loadSchema(ch *Chart) error {
-	schemaPath := filepath.Join(ch.BaseDir, ValuesSchemaFile)
-	data, err := l.files.ReadFileIfExists(schemaPath)
+	data, err := l.files.ReadFileIfExists(ch.FS, ValuesSchemaFile)
 	if err != nil {
 		return fmt.Errorf("read %s: %w", ValuesSchemaFile, err)
 	}
@@ -118,16 +129,14 @@ func (l *FileSystemChartLoader) loadSchema(ch *Chart) error {
 }
 
 func (l *FileSystemChartLoader) loadComposeTemplates(ctx context.Context, ch *Chart) error {
-	dir := filepath.Join(ch.BaseDir, TemplatesCompose)
-	return l.files.WalkFiles(ctx, dir, func(rel string, data []byte) error {
+	return l.files.WalkFiles(ctx, ch.FS, TemplatesCompose, func(rel string, data []byte) error {
 		ch.ComposeTpls[rel] = string(data)
 		return nil
 	})
 }
 
 func (l *FileSystemChartLoader) loadFileTemplates(ctx context.Context, ch *Chart) error {
-	dir := filepath.Join(ch.BaseDir, TemplatesFiles)
-	return l.files.WalkFiles(ctx, dir, func(rel string, data []byte) error {
+	return l.files.WalkFiles(ctx, ch.FS, TemplatesFiles, func(rel string, data []byte) error {
 		if !strings.HasSuffix(rel, TemplateFileSuffix) {
 			return fmt.Errorf("file template %s must end with %s", rel, TemplateFileSuffix)
 		}
@@ -138,16 +147,23 @@ func (l *FileSystemChartLoader) loadFileTemplates(ctx context.Context, ch *Chart
 }
 
 func (l *FileSystemChartLoader) loadHelperTemplates(ctx context.Context, ch *Chart) error {
-	dir := filepath.Join(ch.BaseDir, TemplatesHelpers)
-	return l.files.WalkFiles(ctx, dir, func(rel string, data []byte) error {
+	return l.files.WalkFiles(ctx, ch.FS, TemplatesHelpers, func(rel string, data []byte) error {
 		ch.HelperTpls[rel] = string(data)
 		return nil
 	})
 }
 
+func (l *FileSystemChartLoader) loadNotes(ch *Chart) error {
+	data, err := l.files.ReadFileIfExists(ch.FS, NotesFile)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", NotesFile, err)
+	}
+	ch.NotesTpl = string(data)
+	return nil
+}
+
 func (l *FileSystemChartLoader) loadStaticFiles(ctx context.Context, ch *Chart) error {
-	dir := filepath.Join(ch.BaseDir, FilesDir)
-	return l.files.WalkFiles(ctx, dir, func(rel string, data []byte) error {
+	return l.files.WalkFiles(ctx, ch.FS, FilesDir, func(rel string, data []byte) error {
 		ch.StaticFiles[rel] = data
 		return nil
 	})
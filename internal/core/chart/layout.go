@@ -0,0 +1,30 @@
+package chart
+
+import "strings"
+
+// InLayout reports whether name, slash-separated and relative to the chart root, is part of
+// the chart: Chart.yaml, Chart.lock, values files (values.yaml, values.schema.json, ...),
+// templates/, files/ and charts/. Anything else a chart directory may hold, such as a release
+// directory, tests/ or editor and OS files, is left out of stored archives and digests.
+func InLayout(name string, isDir bool) bool {
+	parts := strings.Split(name, "/")
+	for _, part := range parts {
+		if IgnoredFile(part) {
+			return false
+		}
+	}
+	switch top := parts[0]; {
+	case top == "templates", top == FilesDir, top == ChartsDir:
+		return len(parts) > 1 || isDir
+	case len(parts) > 1 || isDir:
+		return false
+	default:
+		return top == MetadataFile || top == LockFile || strings.HasPrefix(top, "values")
+	}
+}
+
+// IgnoredFile reports whether a file or directory name is VCS, OS or editor metadata.
+func IgnoredFile(name string) bool {
+	return name == ".git" || name == ".DS_Store" || strings.HasPrefix(name, "._") || strings.HasPrefix(name, "__MACOSX") ||
+		strings.HasPrefix(name, ".#") || strings.HasSuffix(name, "~") || strings.HasSuffix(name, ".swp")
+}
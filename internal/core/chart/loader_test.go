@@ -0,0 +1,74 @@
+package chart_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/packager"
+)
+
+func TestLoadDirectoryAndArchive(t *testing.T) {
+	ctx := context.Background()
+	loader := testLoader(t)
+	dir := filepath.Join(t.TempDir(), "demo")
+	writeFiles(t, dir, demoChart)
+	writeFiles(t, dir, map[string]string{
+		"templates/helpers/names.tpl":  `{{ define "demo.name" }}demo{{ end }}`,
+		"templates/NOTES.txt":          "Installed {{ .Chart.Name }}\n",
+		"templates/files/nested/x.tpl": "x={{ .Values.image }}\n",
+		"files/config/nested.conf":     "nested\n",
+	})
+
+	fromDir, err := loader.Load(ctx, dir)
+	if err != nil {
+		t.Fatalf("load directory: %v", err)
+	}
+	archive, err := packager.PackageChart(ctx, loader, packager.Options{ChartPath: dir, Destination: t.TempDir()})
+	if err != nil {
+		t.Fatalf("package: %v", err)
+	}
+	fromArchive, err := loader.Load(ctx, archive)
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	fromFS, err := loader.LoadFS(ctx, os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("load fs: %v", err)
+	}
+
+	for name, ch := range map[string]*chart.Chart{"archive": fromArchive, "fs": fromFS} {
+		if !reflect.DeepEqual(contents(ch), contents(fromDir)) {
+			t.Errorf("%s chart differs from the directory:\n got %+v\nwant %+v", name, contents(ch), contents(fromDir))
+		}
+	}
+
+	if fromDir.BaseDir != dir || fromDir.ArchiveDigest != "" {
+		t.Errorf("directory chart BaseDir %q, ArchiveDigest %q", fromDir.BaseDir, fromDir.ArchiveDigest)
+	}
+	if fromArchive.BaseDir != "" || fromArchive.ArchiveDigest == "" {
+		t.Errorf("archive chart BaseDir %q, ArchiveDigest %q", fromArchive.BaseDir, fromArchive.ArchiveDigest)
+	}
+	if err := fstest.TestFS(fromArchive.FS, chart.MetadataFile, "templates/compose/app.tpl.yaml", "files/config/nested.conf"); err != nil {
+		t.Errorf("archive FS: %v", err)
+	}
+}
+
+// contents strips a chart down to what its source should not change.
+func contents(ch *chart.Chart) chart.Chart {
+	return chart.Chart{
+		Metadata:      ch.Metadata,
+		Digest:        ch.Digest,
+		Values:        ch.Values,
+		ValuesSchema:  ch.ValuesSchema,
+		ComposeTpls:   ch.ComposeTpls,
+		FileTemplates: ch.FileTemplates,
+		HelperTpls:    ch.HelperTpls,
+		NotesTpl:      ch.NotesTpl,
+		StaticFiles:   ch.StaticFiles,
+	}
+}
@@ -3,6 +3,7 @@ package chart
 import (
 	"github.com/google/wire"
 
+	"composepack/internal/infra/cache"
 	"composepack/internal/util/fileloader"
 )
 
@@ -10,6 +11,7 @@ import (
 var ProviderSet = wire.NewSet(
 	fileloader.NewFileSystemLoader,
 	NewFileSystemChartLoader,
+	cache.New,
 	NewCompositeLoader,
 	wire.Bind(new(Loader), new(*CompositeLoader)),
 )
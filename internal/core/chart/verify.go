@@ -0,0 +1,91 @@
+package chart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"composepack/internal/infra/oci"
+)
+
+// provenanceExtension names the detached provenance file stored next to an archive.
+const provenanceExtension = ".prov"
+
+// ArchiveVerifier checks a chart archive before it is loaded. provenance fetches the
+// archive's detached provenance file on demand.
+type ArchiveVerifier interface {
+	VerifyArchive(path string, provenance func() ([]byte, error)) error
+}
+
+// VerifyingLoader loads charts only after an ArchiveVerifier accepted them.
+type VerifyingLoader interface {
+	LoadVerified(ctx context.Context, source string, verifier ArchiveVerifier) (*Chart, error)
+}
+
+// LoadVerified resolves source to an archive (local, URL or OCI), runs verifier against it and
+// its provenance file, and only then loads it. Chart directories cannot be verified.
+func (l *CompositeLoader) LoadVerified(ctx context.Context, source string, verifier ArchiveVerifier) (*Chart, error) {
+	if source == "" {
+		return nil, fmt.Errorf("chart source must be provided")
+	}
+
+	var (
+		path       string
+		provenance func() ([]byte, error)
+		err        error
+	)
+	switch {
+	case oci.IsReference(source):
+		if path, err = l.pullOCI(ctx, source); err != nil {
+			return nil, err
+		}
+		provenance = func() ([]byte, error) {
+			ref, err := oci.ParseReference(source)
+			if err != nil {
+				return nil, err
+			}
+			entry, ok, err := l.cache.Lookup(ref.String() + provenanceExtension)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("%s has no provenance layer", source)
+			}
+			return os.ReadFile(entry.Path)
+		}
+	case isURL(source):
+		if path, err = l.cache.Fetch(ctx, source); err != nil {
+			return nil, err
+		}
+		provenance = func() ([]byte, error) {
+			provPath, err := l.cache.Fetch(ctx, source+provenanceExtension)
+			if err != nil {
+				return nil, fmt.Errorf("fetch provenance file: %w", err)
+			}
+			return os.ReadFile(provPath)
+		}
+	default:
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, fmt.Errorf("chart source %q not found", source)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("chart source %q is a directory; only packaged archives can be verified", source)
+		}
+		path = source
+		provenance = func() ([]byte, error) {
+			data, err := os.ReadFile(source + provenanceExtension)
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("provenance file %s not found", source+provenanceExtension)
+			}
+			return data, err
+		}
+	}
+
+	if err := verifier.VerifyArchive(path, provenance); err != nil {
+		return nil, err
+	}
+	return l.loadArchiveAt(ctx, path, 0)
+}
@@ -0,0 +1,141 @@
+package chart_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"composepack/internal/packager"
+	"composepack/internal/provenance"
+)
+
+// signedChart packages demoChart with a fresh signing key and returns the archive and the key.
+func signedChart(t *testing.T) (string, ed25519.PublicKey) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "signing.key")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "demo")
+	writeFiles(t, dir, demoChart)
+	archive, err := packager.PackageChart(context.Background(), testLoader(t), packager.Options{
+		ChartPath:   dir,
+		Destination: t.TempDir(),
+		SigningKey:  keyPath,
+	})
+	if err != nil {
+		t.Fatalf("package: %v", err)
+	}
+	return archive, pub
+}
+
+func TestLoadVerified(t *testing.T) {
+	archive, pub := signedChart(t)
+	verifier := &provenance.Verifier{Keyring: []ed25519.PublicKey{pub}}
+	ch, err := testLoader(t).LoadVerified(context.Background(), archive, verifier)
+	if err != nil {
+		t.Fatalf("LoadVerified: %v", err)
+	}
+	if ch.Metadata.Name != "demo" || verifier.Result == nil || verifier.Result.KeyID != provenance.KeyID(pub) {
+		t.Errorf("loaded %s, verification result %+v", ch.Metadata.Name, verifier.Result)
+	}
+
+	digest, _ := provenance.FileDigest(archive)
+	checksumOnly := &provenance.Verifier{Checksum: digest}
+	if _, err := testLoader(t).LoadVerified(context.Background(), archive, checksumOnly); err != nil {
+		t.Errorf("LoadVerified with a checksum: %v", err)
+	}
+}
+
+func TestLoadVerifiedRejects(t *testing.T) {
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		prepare  func(t *testing.T, archive string) string
+		checksum string
+		keyring  []ed25519.PublicKey
+		want     string
+	}{
+		"tampered archive": {
+			prepare: func(t *testing.T, archive string) string {
+				f, err := os.OpenFile(archive, os.O_APPEND|os.O_WRONLY, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.Write([]byte("trailing"))
+				f.Close()
+				return archive
+			},
+			want: "archive digest",
+		},
+		"wrong checksum": {
+			checksum: "sha256:0000",
+			want:     "does not match sha256:0000",
+		},
+		"unknown key": {
+			keyring: []ed25519.PublicKey{other},
+			want:    "does not match any key",
+		},
+		"missing provenance": {
+			prepare: func(t *testing.T, archive string) string {
+				os.Remove(archive + provenance.Extension)
+				return archive
+			},
+			want: "not found",
+		},
+		"malformed provenance": {
+			prepare: func(t *testing.T, archive string) string {
+				os.WriteFile(archive+provenance.Extension, []byte("name: demo\n"), 0o644)
+				return archive
+			},
+			want: "has no signature",
+		},
+		"directory": {
+			prepare: func(t *testing.T, _ string) string {
+				dir := t.TempDir()
+				writeFiles(t, dir, demoChart)
+				return dir
+			},
+			want: "only packaged archives can be verified",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			archive, pub := signedChart(t)
+			source := archive
+			if tt.prepare != nil {
+				source = tt.prepare(t, archive)
+			}
+			keyring := tt.keyring
+			if keyring == nil && tt.checksum == "" {
+				keyring = []ed25519.PublicKey{pub}
+			}
+			verifier := &provenance.Verifier{Checksum: tt.checksum, Keyring: keyring}
+			_, err := testLoader(t).LoadVerified(context.Background(), source, verifier)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("LoadVerified error = %v, want %q", err, tt.want)
+			}
+			if name != "directory" && !errors.Is(err, provenance.ErrUnverified) {
+				t.Errorf("error %v does not wrap ErrUnverified", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,393 @@
+package charttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Assertion holds exactly one check. Paths select values in the merged compose document;
+// files are named relative to the release's files/ directory.
+type Assertion struct {
+	Equal          *ValueAssert    `json:"equal,omitempty"`
+	NotEqual       *ValueAssert    `json:"notEqual,omitempty"`
+	Contains       *ContentAssert  `json:"contains,omitempty"`
+	NotContains    *ContentAssert  `json:"notContains,omitempty"`
+	Exists         *PathAssert     `json:"exists,omitempty"`
+	NotExists      *PathAssert     `json:"notExists,omitempty"`
+	MatchRegex     *RegexAssert    `json:"matchRegex,omitempty"`
+	FileContains   *FileAssert     `json:"fileContains,omitempty"`
+	FileMatches    *FileAssert     `json:"fileMatches,omitempty"`
+	FailedTemplate *FailureAssert  `json:"failedTemplate,omitempty"`
+	MatchSnapshot  *SnapshotAssert `json:"matchSnapshot,omitempty"`
+}
+
+// ValueAssert compares the value at Path with Value.
+type ValueAssert struct {
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// ContentAssert looks for Content in the list, string or map at Path.
+type ContentAssert struct {
+	Path    string `json:"path"`
+	Content any    `json:"content"`
+}
+
+// PathAssert checks whether Path selects anything.
+type PathAssert struct {
+	Path string `json:"path"`
+}
+
+// RegexAssert matches the scalar at Path against Pattern.
+type RegexAssert struct {
+	Path    string `json:"path"`
+	Pattern string `json:"pattern"`
+}
+
+// FileAssert checks a rendered file for a substring (Content) or a regular expression (Pattern).
+type FileAssert struct {
+	File    string `json:"file"`
+	Content string `json:"content,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// FailureAssert expects rendering to fail, optionally with a matching error.
+type FailureAssert struct {
+	ErrorMessage string `json:"errorMessage,omitempty"`
+	ErrorPattern string `json:"errorPattern,omitempty"`
+}
+
+// SnapshotAssert compares the value at Path (the whole document when empty), or a rendered
+// File, with the snapshot stored for the test.
+type SnapshotAssert struct {
+	Path string `json:"path,omitempty"`
+	File string `json:"file,omitempty"`
+}
+
+// outcome is what a test's assertions are checked against.
+type outcome struct {
+	doc      any
+	files    map[string][]byte
+	err      error
+	snapshot func(content string) error
+}
+
+func (a Assertion) kind() string {
+	kinds := a.kinds()
+	if len(kinds) == 1 {
+		return kinds[0]
+	}
+	return "assertion"
+}
+
+func (a Assertion) kinds() []string {
+	var kinds []string
+	for name, set := range map[string]bool{
+		"equal":          a.Equal != nil,
+		"notEqual":       a.NotEqual != nil,
+		"contains":       a.Contains != nil,
+		"notContains":    a.NotContains != nil,
+		"exists":         a.Exists != nil,
+		"notExists":      a.NotExists != nil,
+		"matchRegex":     a.MatchRegex != nil,
+		"fileContains":   a.FileContains != nil,
+		"fileMatches":    a.FileMatches != nil,
+		"failedTemplate": a.FailedTemplate != nil,
+		"matchSnapshot":  a.MatchSnapshot != nil,
+	} {
+		if set {
+			kinds = append(kinds, name)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func (a Assertion) validate() error {
+	kinds := a.kinds()
+	switch len(kinds) {
+	case 0:
+		return errors.New("no assertion given")
+	case 1:
+	default:
+		return fmt.Errorf("one assertion per entry, got %s", strings.Join(kinds, ", "))
+	}
+
+	var patterns []string
+	switch {
+	case a.Equal != nil && a.Equal.Path == "", a.NotEqual != nil && a.NotEqual.Path == "",
+		a.Contains != nil && a.Contains.Path == "", a.NotContains != nil && a.NotContains.Path == "",
+		a.Exists != nil && a.Exists.Path == "", a.NotExists != nil && a.NotExists.Path == "",
+		a.MatchRegex != nil && a.MatchRegex.Path == "":
+		return fmt.Errorf("%s: path is required", a.kind())
+	case a.MatchRegex != nil:
+		patterns = append(patterns, a.MatchRegex.Pattern)
+	case a.FileContains != nil && (a.FileContains.File == "" || a.FileContains.Content == ""):
+		return errors.New("fileContains: file and content are required")
+	case a.FileMatches != nil:
+		if a.FileMatches.File == "" || a.FileMatches.Pattern == "" {
+			return errors.New("fileMatches: file and pattern are required")
+		}
+		patterns = append(patterns, a.FileMatches.Pattern)
+	case a.FailedTemplate != nil && a.FailedTemplate.ErrorPattern != "":
+		patterns = append(patterns, a.FailedTemplate.ErrorPattern)
+	case a.MatchSnapshot != nil && a.MatchSnapshot.Path != "" && a.MatchSnapshot.File != "":
+		return errors.New("matchSnapshot: set path or file, not both")
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s: %w", a.kind(), err)
+		}
+	}
+	if path := pathOf(a); path != nil {
+		if _, err := parsePath(*path); err != nil {
+			return fmt.Errorf("%s: %w", a.kind(), err)
+		}
+	}
+	return nil
+}
+
+// pathOf returns the document path an assertion inspects, if any.
+func pathOf(a Assertion) *string {
+	switch {
+	case a.Equal != nil:
+		return &a.Equal.Path
+	case a.NotEqual != nil:
+		return &a.NotEqual.Path
+	case a.Contains != nil:
+		return &a.Contains.Path
+	case a.NotContains != nil:
+		return &a.NotContains.Path
+	case a.Exists != nil:
+		return &a.Exists.Path
+	case a.NotExists != nil:
+		return &a.NotExists.Path
+	case a.MatchRegex != nil:
+		return &a.MatchRegex.Path
+	case a.MatchSnapshot != nil && a.MatchSnapshot.Path != "":
+		return &a.MatchSnapshot.Path
+	}
+	return nil
+}
+
+// check returns nil when the assertion holds and a description of the mismatch otherwise.
+// Only failedTemplate may be checked against a failed render.
+func (a Assertion) check(out *outcome) error {
+	if a.FailedTemplate != nil {
+		return checkFailure(a.FailedTemplate, out.err)
+	}
+
+	switch {
+	case a.Equal != nil:
+		actual, err := selectOne(out.doc, a.Equal.Path)
+		if err != nil {
+			return err
+		}
+		if !sameValue(actual, a.Equal.Value) {
+			return fmt.Errorf("%s: expected %s, got %s", a.Equal.Path, show(a.Equal.Value), show(actual))
+		}
+	case a.NotEqual != nil:
+		actual, err := selectOne(out.doc, a.NotEqual.Path)
+		if err != nil {
+			return err
+		}
+		if sameValue(actual, a.NotEqual.Value) {
+			return fmt.Errorf("%s: expected anything but %s", a.NotEqual.Path, show(actual))
+		}
+	case a.Contains != nil:
+		actual, err := selectOne(out.doc, a.Contains.Path)
+		if err != nil {
+			return err
+		}
+		if !containsValue(actual, a.Contains.Content) {
+			return fmt.Errorf("%s: %s does not contain %s", a.Contains.Path, show(actual), show(a.Contains.Content))
+		}
+	case a.NotContains != nil:
+		actual, err := selectOne(out.doc, a.NotContains.Path)
+		if err != nil {
+			return err
+		}
+		if containsValue(actual, a.NotContains.Content) {
+			return fmt.Errorf("%s: %s contains %s", a.NotContains.Path, show(actual), show(a.NotContains.Content))
+		}
+	case a.Exists != nil:
+		found, err := lookup(out.doc, a.Exists.Path)
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return fmt.Errorf("%s: not found", a.Exists.Path)
+		}
+	case a.NotExists != nil:
+		found, err := lookup(out.doc, a.NotExists.Path)
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			return fmt.Errorf("%s: expected nothing, found %s", a.NotExists.Path, show(unwrap(found)))
+		}
+	case a.MatchRegex != nil:
+		actual, err := selectOne(out.doc, a.MatchRegex.Path)
+		if err != nil {
+			return err
+		}
+		text, ok := scalarString(actual)
+		if !ok {
+			return fmt.Errorf("%s: %s is not a scalar", a.MatchRegex.Path, show(actual))
+		}
+		if !regexp.MustCompile(a.MatchRegex.Pattern).MatchString(text) {
+			return fmt.Errorf("%s: %q does not match /%s/", a.MatchRegex.Path, text, a.MatchRegex.Pattern)
+		}
+	case a.FileContains != nil:
+		data, err := renderedFile(out.files, a.FileContains.File)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(data), a.FileContains.Content) {
+			return fmt.Errorf("files/%s does not contain %q", a.FileContains.File, a.FileContains.Content)
+		}
+	case a.FileMatches != nil:
+		data, err := renderedFile(out.files, a.FileMatches.File)
+		if err != nil {
+			return err
+		}
+		if !regexp.MustCompile(a.FileMatches.Pattern).Match(data) {
+			return fmt.Errorf("files/%s does not match /%s/", a.FileMatches.File, a.FileMatches.Pattern)
+		}
+	case a.MatchSnapshot != nil:
+		content, err := snapshotContent(a.MatchSnapshot, out)
+		if err != nil {
+			return err
+		}
+		return out.snapshot(content)
+	}
+	return nil
+}
+
+func checkFailure(want *FailureAssert, err error) error {
+	if err == nil {
+		return errors.New("expected rendering to fail, but it succeeded")
+	}
+	msg := err.Error()
+	if want.ErrorMessage != "" && !strings.Contains(msg, want.ErrorMessage) {
+		return fmt.Errorf("render error %q does not contain %q", msg, want.ErrorMessage)
+	}
+	if want.ErrorPattern != "" && !regexp.MustCompile(want.ErrorPattern).MatchString(msg) {
+		return fmt.Errorf("render error %q does not match /%s/", msg, want.ErrorPattern)
+	}
+	return nil
+}
+
+func snapshotContent(want *SnapshotAssert, out *outcome) (string, error) {
+	if want.File != "" {
+		data, err := renderedFile(out.files, want.File)
+		return string(data), err
+	}
+	value := out.doc
+	if want.Path != "" {
+		var err error
+		if value, err = selectOne(out.doc, want.Path); err != nil {
+			return "", err
+		}
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("serialize snapshot: %w", err)
+	}
+	return string(data), nil
+}
+
+// selectOne returns the single value path selects, or the list of values for wildcard paths.
+func selectOne(doc any, path string) (any, error) {
+	found, err := lookup(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%s: not found", path)
+	}
+	return unwrap(found), nil
+}
+
+func unwrap(found []any) any {
+	if len(found) == 1 {
+		return found[0]
+	}
+	return found
+}
+
+func renderedFile(files map[string][]byte, name string) ([]byte, error) {
+	data, ok := files[strings.TrimPrefix(name, "files/")]
+	if !ok {
+		return nil, fmt.Errorf("files/%s was not rendered", strings.TrimPrefix(name, "files/"))
+	}
+	return data, nil
+}
+
+// sameValue compares YAML-decoded values after normalizing them through JSON, so 8080 written
+// in a test equals 8080 decoded from the compose file.
+func sameValue(a, b any) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v any) any {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func containsValue(haystack, needle any) bool {
+	switch typed := haystack.(type) {
+	case []any:
+		for _, item := range typed {
+			if sameValue(item, needle) {
+				return true
+			}
+		}
+	case string:
+		s, ok := needle.(string)
+		return ok && strings.Contains(typed, s)
+	case map[string]any:
+		want, ok := normalize(needle).(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range want {
+			got, present := typed[key]
+			if !present || !sameValue(got, value) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func scalarString(v any) (string, bool) {
+	switch v.(type) {
+	case map[string]any, []any, nil:
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+func show(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
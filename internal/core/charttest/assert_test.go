@@ -0,0 +1,165 @@
+package charttest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+const assertDoc = `
+services:
+  web:
+    image: nginx:1.27
+    ports: [8080, 8443]
+    environment:
+      MODE: prod
+      LEVEL: info
+    command: ["nginx", "-g", "daemon off;"]
+`
+
+func parseAssertion(t *testing.T, src string) Assertion {
+	t.Helper()
+	var a Assertion
+	if err := yaml.UnmarshalStrict([]byte(src), &a); err != nil {
+		t.Fatalf("parse %q: %v", src, err)
+	}
+	if err := a.validate(); err != nil {
+		t.Fatalf("validate %q: %v", src, err)
+	}
+	return a
+}
+
+func TestAssertionCheck(t *testing.T) {
+	var doc any
+	if err := yaml.Unmarshal([]byte(assertDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{"nginx/nginx.conf": []byte("worker_processes 4;\n")}
+
+	tests := []struct {
+		assertion string
+		// fail is a substring of the expected failure; empty means the assertion holds.
+		fail string
+	}{
+		{"equal: {path: services.web.image, value: nginx:1.27}", ""},
+		{"equal: {path: 'services.web.ports[0]', value: 8080}", ""},
+		{"equal: {path: services.web.environment, value: {LEVEL: info, MODE: prod}}", ""},
+		{"equal: {path: services.web.image, value: nginx}", `expected "nginx", got "nginx:1.27"`},
+		{"equal: {path: services.db.image, value: x}", "services.db.image: not found"},
+		{"notEqual: {path: services.web.image, value: nginx}", ""},
+		{"notEqual: {path: 'services.web.ports[1]', value: 8443}", "expected anything but 8443"},
+		{"contains: {path: services.web.ports, content: 8443}", ""},
+		{"contains: {path: services.web.image, content: nginx}", ""},
+		{"contains: {path: services.web.environment, content: {MODE: prod}}", ""},
+		{"contains: {path: services.web.ports, content: 9000}", "does not contain 9000"},
+		{"contains: {path: services.web.environment, content: {MODE: dev}}", "does not contain"},
+		{"notContains: {path: services.web.command, content: debug}", ""},
+		{"notContains: {path: services.web.command, content: nginx}", `contains "nginx"`},
+		{"exists: {path: services.web.environment.MODE}", ""},
+		{"exists: {path: services.web.healthcheck}", "not found"},
+		{"notExists: {path: services.web.healthcheck}", ""},
+		{"notExists: {path: services.*.image}", `found "nginx:1.27"`},
+		{"matchRegex: {path: services.web.image, pattern: '^nginx:1\\.2[0-9]$'}", ""},
+		{"matchRegex: {path: 'services.web.ports[0]', pattern: '^80'}", ""},
+		{"matchRegex: {path: services.web.image, pattern: '^redis'}", "does not match"},
+		{"matchRegex: {path: services.web.ports, pattern: x}", "is not a scalar"},
+		{"fileContains: {file: nginx/nginx.conf, content: worker_processes}", ""},
+		{"fileContains: {file: files/nginx/nginx.conf, content: worker_processes}", ""},
+		{"fileContains: {file: nginx/nginx.conf, content: events}", "does not contain"},
+		{"fileContains: {file: missing.conf, content: x}", "files/missing.conf was not rendered"},
+		{"fileMatches: {file: nginx/nginx.conf, pattern: 'worker_processes [0-9]+;'}", ""},
+		{"fileMatches: {file: nginx/nginx.conf, pattern: 'auto'}", "does not match"},
+		{"failedTemplate: {}", "expected rendering to fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			err := parseAssertion(t, tt.assertion).check(&outcome{doc: doc, files: files})
+			switch {
+			case tt.fail == "" && err != nil:
+				t.Errorf("check failed: %v", err)
+			case tt.fail != "" && (err == nil || !strings.Contains(err.Error(), tt.fail)):
+				t.Errorf("check error = %v, want it to mention %q", err, tt.fail)
+			}
+		})
+	}
+}
+
+func TestFailedTemplate(t *testing.T) {
+	renderErr := errors.New(`template: compose/app.tpl.yaml:3: required value "image" is missing`)
+	tests := []struct {
+		assertion string
+		fail      string
+	}{
+		{"failedTemplate: {}", ""},
+		{"failedTemplate: {errorMessage: is missing}", ""},
+		{"failedTemplate: {errorPattern: 'required value \"[a-z]+\"'}", ""},
+		{"failedTemplate: {errorMessage: not found}", `does not contain "not found"`},
+		{"failedTemplate: {errorPattern: '^values'}", "does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			err := parseAssertion(t, tt.assertion).check(&outcome{err: renderErr})
+			switch {
+			case tt.fail == "" && err != nil:
+				t.Errorf("check failed: %v", err)
+			case tt.fail != "" && (err == nil || !strings.Contains(err.Error(), tt.fail)):
+				t.Errorf("check error = %v, want it to mention %q", err, tt.fail)
+			}
+		})
+	}
+}
+
+func TestMatchSnapshotContent(t *testing.T) {
+	var doc any
+	if err := yaml.Unmarshal([]byte(assertDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	out := &outcome{doc: doc, files: map[string][]byte{"app.env": []byte("A=1\n")}}
+	var got []string
+	out.snapshot = func(content string) error {
+		got = append(got, content)
+		return nil
+	}
+	for _, src := range []string{
+		"matchSnapshot: {path: services.web.environment}",
+		"matchSnapshot: {file: app.env}",
+	} {
+		if err := parseAssertion(t, src).check(out); err != nil {
+			t.Fatalf("%s: %v", src, err)
+		}
+	}
+	want := []string{"LEVEL: info\nMODE: prod\n", "A=1\n"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("snapshot contents = %q, want %q", got, want)
+	}
+}
+
+func TestAssertionValidate(t *testing.T) {
+	tests := []struct {
+		assertion string
+		want      string
+	}{
+		{"{}", "no assertion given"},
+		{"{exists: {path: a}, notExists: {path: b}}", "one assertion per entry, got exists, notExists"},
+		{"equal: {value: 1}", "equal: path is required"},
+		{"matchRegex: {path: a, pattern: '('}", "matchRegex: error parsing regexp"},
+		{"fileContains: {file: a}", "file and content are required"},
+		{"fileMatches: {file: a}", "file and pattern are required"},
+		{"failedTemplate: {errorPattern: '['}", "failedTemplate: error parsing regexp"},
+		{"matchSnapshot: {path: a, file: b}", "set path or file, not both"},
+		{"exists: {path: 'a[x]'}", "exists: path \"a[x]\": invalid index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.assertion, func(t *testing.T) {
+			var a Assertion
+			if err := yaml.UnmarshalStrict([]byte(tt.assertion), &a); err != nil {
+				t.Fatal(err)
+			}
+			if err := a.validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("validate() = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
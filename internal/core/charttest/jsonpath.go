@@ -0,0 +1,99 @@
+package charttest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is one step of a path: a map key, a list index or a wildcard.
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// parsePath understands the JSONPath subset tests need: an optional leading `$`, dotted keys,
+// `[n]` indexes, `['key']` / `["key"]` for keys containing dots, and `*` / `[*]` wildcards.
+func parsePath(path string) ([]segment, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []segment
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: unterminated [", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				segments = append(segments, segment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, segment{key: inner[1 : len(inner)-1]})
+			default:
+				n, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q: invalid index [%s]", path, inner)
+				}
+				segments = append(segments, segment{index: n, isIndex: true})
+			}
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			key := rest[:end]
+			rest = rest[end:]
+			if key == "*" {
+				segments = append(segments, segment{wildcard: true})
+			} else {
+				segments = append(segments, segment{key: key})
+			}
+		}
+	}
+	return segments, nil
+}
+
+// lookup returns every value path selects in doc; an empty result means nothing matched.
+func lookup(doc any, path string) ([]any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []any{doc}
+	for _, seg := range segments {
+		var next []any
+		for _, node := range nodes {
+			switch typed := node.(type) {
+			case map[string]any:
+				if seg.wildcard {
+					for _, key := range sortedKeys(typed) {
+						next = append(next, typed[key])
+					}
+				} else if value, ok := typed[seg.key]; ok && !seg.isIndex {
+					next = append(next, value)
+				}
+			case []any:
+				switch {
+				case seg.wildcard:
+					next = append(next, typed...)
+				case seg.isIndex:
+					i := seg.index
+					if i < 0 {
+						i += len(typed)
+					}
+					if i >= 0 && i < len(typed) {
+						next = append(next, typed[i])
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
@@ -0,0 +1,92 @@
+package charttest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want []segment
+	}{
+		{"services.web.image", []segment{{key: "services"}, {key: "web"}, {key: "image"}}},
+		{"$.services", []segment{{key: "services"}}},
+		{"$", nil},
+		{"ports[0]", []segment{{key: "ports"}, {index: 0, isIndex: true}}},
+		{"ports[-1]", []segment{{key: "ports"}, {index: -1, isIndex: true}}},
+		{"labels['app.io/name']", []segment{{key: "labels"}, {key: "app.io/name"}}},
+		{`labels["a.b"].x`, []segment{{key: "labels"}, {key: "a.b"}, {key: "x"}}},
+		{"services.*.image", []segment{{key: "services"}, {wildcard: true}, {key: "image"}}},
+		{"ports[*]", []segment{{key: "ports"}, {wildcard: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parsePath(tt.path)
+			if err != nil {
+				t.Fatalf("parsePath: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsePath(%q) = %+v, want %+v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	for path, want := range map[string]string{
+		"ports[0":   "unterminated [",
+		"ports[x]":  "invalid index",
+		"ports['a]": "invalid index",
+	} {
+		if _, err := parsePath(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parsePath(%q) error = %v, want %q", path, err, want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var doc any
+	if err := yaml.Unmarshal([]byte(`
+services:
+  web:
+    image: nginx
+    ports: ["80:80", "443:443"]
+    labels:
+      app.io/name: web
+  db:
+    image: postgres
+`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []any
+	}{
+		{"services.web.image", []any{"nginx"}},
+		{"services.web.ports[1]", []any{"443:443"}},
+		{"services.web.ports[-1]", []any{"443:443"}},
+		{"services.web.ports[*]", []any{"80:80", "443:443"}},
+		{"services.web.labels['app.io/name']", []any{"web"}},
+		// wildcards over maps visit keys in sorted order
+		{"services.*.image", []any{"postgres", "nginx"}},
+		{"services.web.ports[2]", nil},
+		{"services.web.ports.x", nil},
+		{"services[0]", nil},
+		{"services.cache.image", nil},
+		{"services.web.image.x", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := lookup(doc, tt.path)
+			if err != nil {
+				t.Fatalf("lookup: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lookup(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
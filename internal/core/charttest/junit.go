@@ -0,0 +1,80 @@
+package charttest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	File     string      `xml:"file,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes result as JUnit XML, one <testsuite> per suite file.
+func WriteJUnit(w io.Writer, result *Result) error {
+	total, failed := result.Counts()
+	doc := junitSuites{Name: result.Chart, Tests: total, Failures: failed, Time: seconds(result.Duration)}
+	for _, suite := range result.Suites {
+		js := junitSuite{
+			Name:     suite.Name,
+			File:     suite.Path,
+			Tests:    len(suite.Tests),
+			Failures: suite.Failed(),
+			Time:     seconds(suite.Duration),
+		}
+		for _, test := range suite.Tests {
+			jc := junitCase{Name: test.Name, ClassName: result.Chart + "." + suite.Name, Time: seconds(test.Duration)}
+			if !test.Passed() {
+				jc.Failure = &junitFailure{
+					Message: fmt.Sprintf("%d assertion(s) failed", len(test.Failures)),
+					Text:    strings.Join(test.Failures, "\n"),
+				}
+			}
+			js.Cases = append(js.Cases, jc)
+		}
+		doc.Suites = append(doc.Suites, js)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encode junit report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,64 @@
+package charttest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	result := &Result{
+		Chart:    "demo",
+		Duration: 1500 * time.Millisecond,
+		Suites: []SuiteResult{
+			{
+				Name:     "web",
+				Path:     "tests/web_test.yaml",
+				Duration: time.Second,
+				Tests: []TestResult{
+					{Name: "renders nginx", Duration: 250 * time.Millisecond},
+					{Name: "sets <ports>", Failures: []string{"equal #1: a & b", "exists #2: not found"}},
+				},
+			},
+			{Name: "empty", Path: "tests/empty_test.yaml", Tests: []TestResult{}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, result); err != nil {
+		t.Fatalf("WriteJUnit: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Errorf("report does not start with the XML header:\n%s", buf.String())
+	}
+
+	var got junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, buf.String())
+	}
+	if got.Name != "demo" || got.Tests != 2 || got.Failures != 1 || got.Time != "1.500" {
+		t.Errorf("testsuites = %+v", got)
+	}
+	if len(got.Suites) != 2 {
+		t.Fatalf("got %d testsuite elements, want 2", len(got.Suites))
+	}
+	web := got.Suites[0]
+	if web.Name != "web" || web.File != "tests/web_test.yaml" || web.Tests != 2 || web.Failures != 1 || web.Time != "1.000" {
+		t.Errorf("testsuite = %+v", web)
+	}
+	pass, fail := web.Cases[0], web.Cases[1]
+	if pass.Name != "renders nginx" || pass.ClassName != "demo.web" || pass.Time != "0.250" || pass.Failure != nil {
+		t.Errorf("passing testcase = %+v", pass)
+	}
+	if fail.Name != "sets <ports>" || fail.Failure == nil {
+		t.Fatalf("failing testcase = %+v", fail)
+	}
+	if fail.Failure.Message != "2 assertion(s) failed" || fail.Failure.Text != "equal #1: a & b\nexists #2: not found" {
+		t.Errorf("failure = %+v", fail.Failure)
+	}
+	if empty := got.Suites[1]; empty.Tests != 0 || len(empty.Cases) != 0 {
+		t.Errorf("empty testsuite = %+v", empty)
+	}
+}
@@ -0,0 +1,232 @@
+package charttest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/values"
+)
+
+// RenderInput is what one test renders the chart with. Values are layered over the
+// chart's values.yaml by the RenderFunc.
+type RenderInput struct {
+	Release string
+	Values  map[string]any
+	Env     map[string]string
+}
+
+// Rendered is the merged compose file and the files/ tree of one render.
+type Rendered struct {
+	ComposeYAML []byte
+	Files       map[string][]byte
+}
+
+// RenderFunc renders the chart under test.
+type RenderFunc func(ctx context.Context, in RenderInput) (*Rendered, error)
+
+// Options tune a test run.
+type Options struct {
+	// UpdateSnapshots rewrites mismatching snapshots and, for passing suites, drops unused ones.
+	UpdateSnapshots bool
+}
+
+// Result summarizes a run over every suite of a chart.
+type Result struct {
+	Chart            string        `json:"chart"`
+	Suites           []SuiteResult `json:"suites"`
+	SnapshotsWritten int           `json:"snapshotsWritten"`
+	SnapshotsUpdated int           `json:"snapshotsUpdated"`
+	Duration         time.Duration `json:"duration"`
+}
+
+// SuiteResult holds the outcome of every test in one suite file.
+type SuiteResult struct {
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Tests    []TestResult  `json:"tests"`
+	Duration time.Duration `json:"duration"`
+}
+
+// TestResult lists a test's failed assertions; it passed when there are none.
+type TestResult struct {
+	Name     string        `json:"name"`
+	Failures []string      `json:"failures,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Passed reports whether every assertion held.
+func (t TestResult) Passed() bool { return len(t.Failures) == 0 }
+
+// Failed counts the suite's failed tests.
+func (s SuiteResult) Failed() int {
+	n := 0
+	for _, test := range s.Tests {
+		if !test.Passed() {
+			n++
+		}
+	}
+	return n
+}
+
+// Counts returns the number of tests run and failed.
+func (r *Result) Counts() (total, failed int) {
+	for _, suite := range r.Suites {
+		total += len(suite.Tests)
+		failed += suite.Failed()
+	}
+	return total, failed
+}
+
+// Run executes every suite under <chartDir>/tests against render.
+func Run(ctx context.Context, chartDir, chartName string, render RenderFunc, opts Options) (*Result, error) {
+	suites, err := LoadSuites(chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	result := &Result{Chart: chartName, Suites: []SuiteResult{}}
+	for _, suite := range suites {
+		snaps, err := openSnapshots(chartDir, suite, opts.UpdateSnapshots)
+		if err != nil {
+			return nil, err
+		}
+		suiteStart := time.Now()
+		sr := SuiteResult{Name: suite.Name, Path: suite.Path, Tests: []TestResult{}}
+		for _, test := range suite.Tests {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			sr.Tests = append(sr.Tests, runTest(ctx, suite, test, render, snaps))
+		}
+		sr.Duration = time.Since(suiteStart)
+		if err := snaps.save(ctx, opts.UpdateSnapshots && sr.Failed() == 0); err != nil {
+			return nil, err
+		}
+		result.SnapshotsWritten += snaps.written
+		result.SnapshotsUpdated += snaps.updated
+		result.Suites = append(result.Suites, sr)
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+func runTest(ctx context.Context, suite *Suite, test Test, render RenderFunc, snaps *snapshotFile) TestResult {
+	start := time.Now()
+	tr := TestResult{Name: test.It}
+	fail := func(format string, args ...any) TestResult {
+		tr.Failures = append(tr.Failures, fmt.Sprintf(format, args...))
+		tr.Duration = time.Since(start)
+		return tr
+	}
+
+	vals, err := testValues(suite, test)
+	if err != nil {
+		return fail("%v", err)
+	}
+	env := map[string]string{}
+	for k, v := range suite.Env {
+		env[k] = v
+	}
+	for k, v := range test.Env {
+		env[k] = v
+	}
+
+	out := &outcome{}
+	rendered, renderErr := render(ctx, RenderInput{Release: suite.Release, Values: vals, Env: env})
+	out.err = renderErr
+	if renderErr == nil {
+		out.files = rendered.Files
+		if err := yaml.Unmarshal(rendered.ComposeYAML, &out.doc); err != nil {
+			return fail("parse rendered compose: %v", err)
+		}
+	}
+
+	snapshot, reported := 0, false
+	out.snapshot = func(content string) error {
+		snapshot++
+		return snaps.match(fmt.Sprintf("%s %d", test.It, snapshot), content)
+	}
+	for i, assertion := range test.Asserts {
+		if renderErr != nil && assertion.FailedTemplate == nil {
+			// report an unexpected render failure once, not once per assertion
+			if !reported {
+				tr.Failures = append(tr.Failures, fmt.Sprintf("render failed: %v", renderErr))
+				reported = true
+			}
+			continue
+		}
+		if err := assertion.check(out); err != nil {
+			tr.Failures = append(tr.Failures, fmt.Sprintf("%s #%d: %v", assertion.kind(), i+1, err))
+		}
+	}
+	tr.Duration = time.Since(start)
+	return tr
+}
+
+// testValues layers the suite's values files and set entries, then the test's.
+func testValues(suite *Suite, test Test) (map[string]any, error) {
+	result := map[string]any{}
+	for _, layer := range []struct {
+		files []string
+		set   map[string]any
+	}{{suite.Values, suite.Set}, {test.Values, test.Set}} {
+		for _, name := range layer.files {
+			path := name
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(suite.dir, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read values file: %w", err)
+			}
+			var vals map[string]any
+			if err := yaml.Unmarshal(data, &vals); err != nil {
+				return nil, fmt.Errorf("parse values file %s: %w", name, err)
+			}
+			if result, err = values.Merge(result, vals); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if result, err = values.Merge(result, expandSet(layer.set)); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// expandSet turns dotted keys into nested maps, keeping the YAML-typed values.
+func expandSet(set map[string]any) map[string]any {
+	out := map[string]any{}
+	for _, key := range sortedKeys(set) {
+		parts := strings.Split(key, ".")
+		node := out
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := node[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				node[part] = next
+			}
+			node = next
+		}
+		node[parts[len(parts)-1]] = set[key]
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+package charttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/diff"
+	"composepack/internal/util/fsutil"
+)
+
+// snapshotFile holds a suite's snapshots, keyed by "<test> <n>", in
+// tests/__snapshot__/<suite file>.snap.
+type snapshotFile struct {
+	path    string
+	entries map[string]string
+	used    map[string]bool
+	update  bool
+	dirty   bool
+
+	written int
+	updated int
+}
+
+func openSnapshots(chartDir string, suite *Suite, update bool) (*snapshotFile, error) {
+	rel := strings.TrimPrefix(suite.Path, TestsDir+"/")
+	path := filepath.Join(chartDir, TestsDir, SnapshotDir, filepath.FromSlash(rel)+".snap")
+	snaps := &snapshotFile{path: path, entries: map[string]string{}, used: map[string]bool{}, update: update}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return snaps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read snapshots: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &snaps.entries); err != nil {
+		return nil, fmt.Errorf("parse snapshots %s: %w", path, err)
+	}
+	if snaps.entries == nil {
+		snaps.entries = map[string]string{}
+	}
+	return snaps, nil
+}
+
+// match compares content with the stored snapshot. Missing snapshots are recorded; in update
+// mode mismatches overwrite the stored snapshot instead of failing.
+func (s *snapshotFile) match(key, content string) error {
+	s.used[key] = true
+	stored, ok := s.entries[key]
+	switch {
+	case ok && stored == content:
+		return nil
+	case !ok:
+		s.written++
+	case s.update:
+		s.updated++
+	default:
+		return fmt.Errorf("snapshot %q does not match (run with --update-snapshots to accept):\n%s",
+			key, diff.Unified("snapshot", "rendered", []byte(stored), []byte(content), diff.DefaultContext))
+	}
+	s.entries[key] = content
+	s.dirty = true
+	return nil
+}
+
+// save writes the file if anything changed. With prune (update mode on a fully passing
+// suite) snapshots no test used are dropped; a failing test may simply not have reached its
+// snapshot assertions.
+func (s *snapshotFile) save(ctx context.Context, prune bool) error {
+	if prune {
+		for key := range s.entries {
+			if !s.used[key] {
+				delete(s.entries, key)
+				s.dirty = true
+			}
+		}
+	}
+	if !s.dirty {
+		return nil
+	}
+	if len(s.entries) == 0 {
+		if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove snapshots: %w", err)
+		}
+		return nil
+	}
+	data, err := yaml.Marshal(s.entries)
+	if err != nil {
+		return fmt.Errorf("serialize snapshots: %w", err)
+	}
+	if err := fsutil.EnsureDir(filepath.Dir(s.path)); err != nil {
+		return fmt.Errorf("create snapshot directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(ctx, s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write snapshots: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package charttest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+// renderImage renders a compose file whose web image comes from the `image` value.
+func renderImage(_ context.Context, in RenderInput) (*Rendered, error) {
+	compose := fmt.Sprintf("services:\n  web:\n    image: %v\n", in.Values["image"])
+	return &Rendered{ComposeYAML: []byte(compose), Files: map[string][]byte{}}, nil
+}
+
+func writeChartFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readSnapshots(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "tests", "__snapshot__", "web_test.yaml.snap"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries map[string]string
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func snapshotSuite(tests ...string) string {
+	var b strings.Builder
+	b.WriteString("suite: web\ntests:\n")
+	for _, test := range tests {
+		fmt.Fprintf(&b, "  - it: %s\n    set: {image: %s}\n    asserts:\n      - matchSnapshot: {path: services.web}\n", test, test)
+	}
+	return b.String()
+}
+
+func TestSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	run := func(update bool) *Result {
+		t.Helper()
+		result, err := Run(ctx, dir, "demo", renderImage, Options{UpdateSnapshots: update})
+		if err != nil {
+			t.Fatalf("Run: %v", err)
+		}
+		return result
+	}
+	failures := func(result *Result) string {
+		var out []string
+		for _, test := range result.Suites[0].Tests {
+			out = append(out, test.Failures...)
+		}
+		return strings.Join(out, "\n")
+	}
+
+	// first run writes every snapshot
+	writeChartFile(t, dir, "tests/web_test.yaml", snapshotSuite("nginx", "redis"))
+	result := run(false)
+	if got := failures(result); got != "" || result.SnapshotsWritten != 2 {
+		t.Fatalf("first run wrote %d snapshots, failures: %s", result.SnapshotsWritten, got)
+	}
+	want := map[string]string{"nginx 1": "image: nginx\n", "redis 1": "image: redis\n"}
+	if got := readSnapshots(t, dir); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("snapshots = %v, want %v", got, want)
+	}
+
+	// matching snapshots pass without touching the file
+	if result := run(false); failures(result) != "" || result.SnapshotsWritten+result.SnapshotsUpdated != 0 {
+		t.Fatalf("second run: written %d, updated %d, failures: %s", result.SnapshotsWritten, result.SnapshotsUpdated, failures(result))
+	}
+
+	// a changed render fails and shows a diff until snapshots are updated
+	writeChartFile(t, dir, "tests/web_test.yaml", strings.Replace(snapshotSuite("nginx", "redis"), "{image: redis}", "{image: redis:7}", 1))
+	if got := failures(run(false)); !strings.Contains(got, `snapshot "redis 1" does not match`) || !strings.Contains(got, "+image: redis:7") {
+		t.Fatalf("mismatch failures = %q", got)
+	}
+	if got := readSnapshots(t, dir)["redis 1"]; got != "image: redis\n" {
+		t.Fatalf("a failing run changed the snapshot to %q", got)
+	}
+	if result := run(true); failures(result) != "" || result.SnapshotsUpdated != 1 {
+		t.Fatalf("update run: updated %d, failures: %s", result.SnapshotsUpdated, failures(result))
+	}
+	if got := readSnapshots(t, dir)["redis 1"]; got != "image: redis:7\n" {
+		t.Fatalf("updated snapshot = %q", got)
+	}
+
+	// snapshots of removed tests survive normal runs and are pruned on update
+	writeChartFile(t, dir, "tests/web_test.yaml", snapshotSuite("nginx"))
+	run(false)
+	if _, ok := readSnapshots(t, dir)["redis 1"]; !ok {
+		t.Fatal("a run without --update-snapshots pruned a snapshot")
+	}
+	run(true)
+	if got := readSnapshots(t, dir); len(got) != 1 || got["nginx 1"] == "" {
+		t.Fatalf("pruned snapshots = %v", got)
+	}
+
+	// pruning every snapshot removes the file
+	writeChartFile(t, dir, "tests/web_test.yaml", "suite: web\ntests:\n  - it: plain\n    asserts:\n      - exists: {path: services.web}\n")
+	run(true)
+	if got := readSnapshots(t, dir); got != nil {
+		t.Fatalf("snapshot file still holds %v", got)
+	}
+}
+
+func TestSnapshotsNotPrunedWhenSuiteFails(t *testing.T) {
+	dir := t.TempDir()
+	writeChartFile(t, dir, "tests/__snapshot__/web_test.yaml.snap", "old 1: |\n  image: x\n")
+	writeChartFile(t, dir, "tests/web_test.yaml",
+		"suite: web\ntests:\n  - it: broken\n    asserts:\n      - equal: {path: services.web.image, value: other}\n")
+	result, err := Run(context.Background(), dir, "demo", renderImage, Options{UpdateSnapshots: true})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if _, failed := result.Counts(); failed != 1 {
+		t.Fatalf("failed = %d, want 1", failed)
+	}
+	if _, ok := readSnapshots(t, dir)["old 1"]; !ok {
+		t.Error("a failing suite pruned its snapshots")
+	}
+}
@@ -0,0 +1,124 @@
+package charttest
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// TestsDir holds a chart's test suites; SnapshotDir (inside it) holds their snapshots.
+const (
+	TestsDir    = "tests"
+	SnapshotDir = "__snapshot__"
+)
+
+// DefaultRelease is the release name tests render with unless the suite sets one.
+const DefaultRelease = "release-name"
+
+// Suite is one `*_test.yaml` file under tests/; other files there (such as values files) are
+// left alone.
+type Suite struct {
+	Name string `json:"suite"`
+	// Release overrides DefaultRelease.
+	Release string `json:"release,omitempty"`
+	// Values are values files, relative to the suite file, applied to every test.
+	Values []string `json:"values,omitempty"`
+	// Set maps dotted value paths to typed values, applied after Values.
+	Set   map[string]any    `json:"set,omitempty"`
+	Env   map[string]string `json:"env,omitempty"`
+	Tests []Test            `json:"tests"`
+
+	// Path is the suite file, relative to the chart directory.
+	Path string `json:"-"`
+	dir  string
+}
+
+// Test renders the chart once and checks every assertion against the result. Its values
+// and env are layered over the suite's.
+type Test struct {
+	It      string            `json:"it"`
+	Values  []string          `json:"values,omitempty"`
+	Set     map[string]any    `json:"set,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Asserts []Assertion       `json:"asserts"`
+}
+
+// LoadSuites reads every *_test.yaml suite under <chartDir>/tests, sorted by path.
+func LoadSuites(chartDir string) ([]*Suite, error) {
+	root := filepath.Join(chartDir, TestsDir)
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == SnapshotDir {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && (strings.HasSuffix(d.Name(), "_test.yaml") || strings.HasSuffix(d.Name(), "_test.yml")) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("chart has no %s/ directory", TestsDir)
+		}
+		return nil, fmt.Errorf("find test suites: %w", err)
+	}
+	sort.Strings(paths)
+
+	suites := make([]*Suite, 0, len(paths))
+	for _, path := range paths {
+		suite, err := loadSuite(path)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(chartDir, path)
+		if err != nil {
+			return nil, err
+		}
+		suite.Path = filepath.ToSlash(rel)
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func loadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read test suite: %w", err)
+	}
+	var suite Suite
+	if err := yaml.UnmarshalStrict(data, &suite); err != nil {
+		return nil, fmt.Errorf("parse test suite %s: %w", path, err)
+	}
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if suite.Release == "" {
+		suite.Release = DefaultRelease
+	}
+	seen := map[string]bool{}
+	for i, test := range suite.Tests {
+		if test.It == "" {
+			return nil, fmt.Errorf("%s: test %d has no `it` description", path, i+1)
+		}
+		if seen[test.It] {
+			return nil, fmt.Errorf("%s: duplicate test %q (snapshots are keyed by test name)", path, test.It)
+		}
+		seen[test.It] = true
+		for j, assertion := range test.Asserts {
+			if err := assertion.validate(); err != nil {
+				return nil, fmt.Errorf("%s: test %q assertion %d: %w", path, test.It, j+1, err)
+			}
+		}
+	}
+	suite.dir = filepath.Dir(path)
+	return &suite, nil
+}
@@ -0,0 +1,280 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ServiceChangeKind classifies how a compose service differs between two documents.
+type ServiceChangeKind string
+
+// Service change kinds.
+const (
+	ServiceAdded   ServiceChangeKind = "added"
+	ServiceRemoved ServiceChangeKind = "removed"
+	ServiceChanged ServiceChangeKind = "changed"
+)
+
+// ServiceChange summarizes the differences of one compose service.
+type ServiceChange struct {
+	Name string            `json:"name"`
+	Kind ServiceChangeKind `json:"kind"`
+	// Image holds [before, after] when the image reference changed.
+	Image        []string `json:"image,omitempty"`
+	EnvAdded     []string `json:"envAdded,omitempty"`
+	EnvRemoved   []string `json:"envRemoved,omitempty"`
+	EnvChanged   []string `json:"envChanged,omitempty"`
+	PortsAdded   []string `json:"portsAdded,omitempty"`
+	PortsRemoved []string `json:"portsRemoved,omitempty"`
+	// Other lists remaining top-level service keys whose values differ.
+	Other []string `json:"other,omitempty"`
+}
+
+// Services compares the `services` sections of two compose documents.
+// Empty documents are treated as having no services.
+func Services(before, after []byte) ([]ServiceChange, error) {
+	oldServices, err := parseServices(before)
+	if err != nil {
+		return nil, fmt.Errorf("parse current compose file: %w", err)
+	}
+	newServices, err := parseServices(after)
+	if err != nil {
+		return nil, fmt.Errorf("parse rendered compose file: %w", err)
+	}
+
+	names := map[string]struct{}{}
+	for name := range oldServices {
+		names[name] = struct{}{}
+	}
+	for name := range newServices {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var changes []ServiceChange
+	for _, name := range sorted {
+		oldSvc, hadOld := oldServices[name]
+		newSvc, hasNew := newServices[name]
+		switch {
+		case !hadOld:
+			changes = append(changes, ServiceChange{Name: name, Kind: ServiceAdded})
+		case !hasNew:
+			changes = append(changes, ServiceChange{Name: name, Kind: ServiceRemoved})
+		default:
+			if change, ok := compareService(name, oldSvc, newSvc); ok {
+				changes = append(changes, change)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// String renders a one-line human readable summary.
+func (c ServiceChange) String() string {
+	switch c.Kind {
+	case ServiceAdded:
+		return fmt.Sprintf("+ %s (added)", c.Name)
+	case ServiceRemoved:
+		return fmt.Sprintf("- %s (removed)", c.Name)
+	}
+
+	var parts []string
+	if len(c.Image) == 2 {
+		parts = append(parts, fmt.Sprintf("image %s -> %s", orNone(c.Image[0]), orNone(c.Image[1])))
+	}
+	var env []string
+	for _, key := range c.EnvAdded {
+		env = append(env, "+"+key)
+	}
+	for _, key := range c.EnvChanged {
+		env = append(env, "~"+key)
+	}
+	for _, key := range c.EnvRemoved {
+		env = append(env, "-"+key)
+	}
+	if len(env) > 0 {
+		parts = append(parts, "environment "+strings.Join(env, ", "))
+	}
+	var ports []string
+	for _, p := range c.PortsAdded {
+		ports = append(ports, "+"+p)
+	}
+	for _, p := range c.PortsRemoved {
+		ports = append(ports, "-"+p)
+	}
+	if len(ports) > 0 {
+		parts = append(parts, "ports "+strings.Join(ports, ", "))
+	}
+	if len(c.Other) > 0 {
+		parts = append(parts, "changed "+strings.Join(c.Other, ", "))
+	}
+	return fmt.Sprintf("~ %s: %s", c.Name, strings.Join(parts, "; "))
+}
+
+func compareService(name string, before, after map[string]any) (ServiceChange, bool) {
+	change := ServiceChange{Name: name, Kind: ServiceChanged}
+	changed := false
+
+	oldImage, _ := before["image"].(string)
+	newImage, _ := after["image"].(string)
+	if oldImage != newImage {
+		change.Image = []string{oldImage, newImage}
+		changed = true
+	}
+
+	oldEnv := normalizeEnv(before["environment"])
+	newEnv := normalizeEnv(after["environment"])
+	for _, key := range sortedKeys(newEnv) {
+		oldVal, ok := oldEnv[key]
+		if !ok {
+			change.EnvAdded = append(change.EnvAdded, key)
+		} else if oldVal != newEnv[key] {
+			change.EnvChanged = append(change.EnvChanged, key)
+		}
+	}
+	for _, key := range sortedKeys(oldEnv) {
+		if _, ok := newEnv[key]; !ok {
+			change.EnvRemoved = append(change.EnvRemoved, key)
+		}
+	}
+	if len(change.EnvAdded)+len(change.EnvChanged)+len(change.EnvRemoved) > 0 {
+		changed = true
+	}
+
+	oldPorts := normalizePorts(before["ports"])
+	newPorts := normalizePorts(after["ports"])
+	for _, p := range sortedKeys(newPorts) {
+		if _, ok := oldPorts[p]; !ok {
+			change.PortsAdded = append(change.PortsAdded, p)
+		}
+	}
+	for _, p := range sortedKeys(oldPorts) {
+		if _, ok := newPorts[p]; !ok {
+			change.PortsRemoved = append(change.PortsRemoved, p)
+		}
+	}
+	if len(change.PortsAdded)+len(change.PortsRemoved) > 0 {
+		changed = true
+	}
+
+	keys := map[string]struct{}{}
+	for key := range before {
+		keys[key] = struct{}{}
+	}
+	for key := range after {
+		keys[key] = struct{}{}
+	}
+	for _, key := range sortedKeys(keys) {
+		switch key {
+		case "image", "environment", "ports":
+			continue
+		}
+		if !reflect.DeepEqual(before[key], after[key]) {
+			change.Other = append(change.Other, key)
+			changed = true
+		}
+	}
+
+	return change, changed
+}
+
+func parseServices(data []byte) (map[string]map[string]any, error) {
+	out := map[string]map[string]any{}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return out, nil
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	services, _ := doc["services"].(map[string]any)
+	for name, raw := range services {
+		svc, _ := raw.(map[string]any)
+		if svc == nil {
+			svc = map[string]any{}
+		}
+		out[name] = svc
+	}
+	return out, nil
+}
+
+func normalizeEnv(raw any) map[string]string {
+	out := map[string]string{}
+	switch typed := raw.(type) {
+	case map[string]any:
+		for key, val := range typed {
+			out[key] = scalarString(val)
+		}
+	case []any:
+		for _, item := range typed {
+			entry := scalarString(item)
+			key, val, _ := strings.Cut(entry, "=")
+			out[key] = val
+		}
+	}
+	return out
+}
+
+func normalizePorts(raw any) map[string]struct{} {
+	out := map[string]struct{}{}
+	items, _ := raw.([]any)
+	for _, item := range items {
+		switch typed := item.(type) {
+		case map[string]any:
+			published := scalarString(typed["published"])
+			target := scalarString(typed["target"])
+			port := target
+			if published != "" {
+				port = published + ":" + target
+			}
+			if proto := scalarString(typed["protocol"]); proto != "" && proto != "tcp" {
+				port += "/" + proto
+			}
+			out[port] = struct{}{}
+		default:
+			out[scalarString(typed)] = struct{}{}
+		}
+	}
+	return out
+}
+
+func scalarString(val any) string {
+	switch typed := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case float64:
+		return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%f", typed), "0"), ".")
+	case map[string]any, []any:
+		data, _ := json.Marshal(typed)
+		return string(data)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
@@ -0,0 +1,304 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultContext is the number of unchanged lines shown around each hunk.
+const DefaultContext = 3
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type edit struct {
+	kind opKind
+	line string
+}
+
+// Unified renders a unified diff between before and after, labelled with the given names.
+// It returns an empty string when both inputs are identical, and only a one-line note when
+// either side is binary.
+func Unified(beforeName, afterName string, before, after []byte, context int) string {
+	if string(before) == string(after) {
+		return ""
+	}
+	if isBinary(before) || isBinary(after) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", beforeName, afterName)
+	}
+	if context < 0 {
+		context = DefaultContext
+	}
+
+	a := splitLines(before)
+	b := splitLines(after)
+	edits := myers(a, b)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n", beforeName)
+	fmt.Fprintf(&sb, "+++ %s\n", afterName)
+	for _, h := range hunks(edits, context) {
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+// Colorize adds ANSI colors to a unified diff for terminal output.
+func Colorize(unified string) string {
+	if unified == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(unified, "\n")
+	var sb strings.Builder
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		body := strings.TrimSuffix(line, "\n")
+		suffix := line[len(body):]
+		switch {
+		case strings.HasPrefix(body, "+++"), strings.HasPrefix(body, "---"):
+			sb.WriteString("\x1b[1m" + body + "\x1b[0m" + suffix)
+		case strings.HasPrefix(body, "@@"):
+			sb.WriteString("\x1b[36m" + body + "\x1b[0m" + suffix)
+		case strings.HasPrefix(body, "+"):
+			sb.WriteString("\x1b[32m" + body + "\x1b[0m" + suffix)
+		case strings.HasPrefix(body, "-"):
+			sb.WriteString("\x1b[31m" + body + "\x1b[0m" + suffix)
+		default:
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// isBinary treats content with a NUL byte or invalid UTF-8 as binary.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	text := string(data)
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// myers computes a shortest edit script between a and b with the linear-space variant of
+// Myers' O(ND) algorithm: it finds the middle snake of the edit graph and recurses on both
+// halves, so memory stays O(N+M) however different the inputs are.
+func myers(a, b []string) []edit {
+	return appendEdits(make([]edit, 0, len(a)+len(b)), a, b)
+}
+
+func appendEdits(edits []edit, a, b []string) []edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	edits = appendLines(edits, opEqual, a[:prefix])
+	a, b = a[prefix:], b[prefix:]
+
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	common := a[len(a)-suffix:]
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		edits = appendLines(edits, opInsert, b)
+	case len(b) == 0:
+		edits = appendLines(edits, opDelete, a)
+	default:
+		x, y := middleSnake(a, b)
+		edits = appendEdits(edits, a[:x], b[:y])
+		edits = appendEdits(edits, a[x:], b[y:])
+	}
+	return appendLines(edits, opEqual, common)
+}
+
+func appendLines(edits []edit, kind opKind, lines []string) []edit {
+	for _, line := range lines {
+		edits = append(edits, edit{kind: kind, line: line})
+	}
+	return edits
+}
+
+// middleSnake runs the forward and reverse searches until their paths overlap and returns
+// the point where an optimal path crosses the middle. a and b must be non-empty and differ in
+// their first and last lines, which appendEdits guarantees, so the split always makes progress.
+func middleSnake(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	forward := make([]int, 2*offset+1)
+	reverse := make([]int, 2*offset+1)
+	for i := range forward {
+		forward[i], reverse[i] = -1, -1
+	}
+	forward[offset+1], reverse[offset+1] = 0, 0
+
+	delta := n - m
+	odd := delta%2 != 0
+	// k bounds shrink once a path runs off the edit graph
+	kStart1, kEnd1, kStart2, kEnd2 := 0, 0, 0, 0
+	for d := 0; d < maxD; d++ {
+		for k := -d + kStart1; k <= d-kEnd1; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				kEnd1 += 2
+			case y > m:
+				kStart1 += 2
+			case odd:
+				if rk := offset + delta - k; rk >= 0 && rk < len(reverse) && reverse[rk] != -1 && x >= n-reverse[rk] {
+					return x, y
+				}
+			}
+		}
+		for k := -d + kStart2; k <= d-kEnd2; k += 2 {
+			var x int
+			if k == -d || (k != d && reverse[offset+k-1] < reverse[offset+k+1]) {
+				x = reverse[offset+k+1]
+			} else {
+				x = reverse[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x++
+				y++
+			}
+			reverse[offset+k] = x
+			switch {
+			case x > n:
+				kEnd2 += 2
+			case y > m:
+				kStart2 += 2
+			case !odd:
+				if fk := offset + delta - k; fk >= 0 && fk < len(forward) && forward[fk] != -1 {
+					fx := forward[fk]
+					if fy := fx - (fk - offset); fx >= n-x {
+						return fx, fy
+					}
+				}
+			}
+		}
+	}
+	// unreachable for valid input: the paths always meet by d = maxD
+	return n, 0
+}
+
+func hunks(edits []edit, context int) []string {
+	var out []string
+	i := 0
+	for i < len(edits) {
+		for i < len(edits) && edits[i].kind == opEqual {
+			i++
+		}
+		if i >= len(edits) {
+			break
+		}
+
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].kind == opEqual {
+				run++
+			}
+			if run >= len(edits) || run-end > 2*context {
+				end += context
+				if end > len(edits) {
+					end = len(edits)
+				}
+				break
+			}
+			end = run
+		}
+
+		out = append(out, formatHunk(edits, start, end))
+		i = end
+	}
+	return out
+}
+
+func formatHunk(edits []edit, start, end int) string {
+	aStart, bStart := 1, 1
+	for _, e := range edits[:start] {
+		if e.kind != opInsert {
+			aStart++
+		}
+		if e.kind != opDelete {
+			bStart++
+		}
+	}
+
+	var aLen, bLen int
+	var body strings.Builder
+	for _, e := range edits[start:end] {
+		prefix := " "
+		switch e.kind {
+		case opEqual:
+			aLen++
+			bLen++
+		case opDelete:
+			prefix = "-"
+			aLen++
+		case opInsert:
+			prefix = "+"
+			bLen++
+		}
+		body.WriteString(prefix)
+		body.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			body.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+	return fmt.Sprintf("@@ -%s +%s @@\n%s", hunkRange(aStart, aLen), hunkRange(bStart, bLen), body.String())
+}
+
+func hunkRange(start, length int) string {
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	before := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\n"
+	after := "a\nb\nc\nD\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn"
+	want := `--- before
++++ after
+@@ -1,7 +1,7 @@
+ a
+ b
+ c
+-d
++D
+ e
+ f
+ g
+@@ -11,3 +11,4 @@
+ k
+ l
+ m
++n
+\ No newline at end of file
+`
+	if got := Unified("before", "after", []byte(before), []byte(after), DefaultContext); got != want {
+		t.Errorf("Unified() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Unified("before", "after", []byte(before), []byte(before), DefaultContext); got != "" {
+		t.Errorf("Unified() of identical input = %q", got)
+	}
+	if got := Unified("before", "after", nil, []byte("x\n"), DefaultContext); got != "--- before\n+++ after\n@@ -0,0 +1 @@\n+x\n" {
+		t.Errorf("Unified() from empty = %q", got)
+	}
+}
+
+func TestUnifiedBinary(t *testing.T) {
+	tests := map[string][2]string{
+		"nul byte":     {"text\n", "te\x00xt\n"},
+		"invalid utf8": {"caf\xe9\n", "cafe\n"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := Unified("a/x.bin", "b/x.bin", []byte(tt[0]), []byte(tt[1]), DefaultContext)
+			if want := "Binary files a/x.bin and b/x.bin differ\n"; got != want {
+				t.Errorf("Unified() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// lcs is the textbook dynamic programming answer the edit script length is checked against.
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestMyersIsMinimal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+		edits := myers(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, e := range edits {
+			if e.kind != opInsert {
+				gotA = append(gotA, e.line)
+			}
+			if e.kind != opDelete {
+				gotB = append(gotB, e.line)
+			}
+			if e.kind != opEqual {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("edits for %q -> %q do not reproduce the inputs", a, b)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Fatalf("edits for %q -> %q make %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+func TestMyersLinearMemory(t *testing.T) {
+	// two files with nothing in common are the worst case: D = N + M
+	var a, b []string
+	for i := 0; i < 2000; i++ {
+		a = append(a, fmt.Sprintf("old %d\n", i))
+		b = append(b, fmt.Sprintf("new %d\n", i))
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	edits := myers(a, b)
+	runtime.ReadMemStats(&after)
+
+	if len(edits) != len(a)+len(b) {
+		t.Fatalf("got %d edits, want %d", len(edits), len(a)+len(b))
+	}
+	// a trace of every V would allocate (N+M)² ints, about 128MB here
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 8<<20 {
+		t.Errorf("myers allocated %d bytes for %d lines", allocated, len(a)+len(b))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"composepack/internal/infra/process"
@@ -44,6 +45,12 @@ type MergeOptions struct {
 type CommandOptions struct {
 	WorkingDir string
 	Args       []string
+	// Stdin/Stdout/Stderr are only used by Stream; nil writers discard output.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY passes terminal files straight through for interactive sessions.
+	TTY bool
 }
 
 // MergeFragments shells out to `docker compose config` to get a merged YAML.
@@ -82,13 +89,57 @@ func (r *Runner) Run(ctx context.Context, opts CommandOptions) error {
 	return nil
 }
 
-func (r *Runner) run(ctx context.Context, dir string, args []string, project string) ([]byte, []byte, error) {
-	stdout, stderr, err := r.exec.Run(ctx, process.Command{
-		Name: r.primary[0],
-		Args: append(append([]string{}, r.primary[1:]...), args...),
+// Stream executes docker compose commands while forwarding output live to the provided streams.
+func (r *Runner) Stream(ctx context.Context, opts CommandOptions) error {
+	if opts.WorkingDir == "" {
+		return errors.New("working directory is required")
+	}
+	if len(opts.Args) == 0 {
+		return errors.New("docker compose arguments are required")
+	}
+
+	streams := process.Streams{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Stderr: opts.Stderr,
+		TTY:    opts.TTY,
+	}
+	stderr, err := r.exec.Stream(ctx, r.command(r.primary, opts.WorkingDir, opts.Args, ""), streams)
+	if err != nil && process.IsNotFound(err) && len(r.fallback) > 0 {
+		stderr, err = r.exec.Stream(ctx, r.command(r.fallback, opts.WorkingDir, opts.Args, ""), streams)
+	}
+	if err != nil {
+		return composeError("docker compose", err, stderr)
+	}
+	return nil
+}
+
+// RemoveContainer force-removes a container by name; missing containers are not an error.
+func (r *Runner) RemoveContainer(ctx context.Context, name string) error {
+	if name == "" {
+		return errors.New("container name is required")
+	}
+	_, stderr, err := r.exec.Run(ctx, process.Command{Name: r.primary[0], Args: []string{"rm", "-f", name}})
+	if err != nil {
+		if strings.Contains(strings.ToLower(string(stderr)), "no such container") {
+			return nil
+		}
+		return composeError("docker rm", err, stderr)
+	}
+	return nil
+}
+
+func (r *Runner) command(base []string, dir string, args []string, project string) process.Command {
+	return process.Command{
+		Name: base[0],
+		Args: append(append([]string{}, base[1:]...), args...),
 		Dir:  dir,
 		Env:  composeEnv(project),
-	})
+	}
+}
+
+func (r *Runner) run(ctx context.Context, dir string, args []string, project string) ([]byte, []byte, error) {
+	stdout, stderr, err := r.exec.Run(ctx, r.command(r.primary, dir, args, project))
 	if err == nil {
 		return stdout, stderr, nil
 	}
@@ -96,12 +147,7 @@ func (r *Runner) run(ctx context.Context, dir string, args []string, project str
 		return stdout, stderr, err
 	}
 
-	return r.exec.Run(ctx, process.Command{
-		Name: r.fallback[0],
-		Args: append(append([]string{}, r.fallback[1:]...), args...),
-		Dir:  dir,
-		Env:  composeEnv(project),
-	})
+	return r.exec.Run(ctx, r.command(r.fallback, dir, args, project))
 }
 
 func composeEnv(project string) []string {
@@ -110,6 +156,7 @@ func composeEnv(project string) []string {
 	}
 	return []string{fmt.Sprintf("COMPOSE_PROJECT_NAME=%s", project)}
 }
+
 func composeError(action string, err error, stderr []byte) error {
 	msg := strings.TrimSpace(string(stderr))
 	if msg != "" {
@@ -0,0 +1,588 @@
+package dockercompose
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// MergeEngine selects how compose fragments are merged into the release compose file.
+type MergeEngine string
+
+// Supported merge engines.
+const (
+	// MergeEngineAuto uses docker when it is installed and falls back to the native merge otherwise.
+	MergeEngineAuto MergeEngine = "auto"
+	// MergeEngineDocker always shells out to `docker compose config`.
+	MergeEngineDocker MergeEngine = "docker"
+	// MergeEngineNative merges in-process without requiring Docker.
+	MergeEngineNative MergeEngine = "native"
+)
+
+// ParseMergeEngine validates a merge engine name; empty selects MergeEngineAuto.
+func ParseMergeEngine(name string) (MergeEngine, error) {
+	switch engine := MergeEngine(strings.ToLower(strings.TrimSpace(name))); engine {
+	case "":
+		return MergeEngineAuto, nil
+	case MergeEngineAuto, MergeEngineDocker, MergeEngineNative:
+		return engine, nil
+	default:
+		return "", fmt.Errorf("unknown merge engine %q (expected auto, docker or native)", name)
+	}
+}
+
+// Fragment is one rendered compose document handed to MergeNative.
+type Fragment struct {
+	Name string
+	Data []byte
+}
+
+const (
+	resetTag    = "!reset"
+	overrideTag = "!override"
+)
+
+// overrideAttrs are replaced wholesale instead of merged.
+var overrideAttrs = []string{
+	"services.*.command",
+	"services.*.entrypoint",
+	"services.*.healthcheck.test",
+}
+
+// mappingAttrs accept either a `KEY=VALUE` list or a mapping and are merged by key.
+var mappingAttrs = []string{
+	"services.*.environment",
+	"services.*.labels",
+	"services.*.annotations",
+	"services.*.sysctls",
+	"services.*.build.args",
+	"services.*.build.labels",
+	"services.*.deploy.labels",
+	"networks.*.labels",
+	"volumes.*.labels",
+}
+
+// uniqueAttrs are sequences whose entries are merged by the key returned by the indexer.
+var uniqueAttrs = map[string]func(*yaml.Node) string{
+	"services.*.ports":                     portKey,
+	"services.*.volumes":                   volumeTarget,
+	"services.*.devices":                   volumeTarget,
+	"services.*.secrets":                   mountTarget,
+	"services.*.configs":                   mountTarget,
+	"services.*.env_file":                  envFileKey,
+	"services.*.extra_hosts":               hostKey,
+	"services.*.expose":                    nodeKey,
+	"services.*.cap_add":                   nodeKey,
+	"services.*.cap_drop":                  nodeKey,
+	"services.*.dns":                       nodeKey,
+	"services.*.dns_opt":                   nodeKey,
+	"services.*.dns_search":                nodeKey,
+	"services.*.profiles":                  nodeKey,
+	"services.*.tmpfs":                     nodeKey,
+	"services.*.links":                     nodeKey,
+	"services.*.build.tags":                nodeKey,
+	"services.*.networks.*.aliases":        nodeKey,
+	"services.*.networks.*.link_local_ips": nodeKey,
+}
+
+// MergeNative merges compose fragments in order following the Compose specification merge
+// rules: mappings merge recursively, well-known sequences (ports, volumes, environment, ...)
+// merge by key, other sequences append and scalars are overridden. Later fragments can use the
+// `!reset` and `!override` tags to drop or replace an attribute. Relative paths are normalized
+// against the release directory so they keep pointing into `./files`.
+func MergeNative(fragments []Fragment, projectName string) ([]byte, error) {
+	if len(fragments) == 0 {
+		return nil, errors.New("at least one compose fragment is required")
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, fragment := range fragments {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(fragment.Data, &doc); err != nil {
+			return nil, fmt.Errorf("parse compose fragment %s: %w", fragment.Name, err)
+		}
+		if doc.Kind == 0 || len(doc.Content) == 0 {
+			continue
+		}
+		root := expandAliases(doc.Content[0])
+		if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+			continue
+		}
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("compose fragment %s: top-level must be a mapping", fragment.Name)
+		}
+		if err := validateServices(root); err != nil {
+			return nil, fmt.Errorf("compose fragment %s: %w", fragment.Name, err)
+		}
+		merged = mergeMapping(nil, merged, root)
+	}
+
+	normalizePaths(merged)
+	if projectName != "" && mappingValue(merged, "name") == nil {
+		merged.Content = append([]*yaml.Node{scalar("name"), scalar(projectName)}, merged.Content...)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(merged); err != nil {
+		return nil, fmt.Errorf("encode merged compose file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode merged compose file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func validateServices(root *yaml.Node) error {
+	services := mappingValue(root, "services")
+	if services == nil || isNull(services) {
+		return nil
+	}
+	if services.Kind != yaml.MappingNode {
+		return errors.New("services must be a mapping")
+	}
+	for i := 0; i < len(services.Content); i += 2 {
+		svc := services.Content[i+1]
+		if svc.Kind != yaml.MappingNode && !isNull(svc) && svc.Tag != resetTag {
+			return fmt.Errorf("service %s must be a mapping", services.Content[i].Value)
+		}
+	}
+	return nil
+}
+
+// mergeValue merges over onto base (which may be nil) at the given attribute path.
+func mergeValue(path []string, base, over *yaml.Node) *yaml.Node {
+	if over.Tag == overrideTag {
+		return mergeValue(path, nil, untagged(over))
+	}
+	switch {
+	case matchAny(path, overrideAttrs):
+		return clean(over)
+	case matchAny(path, mappingAttrs):
+		return mergeMapping(path, toMapping(base, false), toMapping(over, false))
+	case matchPath(path, "services.*.depends_on"), matchPath(path, "services.*.networks"):
+		return mergeListOrMapping(path, base, over)
+	case matchPath(path, "services.*.logging"):
+		if base != nil && base.Kind == yaml.MappingNode && over.Kind == yaml.MappingNode {
+			oldDriver, newDriver := mappingValue(base, "driver"), mappingValue(over, "driver")
+			if oldDriver != nil && newDriver != nil && oldDriver.Value != newDriver.Value {
+				return clean(over)
+			}
+		}
+	}
+
+	if indexer := uniqueIndexer(path); indexer != nil && over.Kind == yaml.SequenceNode {
+		var items []*yaml.Node
+		if base != nil && base.Kind == yaml.SequenceNode {
+			items = append(items, base.Content...)
+		}
+		for _, item := range over.Content {
+			items = append(items, clean(item))
+		}
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: dedupe(items, indexer)}
+	}
+
+	switch {
+	case over.Kind == yaml.MappingNode && (base == nil || base.Kind == yaml.MappingNode || isNull(base)):
+		if base == nil || isNull(base) {
+			base = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		return mergeMapping(path, base, over)
+	case over.Kind == yaml.SequenceNode && base != nil && base.Kind == yaml.SequenceNode:
+		out := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: base.Style}
+		out.Content = append(append(out.Content, base.Content...), cleanAll(over.Content)...)
+		return out
+	case over.Kind == yaml.MappingNode && base != nil && !isNull(base):
+		// e.g. `build: ./dir` extended with `build: {args: ...}`: the mapping wins.
+		return mergeMapping(path, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, over)
+	default:
+		return clean(over)
+	}
+}
+
+// mergeMapping merges the entries of over into a copy of base.
+func mergeMapping(path []string, base, over *yaml.Node) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: base.Style}
+	out.Content = append(out.Content, base.Content...)
+	for i := 0; i+1 < len(over.Content); i += 2 {
+		key, val := over.Content[i], over.Content[i+1]
+		idx := mappingIndex(out, key.Value)
+		child := append(append([]string{}, path...), key.Value)
+
+		if val.Tag == resetTag {
+			if idx >= 0 {
+				out.Content = append(out.Content[:idx], out.Content[idx+2:]...)
+			}
+			continue
+		}
+		var existing *yaml.Node
+		if idx >= 0 {
+			existing = out.Content[idx+1]
+		}
+		merged := mergeValue(child, existing, val)
+		if idx >= 0 {
+			out.Content[idx+1] = merged
+		} else {
+			out.Content = append(out.Content, clean(key), merged)
+		}
+	}
+	return out
+}
+
+// mergeListOrMapping handles attributes such as depends_on and service networks that accept
+// either a list of names or a mapping keyed by name.
+func mergeListOrMapping(path []string, base, over *yaml.Node) *yaml.Node {
+	if (base == nil || base.Kind == yaml.SequenceNode) && over.Kind == yaml.SequenceNode {
+		var items []*yaml.Node
+		if base != nil {
+			items = append(items, base.Content...)
+		}
+		items = append(items, cleanAll(over.Content)...)
+		return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: dedupe(items, nodeKey)}
+	}
+	dependsOn := path[len(path)-1] == "depends_on"
+	return mergeMapping(path, toMapping(base, dependsOn), toMapping(over, dependsOn))
+}
+
+// toMapping converts list syntax (`KEY=VALUE` entries or plain names) into a mapping node.
+func toMapping(node *yaml.Node, dependsOn bool) *yaml.Node {
+	out := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if node == nil || isNull(node) {
+		return out
+	}
+	if node.Kind == yaml.MappingNode {
+		return node
+	}
+	if node.Kind != yaml.SequenceNode {
+		return out
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			continue
+		}
+		if dependsOn {
+			cond := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			cond.Content = append(cond.Content, scalar("condition"), scalar("service_started"))
+			out.Content = append(out.Content, scalar(item.Value), cond)
+			continue
+		}
+		key, val, ok := strings.Cut(item.Value, "=")
+		if !ok {
+			out.Content = append(out.Content, scalar(key), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+			continue
+		}
+		out.Content = append(out.Content, scalar(key), scalar(val))
+	}
+	return out
+}
+
+// dedupe keeps the first position of every key while letting later entries replace its value.
+func dedupe(items []*yaml.Node, indexer func(*yaml.Node) string) []*yaml.Node {
+	seen := map[string]int{}
+	var out []*yaml.Node
+	for _, item := range items {
+		key := indexer(item)
+		if idx, ok := seen[key]; ok {
+			out[idx] = item
+			continue
+		}
+		seen[key] = len(out)
+		out = append(out, item)
+	}
+	return out
+}
+
+func uniqueIndexer(path []string) func(*yaml.Node) string {
+	for pattern, indexer := range uniqueAttrs {
+		if matchPath(path, pattern) {
+			return indexer
+		}
+	}
+	return nil
+}
+
+func nodeKey(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+	data, _ := yaml.Marshal(n)
+	return string(data)
+}
+
+func portKey(n *yaml.Node) string {
+	if n.Kind != yaml.MappingNode {
+		return nodeKey(n)
+	}
+	field := func(name string) string {
+		if v := mappingValue(n, name); v != nil {
+			return v.Value
+		}
+		return ""
+	}
+	protocol := field("protocol")
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	return fmt.Sprintf("%s:%s:%s/%s", field("host_ip"), field("published"), field("target"), protocol)
+}
+
+func volumeTarget(n *yaml.Node) string {
+	if n.Kind == yaml.MappingNode {
+		if target := mappingValue(n, "target"); target != nil {
+			return target.Value
+		}
+		return nodeKey(n)
+	}
+	parts := strings.Split(n.Value, ":")
+	if len(parts) >= 2 {
+		return parts[1]
+	}
+	return parts[0]
+}
+
+func mountTarget(n *yaml.Node) string {
+	if n.Kind == yaml.MappingNode {
+		if target := mappingValue(n, "target"); target != nil {
+			return target.Value
+		}
+		if source := mappingValue(n, "source"); source != nil {
+			return source.Value
+		}
+		return nodeKey(n)
+	}
+	return n.Value
+}
+
+func envFileKey(n *yaml.Node) string {
+	if n.Kind == yaml.MappingNode {
+		if p := mappingValue(n, "path"); p != nil {
+			return p.Value
+		}
+	}
+	return nodeKey(n)
+}
+
+func hostKey(n *yaml.Node) string {
+	if n.Kind != yaml.ScalarNode {
+		return nodeKey(n)
+	}
+	if host, _, ok := strings.Cut(n.Value, "="); ok {
+		return host
+	}
+	host, _, _ := strings.Cut(n.Value, ":")
+	return host
+}
+
+// normalizePaths rewrites relative host paths so they resolve from the release directory.
+func normalizePaths(root *yaml.Node) {
+	if services := mappingValue(root, "services"); services != nil && services.Kind == yaml.MappingNode {
+		for i := 1; i < len(services.Content); i += 2 {
+			normalizeServicePaths(services.Content[i])
+		}
+	}
+	for _, section := range []string{"configs", "secrets"} {
+		defs := mappingValue(root, section)
+		if defs == nil || defs.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 1; i < len(defs.Content); i += 2 {
+			if file := mappingValue(defs.Content[i], "file"); file != nil {
+				file.Value = relativePath(file.Value)
+			}
+		}
+	}
+}
+
+func normalizeServicePaths(svc *yaml.Node) {
+	if svc.Kind != yaml.MappingNode {
+		return
+	}
+	if volumes := mappingValue(svc, "volumes"); volumes != nil && volumes.Kind == yaml.SequenceNode {
+		for _, vol := range volumes.Content {
+			switch vol.Kind {
+			case yaml.ScalarNode:
+				source, rest, ok := strings.Cut(vol.Value, ":")
+				if ok && isHostPath(source) {
+					vol.Value = relativePath(source) + ":" + rest
+				}
+			case yaml.MappingNode:
+				if source := mappingValue(vol, "source"); source != nil && isHostPath(source.Value) {
+					source.Value = relativePath(source.Value)
+				}
+			}
+		}
+	}
+	if build := mappingValue(svc, "build"); build != nil {
+		switch build.Kind {
+		case yaml.ScalarNode:
+			build.Value = relativePath(build.Value)
+		case yaml.MappingNode:
+			if context := mappingValue(build, "context"); context != nil {
+				context.Value = relativePath(context.Value)
+			}
+		}
+	}
+	if envFile := mappingValue(svc, "env_file"); envFile != nil {
+		switch envFile.Kind {
+		case yaml.ScalarNode:
+			envFile.Value = relativePath(envFile.Value)
+		case yaml.SequenceNode:
+			for _, item := range envFile.Content {
+				if item.Kind == yaml.ScalarNode {
+					item.Value = relativePath(item.Value)
+				} else if p := mappingValue(item, "path"); p != nil {
+					p.Value = relativePath(p.Value)
+				}
+			}
+		}
+	}
+}
+
+func isHostPath(source string) bool {
+	return strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~")
+}
+
+// relativePath cleans a relative path and anchors it with `./`; absolute paths, home paths,
+// URLs and interpolated values are left untouched.
+func relativePath(p string) string {
+	if p == "" || strings.HasPrefix(p, "/") || strings.HasPrefix(p, "~") ||
+		strings.Contains(p, "://") || strings.Contains(p, "${") || strings.HasPrefix(p, "git@") {
+		return p
+	}
+	cleaned := path.Clean(p)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return cleaned
+	}
+	return "./" + cleaned
+}
+
+// expandAliases returns a deep copy of n with aliases and `<<` merge keys resolved.
+func expandAliases(n *yaml.Node) *yaml.Node {
+	if n.Kind == yaml.AliasNode {
+		return expandAliases(n.Alias)
+	}
+	out := *n
+	out.Anchor = ""
+	out.Content = nil
+	if n.Kind != yaml.MappingNode {
+		for _, child := range n.Content {
+			out.Content = append(out.Content, expandAliases(child))
+		}
+		return &out
+	}
+
+	var inherited []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		if key.Tag != "!!merge" {
+			out.Content = append(out.Content, expandAliases(key), expandAliases(val))
+			continue
+		}
+		sources := []*yaml.Node{expandAliases(val)}
+		if sources[0].Kind == yaml.SequenceNode {
+			sources = sources[0].Content
+		}
+		for _, src := range sources {
+			inherited = append(inherited, src.Content...)
+		}
+	}
+	for i := 0; i+1 < len(inherited); i += 2 {
+		if mappingIndex(&out, inherited[i].Value) < 0 {
+			out.Content = append(out.Content, inherited[i], inherited[i+1])
+		}
+	}
+	return &out
+}
+
+// clean strips merge tags from a node, dropping `!reset` entries nested inside it.
+func clean(n *yaml.Node) *yaml.Node {
+	n = untagged(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		return mergeMapping(nil, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Style: n.Style}, n)
+	case yaml.SequenceNode:
+		out := *n
+		out.Content = nil
+		for _, item := range n.Content {
+			if item.Tag != resetTag {
+				out.Content = append(out.Content, clean(item))
+			}
+		}
+		return &out
+	}
+	return n
+}
+
+func cleanAll(nodes []*yaml.Node) []*yaml.Node {
+	out := make([]*yaml.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Tag != resetTag {
+			out = append(out, clean(n))
+		}
+	}
+	return out
+}
+
+func untagged(n *yaml.Node) *yaml.Node {
+	if n.Tag != overrideTag && n.Tag != resetTag {
+		return n
+	}
+	out := *n
+	out.Tag = ""
+	return &out
+}
+
+func mappingIndex(m *yaml.Node, key string) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	if idx := mappingIndex(m, key); idx >= 0 {
+		return m.Content[idx+1]
+	}
+	return nil
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func matchAny(path []string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matchPath(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPath reports whether an attribute path matches a dotted pattern where `*` matches any key.
+func matchPath(path []string, pattern string) bool {
+	parts := strings.Split(pattern, ".")
+	if len(parts) != len(path) {
+		return false
+	}
+	for i, part := range parts {
+		if part != "*" && part != path[i] {
+			return false
+		}
+	}
+	return true
+}
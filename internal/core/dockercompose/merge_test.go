@@ -0,0 +1,343 @@
+package dockercompose
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+var (
+	update       = flag.Bool("update", false, "rewrite the native golden files in testdata/merge")
+	updateDocker = flag.Bool("update-docker", false, "rewrite the docker golden files in testdata/merge with docker compose config")
+)
+
+// mergeCases lists testdata/merge/<case>; each holds fragments merged in name order, the
+// expected native output in merged.golden.yaml and docker's in docker.golden.yaml.
+func mergeCases(t *testing.T) map[string][]string {
+	t.Helper()
+	dirs, err := filepath.Glob(filepath.Join("testdata", "merge", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string][]string{}
+	for _, dir := range dirs {
+		fragments, err := filepath.Glob(filepath.Join(dir, "[0-9]*.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cases[dir] = fragments
+	}
+	if len(cases) == 0 {
+		t.Fatal("no merge fixtures found")
+	}
+	return cases
+}
+
+func readFragments(t *testing.T, paths []string) []Fragment {
+	t.Helper()
+	var fragments []Fragment
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fragments = append(fragments, Fragment{Name: filepath.Base(path), Data: data})
+	}
+	return fragments
+}
+
+func TestMergeNativeGolden(t *testing.T) {
+	for dir, paths := range mergeCases(t) {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			got, err := MergeNative(readFragments(t, paths), "demo")
+			if err != nil {
+				t.Fatalf("MergeNative: %v", err)
+			}
+			golden := filepath.Join(dir, "merged.golden.yaml")
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("MergeNative output differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// dockerProjectDir stands in for the directory docker compose resolved relative paths against
+// when the docker goldens were generated.
+const dockerProjectDir = "/work"
+
+// TestMergeNativeMatchesDocker compares the native merge with docker.golden.yaml, the
+// `docker compose config` output for the same fragments. Both are normalized first: docker
+// spells out defaults and long syntax the native engine leaves alone. Run with -update-docker
+// on a machine with Docker to regenerate the goldens.
+func TestMergeNativeMatchesDocker(t *testing.T) {
+	for dir, paths := range mergeCases(t) {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			golden := filepath.Join(dir, "docker.golden.yaml")
+			if *updateDocker {
+				writeDockerGolden(t, golden, paths)
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update-docker to create it)", err)
+			}
+			got, err := MergeNative(readFragments(t, paths), "demo")
+			if err != nil {
+				t.Fatalf("MergeNative: %v", err)
+			}
+
+			gotProject, wantProject := normalizeProject(t, got), normalizeProject(t, want)
+			if !reflect.DeepEqual(gotProject, wantProject) {
+				gotYAML, _ := yaml.Marshal(gotProject)
+				wantYAML, _ := yaml.Marshal(wantProject)
+				t.Errorf("native merge differs from %s after normalizing\nnative:\n%s\ndocker:\n%s", golden, gotYAML, wantYAML)
+			}
+		})
+	}
+}
+
+func writeDockerGolden(t *testing.T, golden string, paths []string) {
+	t.Helper()
+	// copies keep docker from resolving paths against the source tree
+	work := t.TempDir()
+	var fragmentPaths []string
+	for _, fragment := range readFragments(t, paths) {
+		dest := filepath.Join(work, fragment.Name)
+		if err := os.WriteFile(dest, fragment.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		fragmentPaths = append(fragmentPaths, dest)
+	}
+	out, err := NewRunner(nil).MergeFragments(context.Background(), MergeOptions{WorkingDir: work, FragmentPaths: fragmentPaths, ProjectName: "demo"})
+	if err != nil {
+		t.Fatalf("docker compose config: %v", err)
+	}
+	out = bytes.ReplaceAll(out, []byte(work), []byte(dockerProjectDir))
+	if err := os.WriteFile(golden, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// normalizeProject rewrites a compose file into one canonical form: short syntax becomes long
+// syntax, relative paths are resolved against dockerProjectDir, and the defaults docker adds
+// (the default network, resource names, required: true, ...) are dropped. Ports and volumes are
+// sorted because their order carries no meaning.
+func normalizeProject(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var project map[string]any
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		t.Fatalf("parse compose file: %v\n%s", err, data)
+	}
+	name, _ := project["name"].(string)
+	for _, kind := range []string{"networks", "volumes"} {
+		resources, _ := project[kind].(map[string]any)
+		for key, value := range resources {
+			resource, _ := value.(map[string]any)
+			if resource["name"] == name+"_"+key {
+				delete(resource, "name")
+			}
+			if kind == "networks" && key == "default" && len(resource) == 0 {
+				delete(resources, key)
+				continue
+			}
+			if resource == nil {
+				resource = map[string]any{}
+			}
+			resources[key] = resource
+		}
+		if resources != nil && len(resources) == 0 {
+			delete(project, kind)
+		}
+	}
+	services, _ := project["services"].(map[string]any)
+	for _, value := range services {
+		normalizeService(value.(map[string]any))
+	}
+	return project
+}
+
+func normalizeService(service map[string]any) {
+	for _, key := range []string{"environment", "labels"} {
+		if list, ok := service[key].([]any); ok {
+			m := map[string]any{}
+			for _, item := range list {
+				k, v, _ := strings.Cut(fmt.Sprint(item), "=")
+				m[k] = v
+			}
+			service[key] = m
+		}
+	}
+
+	if networks, ok := service["networks"].([]any); ok {
+		m := map[string]any{}
+		for _, network := range networks {
+			m[fmt.Sprint(network)] = nil
+		}
+		service["networks"] = m
+	}
+	if networks, ok := service["networks"].(map[string]any); ok && len(networks) == 1 {
+		if value, ok := networks["default"]; ok && value == nil {
+			delete(service, "networks")
+		}
+	}
+
+	if deps, ok := service["depends_on"].([]any); ok {
+		m := map[string]any{}
+		for _, dep := range deps {
+			m[fmt.Sprint(dep)] = map[string]any{"condition": "service_started"}
+		}
+		service["depends_on"] = m
+	}
+	if deps, ok := service["depends_on"].(map[string]any); ok {
+		for _, value := range deps {
+			if dep, ok := value.(map[string]any); ok && dep["required"] == true {
+				delete(dep, "required")
+			}
+		}
+	}
+
+	if envFiles, ok := service["env_file"]; ok {
+		list, ok := envFiles.([]any)
+		if !ok {
+			list = []any{envFiles}
+		}
+		var paths []any
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok {
+				item = m["path"]
+			}
+			paths = append(paths, projectPath(fmt.Sprint(item)))
+		}
+		service["env_file"] = paths
+	}
+
+	if ports, ok := service["ports"].([]any); ok {
+		for i, port := range ports {
+			ports[i] = normalizePort(port)
+		}
+		sortBy(ports, "target", "published")
+	}
+	if volumes, ok := service["volumes"].([]any); ok {
+		for i, volume := range volumes {
+			volumes[i] = normalizeVolume(volume)
+		}
+		sortBy(volumes, "target")
+	}
+}
+
+// normalizePort handles the `[host_ip:]published:target[/protocol]` forms the fixtures use.
+func normalizePort(port any) map[string]any {
+	out := map[string]any{"protocol": "tcp"}
+	if long, ok := port.(map[string]any); ok {
+		for key, value := range long {
+			if key != "mode" || value != "ingress" {
+				out[key] = fmt.Sprint(value)
+			}
+		}
+		return out
+	}
+	spec, protocol, ok := strings.Cut(fmt.Sprint(port), "/")
+	if ok {
+		out["protocol"] = protocol
+	}
+	parts := strings.Split(spec, ":")
+	out["target"] = parts[len(parts)-1]
+	if len(parts) > 1 {
+		out["published"] = parts[len(parts)-2]
+	}
+	if len(parts) > 2 {
+		out["host_ip"] = strings.Join(parts[:len(parts)-2], ":")
+	}
+	return out
+}
+
+// normalizeVolume handles `source:target[:ro]` and the long syntax docker prints.
+func normalizeVolume(volume any) map[string]any {
+	if long, ok := volume.(map[string]any); ok {
+		out := map[string]any{}
+		for key, value := range long {
+			switch key {
+			case "bind", "volume":
+				// create_host_path: true and an empty volume section are docker's defaults
+			case "source":
+				if long["type"] == "bind" {
+					value = projectPath(fmt.Sprint(value))
+				}
+				out[key] = value
+			default:
+				out[key] = value
+			}
+		}
+		return out
+	}
+	parts := strings.Split(fmt.Sprint(volume), ":")
+	out := map[string]any{"type": "volume", "source": parts[0], "target": parts[1]}
+	if strings.HasPrefix(parts[0], ".") || strings.HasPrefix(parts[0], "/") {
+		out["type"], out["source"] = "bind", projectPath(parts[0])
+	}
+	if len(parts) > 2 && parts[2] == "ro" {
+		out["read_only"] = true
+	}
+	return out
+}
+
+func projectPath(p string) string {
+	if path.IsAbs(p) {
+		return path.Clean(p)
+	}
+	return path.Join(dockerProjectDir, p)
+}
+
+func sortBy(items []any, keys ...string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].(map[string]any), items[j].(map[string]any)
+		for _, key := range keys {
+			if x, y := fmt.Sprint(a[key]), fmt.Sprint(b[key]); x != y {
+				return x < y
+			}
+		}
+		return false
+	})
+}
+
+func TestMergeNativeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"not a mapping", "- a\n", "top-level must be a mapping"},
+		{"services list", "services: [a]\n", "services must be a mapping"},
+		{"service scalar", "services:\n  web: nginx\n", "service web must be a mapping"},
+		{"invalid yaml", "services: {\n", "parse compose fragment"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MergeNative([]Fragment{{Name: "f.yaml", Data: []byte(tt.data)}}, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+	if _, err := MergeNative(nil, ""); err == nil {
+		t.Error("MergeNative(nil) succeeded")
+	}
+}
@@ -0,0 +1,85 @@
+package dockercompose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContainerStatus mirrors the fields of `docker compose ps --format json` that ComposePack uses.
+type ContainerStatus struct {
+	Name     string `json:"Name"`
+	Service  string `json:"Service"`
+	State    string `json:"State"`
+	Health   string `json:"Health"`
+	ExitCode int    `json:"ExitCode"`
+	Status   string `json:"Status"`
+}
+
+// Running reports whether the container is up.
+func (c ContainerStatus) Running() bool {
+	return strings.EqualFold(c.State, "running")
+}
+
+// PS returns the state of every container (including stopped ones) in the runtime directory's project.
+func (r *Runner) PS(ctx context.Context, workingDir string) ([]ContainerStatus, error) {
+	if workingDir == "" {
+		return nil, errors.New("working directory is required")
+	}
+
+	stdout, stderr, err := r.run(ctx, workingDir, []string{"ps", "--all", "--format", "json"}, "")
+	if err != nil {
+		return nil, composeError("docker compose ps", err, stderr)
+	}
+	return parsePSOutput(stdout)
+}
+
+// Logs returns the last tail lines of output for the given services (all services when empty).
+func (r *Runner) Logs(ctx context.Context, workingDir string, services []string, tail int) ([]byte, error) {
+	if workingDir == "" {
+		return nil, errors.New("working directory is required")
+	}
+
+	args := []string{"logs", "--no-color", "--tail", strconv.Itoa(tail)}
+	args = append(args, services...)
+	stdout, stderr, err := r.run(ctx, workingDir, args, "")
+	if err != nil {
+		return nil, composeError("docker compose logs", err, stderr)
+	}
+	return stdout, nil
+}
+
+// parsePSOutput accepts both the JSON array emitted by older Compose v2 releases and the
+// newline-delimited objects emitted by newer ones.
+func parsePSOutput(data []byte) ([]ContainerStatus, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+
+	if trimmed[0] == '[' {
+		var out []ContainerStatus
+		if err := json.Unmarshal(trimmed, &out); err != nil {
+			return nil, fmt.Errorf("parse docker compose ps output: %w", err)
+		}
+		return out, nil
+	}
+
+	var out []ContainerStatus
+	for _, line := range bytes.Split(trimmed, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var status ContainerStatus
+		if err := json.Unmarshal(line, &status); err != nil {
+			return nil, fmt.Errorf("parse docker compose ps output: %w", err)
+		}
+		out = append(out, status)
+	}
+	return out, nil
+}
@@ -0,0 +1,323 @@
+package hooks
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	yaml "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+const (
+	// ExtensionKey marks a compose service as a lifecycle hook instead of a long-running service.
+	ExtensionKey = "x-composepack-hook"
+	// FileName is the runtime compose file that holds hook services.
+	FileName = "docker-compose.hooks.yaml"
+)
+
+// Event names a point in the release lifecycle at which hooks run.
+type Event string
+
+// Supported hook events.
+const (
+	PreInstall  Event = "pre-install"
+	PostInstall Event = "post-install"
+	PreUpgrade  Event = "pre-upgrade"
+	PostUpgrade Event = "post-upgrade"
+	PreDelete   Event = "pre-delete"
+	PostDelete  Event = "post-delete"
+)
+
+// DeletePolicy controls when a hook's container is removed. Without one the container is
+// removed as soon as the hook exits.
+type DeletePolicy string
+
+// Supported delete policies (Helm semantics).
+const (
+	// BeforeHookCreation removes the previous container before the hook runs again.
+	BeforeHookCreation DeletePolicy = "before-hook-creation"
+	// HookSucceeded removes the container after the hook succeeds.
+	HookSucceeded DeletePolicy = "hook-succeeded"
+	// HookFailed removes the container after the hook fails.
+	HookFailed DeletePolicy = "hook-failed"
+)
+
+// Hook is a compose service that runs once at the given events.
+type Hook struct {
+	Service        string
+	Events         []Event
+	Weight         int
+	DeletePolicies []DeletePolicy
+}
+
+// Has reports whether the hook subscribes to event.
+func (h Hook) Has(event Event) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoRemove reports whether the hook container can be run with `--rm`, which is only the case
+// without a delete policy: every policy keeps the container for at least one outcome.
+func (h Hook) AutoRemove() bool {
+	return len(h.DeletePolicies) == 0
+}
+
+// HasPolicy reports whether the hook uses the given delete policy.
+func (h Hook) HasPolicy(policy DeletePolicy) bool {
+	for _, p := range h.DeletePolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// spec is the accepted shape of the `x-composepack-hook` extension. Events and delete-policy
+// accept a single string or a list; the whole extension may also be just the event(s).
+type spec struct {
+	Events       any `yaml:"events"`
+	Weight       int `yaml:"weight"`
+	DeletePolicy any `yaml:"delete-policy"`
+}
+
+// Extract moves services marked with `x-composepack-hook` out of a merged compose file.
+// It returns the compose file without hooks, a compose file holding only the hook services
+// (nil when there are none) and the parsed hooks.
+func Extract(composeYAML []byte) ([]byte, []byte, []Hook, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(composeYAML, &doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("parse compose file: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return composeYAML, nil, nil, nil
+	}
+	root := doc.Content[0]
+	services := mappingValue(root, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return composeYAML, nil, nil, nil
+	}
+
+	var hooks []Hook
+	kept := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	hookServices := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, svc := services.Content[i], services.Content[i+1]
+		ext := mappingValue(svc, ExtensionKey)
+		if ext == nil {
+			kept.Content = append(kept.Content, name, svc)
+			continue
+		}
+		hook, err := parseHook(name.Value, ext)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		hooks = append(hooks, hook)
+		hookServices.Content = append(hookServices.Content, name, svc)
+	}
+	if len(hooks) == 0 {
+		return composeYAML, nil, nil, nil
+	}
+
+	if err := checkDependencies(kept, hooks); err != nil {
+		return nil, nil, nil, err
+	}
+
+	services.Content = kept.Content
+	main, err := encode(root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hooksDoc := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	hooksDoc.Content = append(hooksDoc.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "services"},
+		hookServices,
+	)
+	hooksYAML, err := encode(hooksDoc)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return main, hooksYAML, hooks, nil
+}
+
+// Parse reads the hooks defined in a hooks compose file written by Extract.
+func Parse(hooksYAML []byte) ([]Hook, error) {
+	if len(bytes.TrimSpace(hooksYAML)) == 0 {
+		return nil, nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(hooksYAML, &doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", FileName, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	services := mappingValue(doc.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var hooks []Hook
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		ext := mappingValue(services.Content[i+1], ExtensionKey)
+		if ext == nil {
+			continue
+		}
+		hook, err := parseHook(services.Content[i].Value, ext)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// ForEvent returns the hooks subscribed to event ordered by weight, then service name.
+func ForEvent(hooks []Hook, event Event) []Hook {
+	var out []Hook
+	for _, h := range hooks {
+		if h.Has(event) {
+			out = append(out, h)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Weight != out[j].Weight {
+			return out[i].Weight < out[j].Weight
+		}
+		return out[i].Service < out[j].Service
+	})
+	return out
+}
+
+func parseHook(service string, ext *yaml.Node) (Hook, error) {
+	var s spec
+	if ext.Kind == yaml.MappingNode {
+		if err := ext.Decode(&s); err != nil {
+			return Hook{}, fmt.Errorf("service %s: invalid %s: %w", service, ExtensionKey, err)
+		}
+	} else {
+		var events any
+		if err := ext.Decode(&events); err != nil {
+			return Hook{}, fmt.Errorf("service %s: invalid %s: %w", service, ExtensionKey, err)
+		}
+		s.Events = events
+	}
+
+	hook := Hook{Service: service, Weight: s.Weight}
+	events, err := stringList(s.Events)
+	if err != nil {
+		return Hook{}, fmt.Errorf("service %s: %s events: %w", service, ExtensionKey, err)
+	}
+	if len(events) == 0 {
+		return Hook{}, fmt.Errorf("service %s: %s requires at least one event", service, ExtensionKey)
+	}
+	for _, e := range events {
+		switch event := Event(e); event {
+		case PreInstall, PostInstall, PreUpgrade, PostUpgrade, PreDelete, PostDelete:
+			hook.Events = append(hook.Events, event)
+		default:
+			return Hook{}, fmt.Errorf("service %s: unknown hook event %q", service, e)
+		}
+	}
+
+	policies, err := stringList(s.DeletePolicy)
+	if err != nil {
+		return Hook{}, fmt.Errorf("service %s: %s delete-policy: %w", service, ExtensionKey, err)
+	}
+	for _, p := range policies {
+		switch policy := DeletePolicy(p); policy {
+		case BeforeHookCreation, HookSucceeded, HookFailed:
+			hook.DeletePolicies = append(hook.DeletePolicies, policy)
+		default:
+			return Hook{}, fmt.Errorf("service %s: unknown hook delete policy %q", service, p)
+		}
+	}
+	return hook, nil
+}
+
+// checkDependencies rejects long-running services that depend on a hook, since hooks are
+// not part of the main compose file.
+func checkDependencies(services *yaml.Node, hooks []Hook) error {
+	hookNames := map[string]bool{}
+	for _, h := range hooks {
+		hookNames[h.Service] = true
+	}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		deps := mappingValue(services.Content[i+1], "depends_on")
+		if deps == nil {
+			continue
+		}
+		var names []string
+		switch deps.Kind {
+		case yaml.SequenceNode:
+			for _, item := range deps.Content {
+				names = append(names, item.Value)
+			}
+		case yaml.MappingNode:
+			for j := 0; j < len(deps.Content); j += 2 {
+				names = append(names, deps.Content[j].Value)
+			}
+		}
+		for _, dep := range names {
+			if hookNames[dep] {
+				return fmt.Errorf("service %s depends on hook service %s; use hook events and weights to order hooks instead", services.Content[i].Value, dep)
+			}
+		}
+	}
+	return nil
+}
+
+func stringList(raw any) ([]string, error) {
+	switch typed := raw.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		var out []string
+		for _, part := range strings.Split(typed, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+		return out, nil
+	case []any:
+		out := make([]string, 0, len(typed))
+		for _, item := range typed {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected string, got %T", item)
+			}
+			out = append(out, strings.TrimSpace(s))
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("expected string or list, got %T", raw)
+	}
+}
+
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func encode(node *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, fmt.Errorf("encode compose file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode compose file: %w", err)
+	}
+	return buf.Bytes(), nil
+}
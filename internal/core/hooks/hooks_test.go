@@ -0,0 +1,67 @@
+package hooks
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractDeletePolicies(t *testing.T) {
+	compose := `
+services:
+  web:
+    image: nginx
+  plain:
+    image: busybox
+    x-composepack-hook: pre-install
+  succeeded:
+    image: busybox
+    x-composepack-hook: {events: post-install, delete-policy: hook-succeeded}
+  recreate:
+    image: busybox
+    x-composepack-hook: {events: [pre-upgrade], delete-policy: before-hook-creation}
+  both:
+    image: busybox
+    x-composepack-hook: {events: pre-delete, delete-policy: [before-hook-creation, hook-succeeded]}
+  failed:
+    image: busybox
+    x-composepack-hook: {events: pre-delete, delete-policy: hook-failed}
+`
+	main, hookFile, hooks, err := Extract([]byte(compose))
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if strings.Contains(string(main), "busybox") || !strings.Contains(string(hookFile), "busybox") {
+		t.Errorf("hook services were not moved out:\nmain:\n%s\nhooks:\n%s", main, hookFile)
+	}
+
+	want := map[string]struct {
+		policies   []DeletePolicy
+		autoRemove bool
+	}{
+		"plain":     {nil, true},
+		"succeeded": {[]DeletePolicy{HookSucceeded}, false},
+		"recreate":  {[]DeletePolicy{BeforeHookCreation}, false},
+		"both":      {[]DeletePolicy{BeforeHookCreation, HookSucceeded}, false},
+		"failed":    {[]DeletePolicy{HookFailed}, false},
+	}
+	if len(hooks) != len(want) {
+		t.Fatalf("got %d hooks, want %d", len(hooks), len(want))
+	}
+	for _, hook := range hooks {
+		w := want[hook.Service]
+		if !reflect.DeepEqual(hook.DeletePolicies, w.policies) {
+			t.Errorf("%s: policies = %v, want %v", hook.Service, hook.DeletePolicies, w.policies)
+		}
+		if hook.AutoRemove() != w.autoRemove {
+			t.Errorf("%s: AutoRemove() = %v, want %v", hook.Service, hook.AutoRemove(), w.autoRemove)
+		}
+	}
+}
+
+func TestExtractRejectsUnknownPolicy(t *testing.T) {
+	compose := "services:\n  job:\n    image: x\n    x-composepack-hook: {events: pre-install, delete-policy: never}\n"
+	if _, _, _, err := Extract([]byte(compose)); err == nil || !strings.Contains(err.Error(), `unknown hook delete policy "never"`) {
+		t.Errorf("Extract error = %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package lint
+
+import (
+	_ "embed"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+	"sigs.k8s.io/yaml"
+)
+
+// composeSchema is a hand-maintained subset of the Compose specification schema: it knows
+// every top-level and service key and the shapes of the ones composepack charts commonly use.
+//
+//go:embed compose.schema.json
+var composeSchema []byte
+
+// composePath is the Path reported for issues found in the merged compose file.
+const composePath = "docker-compose.yaml"
+
+// CheckCompose validates a merged compose file against the Compose schema and flags
+// common problems: floating image tags, host port collisions and bind mounts of files/
+// entries the chart never renders.
+func CheckCompose(data []byte, files map[string][]byte) []Issue {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Issue{errorf(composePath, "parse merged compose: %v", err)}
+	}
+
+	issues := validateSchema(doc)
+	services, _ := doc["services"].(map[string]any)
+	ports := portTable{}
+	for _, name := range sortedKeys(services) {
+		svc, ok := services[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		issues = append(issues, checkImage(name, svc)...)
+		issues = append(issues, ports.add(name, svc["ports"])...)
+		issues = append(issues, checkMounts(name, svc["volumes"], files)...)
+	}
+	return issues
+}
+
+func validateSchema(doc map[string]any) []Issue {
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(composeSchema), gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return []Issue{errorf(composePath, "validate against compose schema: %v", err)}
+	}
+	var issues []Issue
+	for _, desc := range result.Errors() {
+		issues = append(issues, errorf(composePath, "%s", desc.String()))
+	}
+	return issues
+}
+
+func checkImage(service string, svc map[string]any) []Issue {
+	image, _ := svc["image"].(string)
+	if image == "" {
+		if _, ok := svc["build"]; !ok {
+			return []Issue{errorf(composePath, "service %s: has neither image nor build", service)}
+		}
+		return nil
+	}
+	if strings.Contains(image, "$") || strings.Contains(image, "@") {
+		return nil
+	}
+	tag := ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		tag = image[i+1:]
+	}
+	switch tag {
+	case "":
+		return []Issue{warnf(composePath, "service %s: image %q has no tag and resolves to :latest; pin a version", service, image)}
+	case "latest":
+		return []Issue{warnf(composePath, "service %s: image %q uses the floating :latest tag; pin a version", service, image)}
+	}
+	return nil
+}
+
+// portBinding is one published host port.
+type portBinding struct {
+	service string
+	hostIP  string
+}
+
+// portTable tracks published host ports by "<port>/<protocol>" to find collisions.
+type portTable map[string][]portBinding
+
+func (t portTable) add(service string, raw any) []Issue {
+	entries, _ := raw.([]any)
+	var issues []Issue
+	for _, entry := range entries {
+		hostIP, published, protocol, err := parsePort(entry)
+		if err != nil {
+			issues = append(issues, errorf(composePath, "service %s: %v", service, err))
+			continue
+		}
+		for _, port := range published {
+			key := fmt.Sprintf("%d/%s", port, protocol)
+			for _, other := range t[key] {
+				if other.hostIP == "" || hostIP == "" || other.hostIP == hostIP {
+					issues = append(issues, errorf(composePath, "service %s: host port %s is already published by service %s", service, key, other.service))
+					break
+				}
+			}
+			t[key] = append(t[key], portBinding{service: service, hostIP: hostIP})
+		}
+	}
+	return issues
+}
+
+// parsePort returns the host IP, published host ports and protocol of a ports entry in
+// short ("[ip:]host:container[/proto]") or long syntax. Container-only entries publish nothing.
+func parsePort(entry any) (string, []int, string, error) {
+	switch typed := entry.(type) {
+	case map[string]any:
+		protocol, _ := typed["protocol"].(string)
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		hostIP, _ := typed["host_ip"].(string)
+		published := fmt.Sprint(typed["published"])
+		if typed["published"] == nil || published == "" {
+			return hostIP, nil, protocol, nil
+		}
+		ports, err := parsePortRange(published)
+		return hostIP, ports, protocol, err
+	case string:
+		spec, protocol := typed, "tcp"
+		if i := strings.LastIndex(spec, "/"); i >= 0 {
+			spec, protocol = spec[:i], spec[i+1:]
+		}
+		i := strings.LastIndex(spec, ":")
+		if i < 0 || strings.Contains(spec, "$") {
+			return "", nil, protocol, nil
+		}
+		host, hostIP := spec[:i], ""
+		if j := strings.LastIndex(host, ":"); j >= 0 {
+			host, hostIP = host[j+1:], strings.Trim(host[:j], "[]")
+		}
+		if host == "" {
+			return hostIP, nil, protocol, nil
+		}
+		ports, err := parsePortRange(host)
+		return hostIP, ports, protocol, err
+	default:
+		return "", nil, "tcp", nil
+	}
+}
+
+func parsePortRange(spec string) ([]int, error) {
+	lo, hi, isRange := strings.Cut(spec, "-")
+	start, err := strconv.Atoi(lo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host port %q", spec)
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(hi); err != nil {
+			return nil, fmt.Errorf("invalid host port range %q", spec)
+		}
+	}
+	if start < 1 || end > 65535 || end < start {
+		return nil, fmt.Errorf("host port %q is out of range", spec)
+	}
+	ports := make([]int, 0, end-start+1)
+	for p := start; p <= end; p++ {
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// checkMounts reports bind mounts of ./files/... paths that are not part of the rendered files.
+func checkMounts(service string, raw any, files map[string][]byte) []Issue {
+	entries, _ := raw.([]any)
+	var issues []Issue
+	for _, entry := range entries {
+		var source string
+		switch typed := entry.(type) {
+		case string:
+			source, _, _ = strings.Cut(typed, ":")
+		case map[string]any:
+			if kind, _ := typed["type"].(string); kind == "bind" {
+				source, _ = typed["source"].(string)
+			}
+		}
+		rel, ok := filesPath(source)
+		if ok && !hasFile(files, rel) {
+			issues = append(issues, errorf(composePath, "service %s: volume source %s is not rendered by the chart (no files/%s)", service, source, rel))
+		}
+	}
+	return issues
+}
+
+// filesPath maps a relative bind source under files/ to its path inside the files tree.
+func filesPath(source string) (string, bool) {
+	if source == "" || path.IsAbs(source) || strings.Contains(source, "$") {
+		return "", false
+	}
+	cleaned := path.Clean(source)
+	if cleaned == "files" {
+		return "", true
+	}
+	rel, ok := strings.CutPrefix(cleaned, "files/")
+	return rel, ok
+}
+
+func hasFile(files map[string][]byte, rel string) bool {
+	if rel == "" {
+		return len(files) > 0
+	}
+	if _, ok := files[rel]; ok {
+		return true
+	}
+	for name := range files {
+		if strings.HasPrefix(name, rel+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParsePort(t *testing.T) {
+	tests := []struct {
+		entry    any
+		hostIP   string
+		ports    []int
+		protocol string
+		err      string
+	}{
+		{entry: "8080:80", ports: []int{8080}, protocol: "tcp"},
+		{entry: "127.0.0.1:5353:53/udp", hostIP: "127.0.0.1", ports: []int{5353}, protocol: "udp"},
+		{entry: "[::1]:8080:80", hostIP: "::1", ports: []int{8080}, protocol: "tcp"},
+		{entry: "9000-9002:9000-9002", ports: []int{9000, 9001, 9002}, protocol: "tcp"},
+		{entry: "127.0.0.1::80", hostIP: "127.0.0.1", protocol: "tcp"},
+		{entry: "80", protocol: "tcp"},
+		{entry: "80/udp", protocol: "udp"},
+		{entry: "${WEB_PORT}:80", protocol: "tcp"},
+		{entry: "${WEB_PORT:-8080}:80/udp", protocol: "udp"},
+		{entry: "http:80", protocol: "tcp", err: `invalid host port "http"`},
+		{entry: "70000:80", protocol: "tcp", err: `host port "70000" is out of range`},
+		{entry: map[string]any{"target": 80, "published": "8080"}, ports: []int{8080}, protocol: "tcp"},
+		{entry: map[string]any{"target": 53, "published": float64(5353), "protocol": "udp", "host_ip": "10.0.0.1"}, hostIP: "10.0.0.1", ports: []int{5353}, protocol: "udp"},
+		{entry: map[string]any{"target": 80}, protocol: "tcp"},
+		{entry: 80, protocol: "tcp"},
+	}
+	for _, tt := range tests {
+		hostIP, ports, protocol, err := parsePort(tt.entry)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parsePort(%v) error = %v, want %q", tt.entry, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || hostIP != tt.hostIP || !reflect.DeepEqual(ports, tt.ports) || protocol != tt.protocol {
+			t.Errorf("parsePort(%v) = %q, %v, %q, %v; want %q, %v, %q", tt.entry, hostIP, ports, protocol, err, tt.hostIP, tt.ports, tt.protocol)
+		}
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	tests := map[string]struct {
+		ports []int
+		err   string
+	}{
+		"8080":      {ports: []int{8080}},
+		"1-3":       {ports: []int{1, 2, 3}},
+		"65535":     {ports: []int{65535}},
+		"0":         {err: "out of range"},
+		"65536":     {err: "out of range"},
+		"10-5":      {err: "out of range"},
+		"10-":       {err: "invalid host port range"},
+		"abc":       {err: "invalid host port"},
+		"8080-8080": {ports: []int{8080}},
+	}
+	for spec, tt := range tests {
+		ports, err := parsePortRange(spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parsePortRange(%q) error = %v, want %q", spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(ports, tt.ports) {
+			t.Errorf("parsePortRange(%q) = %v, %v; want %v", spec, ports, err, tt.ports)
+		}
+	}
+}
+
+func TestFilesPath(t *testing.T) {
+	tests := map[string]struct {
+		rel string
+		ok  bool
+	}{
+		"./files/nginx.conf":       {"nginx.conf", true},
+		"files/conf/app.ini":       {"conf/app.ini", true},
+		"./files":                  {"", true},
+		"./files/../files/site":    {"site", true},
+		"./files/../secrets/key":   {"", false},
+		"./filesystem/x":           {"", false},
+		"/etc/nginx/nginx.conf":    {"", false},
+		"${CONFIG_DIR}/nginx.conf": {"", false},
+		"data":                     {"", false},
+		"":                         {"", false},
+	}
+	for source, tt := range tests {
+		// the path only means something when the source is under files/
+		if rel, ok := filesPath(source); ok != tt.ok || (ok && rel != tt.rel) {
+			t.Errorf("filesPath(%q) = %q, %v; want %q, %v", source, rel, ok, tt.rel, tt.ok)
+		}
+	}
+}
+
+func TestHasFile(t *testing.T) {
+	files := map[string][]byte{"nginx.conf": nil, "conf.d/default.conf": nil}
+	tests := map[string]bool{
+		"nginx.conf":          true,
+		"conf.d":              true,
+		"conf.d/default.conf": true,
+		"conf":                false,
+		"conf.d/other.conf":   false,
+		"":                    true,
+	}
+	for rel, want := range tests {
+		if got := hasFile(files, rel); got != want {
+			t.Errorf("hasFile(%q) = %v, want %v", rel, got, want)
+		}
+	}
+	if hasFile(nil, "") {
+		t.Error(`hasFile(nil, "") = true`)
+	}
+}
+
+func TestCheckImage(t *testing.T) {
+	tests := map[string]string{
+		"nginx:1.25":                      "",
+		"nginx":                           "has no tag",
+		"nginx:latest":                    "floating :latest",
+		"registry.local:5000/team/app":    "has no tag",
+		"registry.local:5000/team/app:v2": "",
+		"nginx@sha256:0123456789abcdef":   "",
+		"nginx:1.25@sha256:0123456789abc": "",
+		"${IMAGE}":                        "",
+		"${REGISTRY}/app:${TAG:-latest}":  "",
+	}
+	for image, want := range tests {
+		issues := checkImage("web", map[string]any{"image": image})
+		switch {
+		case want == "" && len(issues) != 0:
+			t.Errorf("checkImage(%q) = %v, want no issues", image, issues)
+		case want != "" && (len(issues) != 1 || issues[0].Severity != SeverityWarning || !strings.Contains(issues[0].Message, want)):
+			t.Errorf("checkImage(%q) = %v, want a warning about %q", image, issues, want)
+		}
+	}
+
+	if issues := checkImage("web", map[string]any{"build": "."}); len(issues) != 0 {
+		t.Errorf("build-only service: %v", issues)
+	}
+	if issues := checkImage("web", map[string]any{}); len(issues) != 1 || issues[0].Severity != SeverityError {
+		t.Errorf("service without image or build: %v", issues)
+	}
+}
+
+func TestCheckComposePorts(t *testing.T) {
+	compose := `
+services:
+  a:
+    image: a:1
+    ports: ["8080:80", "127.0.0.1:9000:9000", "5353:53/udp"]
+  b:
+    image: b:1
+    ports: ["10.0.0.1:9000:9000", "5353:53/tcp", "8079-8081:80-82"]
+`
+	var collisions []string
+	for _, issue := range CheckCompose([]byte(compose), nil) {
+		collisions = append(collisions, issue.Message)
+	}
+	want := []string{"service b: host port 8080/tcp is already published by service a"}
+	if !reflect.DeepEqual(collisions, want) {
+		t.Errorf("issues = %q, want %q", collisions, want)
+	}
+}
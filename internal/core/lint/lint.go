@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"composepack/internal/core/chart"
+)
+
+// Severity ranks an issue; errors fail lint, warnings only fail it in strict mode.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single lint finding.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	// Path is the chart file or compose location the issue refers to.
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Path == "" {
+		return fmt.Sprintf("[%s] %s", strings.ToUpper(string(i.Severity)), i.Message)
+	}
+	return fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(i.Severity)), i.Path, i.Message)
+}
+
+// Report collects the issues found in one chart.
+type Report struct {
+	Chart   string  `json:"chart"`
+	Version string  `json:"version,omitempty"`
+	Issues  []Issue `json:"issues"`
+}
+
+// Add records issues, dropping exact duplicates (the same problem found by several renders).
+func (r *Report) Add(issues ...Issue) {
+	for _, issue := range issues {
+		if !r.Has(issue) {
+			r.Issues = append(r.Issues, issue)
+		}
+	}
+}
+
+// Has reports whether issue was already recorded.
+func (r *Report) Has(issue Issue) bool {
+	for _, existing := range r.Issues {
+		if existing == issue {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns how many issues have the given severity.
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed reports whether the chart fails lint; strict also fails on warnings.
+func (r *Report) Failed(strict bool) bool {
+	return r.Count(SeverityError) > 0 || (strict && r.Count(SeverityWarning) > 0)
+}
+
+func errorf(path, format string, args ...any) Issue {
+	return Issue{Severity: SeverityError, Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+func warnf(path, format string, args ...any) Issue {
+	return Issue{Severity: SeverityWarning, Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+var chartNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// CheckMetadata validates Chart.yaml: name format, semver version and dependency declarations.
+func CheckMetadata(meta chart.ChartMetadata) []Issue {
+	var issues []Issue
+	if !chartNamePattern.MatchString(meta.Name) {
+		issues = append(issues, errorf(chart.MetadataFile, "name %q must be lowercase letters, digits and dashes, starting and ending with a letter or digit", meta.Name))
+	}
+	if _, err := semver.StrictNewVersion(meta.Version); err != nil {
+		issues = append(issues, errorf(chart.MetadataFile, "version %q is not a valid semantic version (MAJOR.MINOR.PATCH)", meta.Version))
+	}
+	if meta.Description == "" {
+		issues = append(issues, warnf(chart.MetadataFile, "description is empty"))
+	}
+	for _, dep := range meta.Dependencies {
+		if dep.Version == "" {
+			issues = append(issues, errorf(chart.MetadataFile, "dependency %s has no version", dep.Name))
+		} else if _, err := semver.NewConstraint(dep.Version); err != nil {
+			issues = append(issues, errorf(chart.MetadataFile, "dependency %s version %q is not a version or semver range", dep.Name, dep.Version))
+		}
+	}
+	return issues
+}
+
+var (
+	definePattern = regexp.MustCompile(`\{\{-?\s*define\s+"([^"]+)"`)
+	usePattern    = regexp.MustCompile(`\b(?:include|template)\s+"([^"]+)"`)
+)
+
+// CheckHelpers warns about templates defined under templates/helpers/ that nothing includes.
+// Only literal names are tracked, so helpers reached through a computed include name are
+// reported too.
+func CheckHelpers(ch *chart.Chart, prefix string) []Issue {
+	used := map[string]bool{}
+	for _, set := range []map[string]string{ch.ComposeTpls, ch.FileTemplates, ch.HelperTpls, {chart.NotesFile: ch.NotesTpl}} {
+		for _, body := range set {
+			for _, match := range usePattern.FindAllStringSubmatch(body, -1) {
+				used[match[1]] = true
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, file := range sortedKeys(ch.HelperTpls) {
+		for _, match := range definePattern.FindAllStringSubmatch(ch.HelperTpls[file], -1) {
+			if !used[match[1]] {
+				issues = append(issues, warnf(prefix+chart.TemplatesHelpers+"/"+file, "helper %q is never included", match[1]))
+			}
+		}
+	}
+	for _, sc := range ch.Subcharts {
+		issues = append(issues, CheckHelpers(sc.Chart, prefix+chart.ChartsDir+"/"+sc.Dependency.Key()+"/")...)
+	}
+	return issues
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
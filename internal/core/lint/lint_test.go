@@ -0,0 +1,37 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	"composepack/internal/core/chart"
+)
+
+func TestCheckHelpers(t *testing.T) {
+	sub := &chart.Chart{
+		HelperTpls: map[string]string{"db.tpl": `{{ define "db.unused" }}x{{ end }}`},
+	}
+	ch := &chart.Chart{
+		ComposeTpls: map[string]string{"app.tpl.yaml": `image: {{ include "app.image" . }}`},
+		FileTemplates: map[string]string{
+			"conf/app.conf.tpl": `{{ template "app.conf" . }}`,
+		},
+		HelperTpls: map[string]string{
+			"_names.tpl": `{{- define "app.image" }}{{ include "app.registry" . }}/app{{ end }}
+{{ define "app.registry" }}registry.local{{ end }}
+{{ define "app.conf" }}{{ end }}
+{{ define "app.notes" }}{{ end }}`,
+			"unused.tpl": `{{- define "app.legacy" -}}old{{- end }}`,
+		},
+		NotesTpl:  `{{ include "app.notes" . }}`,
+		Subcharts: []*chart.Subchart{{Dependency: chart.Dependency{Name: "postgres", Alias: "db"}, Chart: sub}},
+	}
+
+	want := []Issue{
+		warnf("templates/helpers/unused.tpl", `helper "app.legacy" is never included`),
+		warnf("charts/db/templates/helpers/db.tpl", `helper "db.unused" is never included`),
+	}
+	if got := CheckHelpers(ch, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckHelpers() = %v, want %v", got, want)
+	}
+}
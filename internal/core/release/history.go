@@ -0,0 +1,385 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/hooks"
+	"composepack/internal/core/values"
+	"composepack/internal/util/fsutil"
+)
+
+const (
+	revisionsDirName   = "revisions"
+	revisionCompose    = "docker-compose.yaml"
+	revisionFilesDir   = "files"
+	revisionValuesFile = "values.yaml"
+	revisionNotesFile  = "NOTES.txt"
+	revisionUserValues = "user-values.yaml"
+	revisionProvenance = "values.provenance.yaml"
+	revisionChart      = "chart.cpack.tgz"
+)
+
+// Revision is a full snapshot of one rendered release.
+type Revision struct {
+	Metadata    *Metadata
+	ComposeYAML []byte
+	HooksYAML   []byte
+	Files       map[string][]byte
+	Values      map[string]any
+	// UserValues holds only what the user supplied (values files and --set flags), in order,
+	// which upgrades replay; nil for revisions recorded without it.
+	UserValues values.Layers
+	// Notes is the rendered templates/NOTES.txt, empty when the chart has none.
+	Notes []byte
+	// Provenance explains where each value came from; nil for revisions recorded without it.
+	Provenance []values.Explanation
+	// Chart is the chart archive the revision was rendered from; nil for revisions recorded
+	// without it.
+	Chart []byte
+}
+
+// History stores numbered revisions under `<runtime>/revisions/<n>/`.
+type History struct{}
+
+// NextRevision returns the number the next recorded revision should use.
+func (h *History) NextRevision(ctx context.Context, runtimePath string) (int, error) {
+	numbers, err := h.revisionNumbers(ctx, runtimePath)
+	if err != nil {
+		return 0, err
+	}
+	if len(numbers) == 0 {
+		return 1, nil
+	}
+	return numbers[len(numbers)-1] + 1, nil
+}
+
+// Record snapshots a revision, supersedes older live revisions and prunes beyond maxHistory (0 keeps all).
+func (h *History) Record(ctx context.Context, runtimePath string, rev *Revision, maxHistory int) error {
+	if rev == nil || rev.Metadata == nil {
+		return errors.New("revision metadata must be provided")
+	}
+	if rev.Metadata.Revision <= 0 {
+		return errors.New("revision number must be positive")
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	dir := h.revisionDir(runtimePath, rev.Metadata.Revision)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("clean revision %d: %w", rev.Metadata.Revision, err)
+	}
+	if err := fsutil.EnsureDir(dir); err != nil {
+		return fmt.Errorf("ensure revision dir: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(ctx, filepath.Join(dir, revisionCompose), rev.ComposeYAML, 0o644); err != nil {
+		return fmt.Errorf("write revision compose file: %w", err)
+	}
+	if len(rev.HooksYAML) > 0 {
+		if err := fsutil.WriteFileAtomic(ctx, filepath.Join(dir, hooks.FileName), rev.HooksYAML, 0o644); err != nil {
+			return fmt.Errorf("write revision hooks file: %w", err)
+		}
+	}
+	for rel, data := range rev.Files {
+		dest := filepath.Join(dir, revisionFilesDir, filepath.FromSlash(rel))
+		if err := fsutil.WriteFileAtomic(ctx, dest, data, 0o644); err != nil {
+			return fmt.Errorf("write revision file %s: %w", rel, err)
+		}
+	}
+	// values may carry secrets, keep them owner-readable only
+	if rev.Values != nil {
+		if err := writeSecretYAML(ctx, filepath.Join(dir, revisionValuesFile), rev.Values); err != nil {
+			return fmt.Errorf("write revision values: %w", err)
+		}
+	}
+	if rev.UserValues != nil {
+		if err := writeSecretYAML(ctx, filepath.Join(dir, revisionUserValues), rev.UserValues); err != nil {
+			return fmt.Errorf("write revision user values: %w", err)
+		}
+	}
+	if rev.Provenance != nil {
+		if err := writeSecretYAML(ctx, filepath.Join(dir, revisionProvenance), rev.Provenance); err != nil {
+			return fmt.Errorf("write values provenance: %w", err)
+		}
+	}
+	if len(rev.Notes) > 0 {
+		// notes often print generated credentials
+		if err := fsutil.WriteFileAtomic(ctx, filepath.Join(dir, revisionNotesFile), rev.Notes, 0o600); err != nil {
+			return fmt.Errorf("write revision notes: %w", err)
+		}
+	}
+	if len(rev.Chart) > 0 {
+		// the chart carries its default values, which can hold secrets like the files above
+		if err := fsutil.WriteFileAtomic(ctx, filepath.Join(dir, revisionChart), rev.Chart, 0o600); err != nil {
+			return fmt.Errorf("write revision chart: %w", err)
+		}
+	}
+	if err := writeMetadata(dir, rev.Metadata); err != nil {
+		return err
+	}
+
+	if err := h.supersede(ctx, runtimePath, rev.Metadata.Revision); err != nil {
+		return err
+	}
+	return h.Prune(ctx, runtimePath, maxHistory)
+}
+
+// SaveMetadata rewrites the metadata of an already recorded revision (e.g. after a status change).
+func (h *History) SaveMetadata(ctx context.Context, runtimePath string, meta *Metadata) error {
+	if meta == nil {
+		return errors.New("metadata must be provided")
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	dir := h.revisionDir(runtimePath, meta.Revision)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("revision %d not found: %w", meta.Revision, err)
+	}
+	return writeMetadata(dir, meta)
+}
+
+// List returns the metadata of every stored revision ordered by revision number.
+func (h *History) List(ctx context.Context, runtimePath string) ([]*Metadata, error) {
+	numbers, err := h.revisionNumbers(ctx, runtimePath)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*Metadata, 0, len(numbers))
+	for _, n := range numbers {
+		meta, err := readMetadata(h.revisionDir(runtimePath, n))
+		if err != nil {
+			return nil, fmt.Errorf("revision %d: %w", n, err)
+		}
+		if meta == nil {
+			continue
+		}
+		out = append(out, meta)
+	}
+	return out, nil
+}
+
+// Get loads a complete revision snapshot.
+func (h *History) Get(ctx context.Context, runtimePath string, revision int) (*Revision, error) {
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	dir := h.revisionDir(runtimePath, revision)
+	meta, err := readMetadata(dir)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("revision %d not found", revision)
+	}
+
+	compose, err := os.ReadFile(filepath.Join(dir, revisionCompose))
+	if err != nil {
+		return nil, fmt.Errorf("read revision compose file: %w", err)
+	}
+	hooksYAML, err := os.ReadFile(filepath.Join(dir, hooks.FileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read revision hooks file: %w", err)
+	}
+
+	files := map[string][]byte{}
+	filesRoot := filepath.Join(dir, revisionFilesDir)
+	err = filepath.WalkDir(filesRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == filesRoot {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(filesRoot, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read revision files: %w", err)
+	}
+
+	var vals map[string]any
+	if err := readOptionalYAML(filepath.Join(dir, revisionValuesFile), &vals); err != nil {
+		return nil, fmt.Errorf("revision values: %w", err)
+	}
+	meta.Values = vals
+	userVals, err := readUserValues(filepath.Join(dir, revisionUserValues))
+	if err != nil {
+		return nil, fmt.Errorf("revision user values: %w", err)
+	}
+
+	notes, err := os.ReadFile(filepath.Join(dir, revisionNotesFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read revision notes: %w", err)
+	}
+
+	var provenance []values.Explanation
+	if err := readOptionalYAML(filepath.Join(dir, revisionProvenance), &provenance); err != nil {
+		return nil, fmt.Errorf("values provenance: %w", err)
+	}
+
+	chartArchive, err := os.ReadFile(filepath.Join(dir, revisionChart))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read revision chart: %w", err)
+	}
+
+	return &Revision{
+		Metadata:    meta,
+		ComposeYAML: compose,
+		HooksYAML:   hooksYAML,
+		Files:       files,
+		Values:      vals,
+		UserValues:  userVals,
+		Notes:       notes,
+		Provenance:  provenance,
+		Chart:       chartArchive,
+	}, nil
+}
+
+// Prune deletes the oldest revisions so at most max remain; max <= 0 disables pruning.
+func (h *History) Prune(ctx context.Context, runtimePath string, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	numbers, err := h.revisionNumbers(ctx, runtimePath)
+	if err != nil {
+		return err
+	}
+	for len(numbers) > max {
+		if err := os.RemoveAll(h.revisionDir(runtimePath, numbers[0])); err != nil {
+			return fmt.Errorf("prune revision %d: %w", numbers[0], err)
+		}
+		numbers = numbers[1:]
+	}
+	return nil
+}
+
+// writeSecretYAML writes v as an owner-readable YAML file.
+func writeSecretYAML(ctx context.Context, path string, v any) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("serialize: %w", err)
+	}
+	return fsutil.WriteFileAtomic(ctx, path, data, 0o600)
+}
+
+// readUserValues reads user-values.yaml, which early revisions stored as one merged map.
+func readUserValues(path string) (values.Layers, error) {
+	var layers values.Layers
+	if err := readOptionalYAML(path, &layers); err == nil {
+		return layers, nil
+	}
+	var merged map[string]any
+	if err := readOptionalYAML(path, &merged); err != nil {
+		return nil, err
+	}
+	return values.Layers{{Values: merged}}, nil
+}
+
+// readOptionalYAML parses path into out, leaving out untouched when the file is missing or empty.
+func readOptionalYAML(path string, out any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("parse: %w", err)
+	}
+	return nil
+}
+
+func (h *History) supersede(ctx context.Context, runtimePath string, current int) error {
+	revisions, err := h.List(ctx, runtimePath)
+	if err != nil {
+		return err
+	}
+	for _, meta := range revisions {
+		if meta.Revision >= current {
+			continue
+		}
+		if meta.Status != StatusDeployed && meta.Status != StatusRendered {
+			continue
+		}
+		meta.Status = StatusSuperseded
+		if err := writeMetadata(h.revisionDir(runtimePath, meta.Revision), meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *History) revisionNumbers(ctx context.Context, runtimePath string) ([]int, error) {
+	if runtimePath == "" {
+		return nil, errors.New("runtime path is required")
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(runtimePath, revisionsDirName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read revisions: %w", err)
+	}
+
+	var numbers []int
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		n, err := strconv.Atoi(entry.Name())
+		if err != nil || n <= 0 {
+			continue
+		}
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	return numbers, nil
+}
+
+// ChartPath returns where the chart archive of a revision is stored.
+func (h *History) ChartPath(runtimePath string, revision int) string {
+	return filepath.Join(h.revisionDir(runtimePath, revision), revisionChart)
+}
+
+func (h *History) revisionDir(runtimePath string, revision int) string {
+	return filepath.Join(runtimePath, revisionsDirName, strconv.Itoa(revision))
+}
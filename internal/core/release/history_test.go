@@ -0,0 +1,70 @@
+package release
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryRecordSupersedesAndPrunes(t *testing.T) {
+	h := &History{}
+	ctx := context.Background()
+	dir := t.TempDir()
+	record := func(revision int, status Status, max int) {
+		t.Helper()
+		err := h.Record(ctx, dir, &Revision{
+			Metadata:    &Metadata{ReleaseName: "demo", Revision: revision, Status: status},
+			ComposeYAML: []byte("services: {}\n"),
+			Files:       map[string][]byte{"conf/app.conf": []byte("revision")},
+		}, max)
+		if err != nil {
+			t.Fatalf("Record(%d): %v", revision, err)
+		}
+	}
+	statuses := func() map[int]Status {
+		t.Helper()
+		revisions, err := h.List(ctx, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := map[int]Status{}
+		for _, meta := range revisions {
+			out[meta.Revision] = meta.Status
+		}
+		return out
+	}
+
+	record(1, StatusDeployed, 0)
+	record(2, StatusFailed, 0)
+	record(3, StatusRendered, 0)
+	want := map[int]Status{1: StatusSuperseded, 2: StatusFailed, 3: StatusRendered}
+	if got := statuses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after three revisions: %v, want %v", got, want)
+	}
+
+	record(4, StatusDeployed, 2)
+	want = map[int]Status{3: StatusSuperseded, 4: StatusDeployed}
+	if got := statuses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after pruning to 2: %v, want %v", got, want)
+	}
+	if next, err := h.NextRevision(ctx, dir); err != nil || next != 5 {
+		t.Errorf("NextRevision = %d, %v", next, err)
+	}
+	if _, err := h.Get(ctx, dir, 1); err == nil {
+		t.Error("pruned revision 1 is still readable")
+	}
+	rev, err := h.Get(ctx, dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(rev.Files["conf/app.conf"]) != "revision" || string(rev.ComposeYAML) != "services: {}\n" {
+		t.Errorf("revision 3 = %+v", rev)
+	}
+
+	if err := h.Prune(ctx, dir, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := statuses(); len(got) != 1 || got[4] != StatusDeployed {
+		t.Errorf("after Prune(1): %v", got)
+	}
+}
@@ -1,7 +1,6 @@
 package release
 
 import (
-	"composepack/internal/core/chart"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -11,21 +10,80 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/core/hooks"
 )
 
 const metadataFileName = "release.json"
 
-// Metadata captures release.json contents in runtime directories.
+// Status describes the lifecycle state of a release revision.
+type Status string
+
+// Known revision states.
+const (
+	StatusRendered    Status = "rendered"
+	StatusDeployed    Status = "deployed"
+	StatusFailed      Status = "failed"
+	StatusSuperseded  Status = "superseded"
+	StatusUninstalled Status = "uninstalled"
+)
+
+// Metadata captures release.json contents in runtime directories. ChartDigest fingerprints the
+// chart's contents (chart.ContentDigest) and RenderedDigest the rendered output (RenderedDigest),
+// so equal digests mean the same chart build and the same deployed files.
 type Metadata struct {
-	ReleaseName   string              `json:"releaseName"`
-	ChartMetadata chart.ChartMetadata `json:"chartMetadata"`
-	ChartDigest   string              `json:"chartDigest"`
-	RuntimePath   string              `json:"runtimePath"`
-	CreatedAt     time.Time           `json:"createdAt"`
-	Values        map[string]any      `json:"values,omitempty"`
-	ValuesSources []string            `json:"valuesSources"`
-	ComposeFiles  []string            `json:"composeFiles"`
+	ReleaseName    string              `json:"releaseName"`
+	ChartMetadata  chart.ChartMetadata `json:"chartMetadata"`
+	ChartDigest    string              `json:"chartDigest"`
+	ChartSource    *ChartSource        `json:"chartSource,omitempty"`
+	RenderedDigest string              `json:"renderedDigest,omitempty"`
+	RuntimePath    string              `json:"runtimePath"`
+	CreatedAt      time.Time           `json:"createdAt"`
+	// FirstCreatedAt is the CreatedAt of the release's first revision, carried forward by every
+	// later one so it outlives pruning.
+	FirstCreatedAt time.Time      `json:"firstCreatedAt,omitempty"`
+	Revision       int            `json:"revision"`
+	Status         Status         `json:"status,omitempty"`
+	Description    string         `json:"description,omitempty"`
+	Values         map[string]any `json:"values,omitempty"`
+	ValuesSources  []string       `json:"valuesSources"`
+	ComposeFiles   []string       `json:"composeFiles"`
+	Readiness      *Readiness     `json:"readiness,omitempty"`
+	Hooks          []HookRun      `json:"hooks,omitempty"`
+}
+
+// ChartSource records where a release's chart was loaded from, so later upgrades can load it
+// again without --chart.
+type ChartSource struct {
+	// Ref is an absolute path, a URL, an `oci://` reference or a `repo/chart` reference.
+	Ref string `json:"ref"`
+	// Version pins `repo/chart` references to the version that was installed.
+	Version string `json:"version,omitempty"`
+	// Digest is the sha256 of the chart archive; empty for chart directories.
+	Digest string `json:"digest,omitempty"`
+}
+
+// HookRun records one execution of a lifecycle hook.
+type HookRun struct {
+	Service   string    `json:"service"`
+	Event     string    `json:"event"`
+	Succeeded bool      `json:"succeeded"`
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Readiness records the outcome of waiting for services after `--wait`.
+type Readiness struct {
+	Ready   bool   `json:"ready"`
+	Timeout string `json:"timeout"`
+	Elapsed string `json:"elapsed"`
+	Message string `json:"message,omitempty"`
+	// Services maps each service to its last observed state.
+	Services map[string]string `json:"services,omitempty"`
 }
 
 // Store persists release metadata inside runtime directories.
@@ -42,20 +100,7 @@ func (s *Store) Load(ctx context.Context, runtimePath string) (*Metadata, error)
 		}
 	}
 
-	path := filepath.Join(runtimePath, metadataFileName)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("read release metadata: %w", err)
-	}
-
-	var meta Metadata
-	if err := json.Unmarshal(data, &meta); err != nil {
-		return nil, fmt.Errorf("parse release metadata: %w", err)
-	}
-	return &meta, nil
+	return readMetadata(runtimePath)
 }
 
 // Save writes release metadata to `<runtime>/release.json`.
@@ -76,12 +121,19 @@ func (s *Store) Save(ctx context.Context, runtimePath string, meta *Metadata) er
 	if meta.CreatedAt.IsZero() {
 		meta.CreatedAt = time.Now().UTC()
 	}
-	meta.Digest()
+	if meta.FirstCreatedAt.IsZero() {
+		meta.FirstCreatedAt = meta.CreatedAt
+	}
 
 	if err := os.MkdirAll(runtimePath, 0o755); err != nil {
 		return fmt.Errorf("ensure runtime directory: %w", err)
 	}
 
+	return writeMetadata(runtimePath, meta)
+}
+
+// writeMetadata serializes meta into `<dir>/release.json` via temp file + rename.
+func writeMetadata(dir string, meta *Metadata) error {
 	// hide confidential fields from the metadata
 	val := meta.Values
 	meta.Values = nil
@@ -94,29 +146,53 @@ func (s *Store) Save(ctx context.Context, runtimePath string, meta *Metadata) er
 		return fmt.Errorf("serialize metadata: %w", err)
 	}
 
-	tempPath := filepath.Join(runtimePath, ".release.json.tmp")
+	tempPath := filepath.Join(dir, ".release.json.tmp")
 	if err := os.WriteFile(tempPath, data, 0o644); err != nil {
 		return fmt.Errorf("write temp metadata: %w", err)
 	}
-	if err := os.Rename(tempPath, filepath.Join(runtimePath, metadataFileName)); err != nil {
+	if err := os.Rename(tempPath, filepath.Join(dir, metadataFileName)); err != nil {
 		return fmt.Errorf("rename metadata file: %w", err)
 	}
 
 	return nil
 }
 
-func (m *Metadata) Digest() {
-	fieldsToBeHashed := []string{
-		m.ReleaseName,
-		m.ChartMetadata.Name,
-		m.ChartMetadata.Version,
-		m.ChartMetadata.Description,
-		m.CreatedAt.UTC().Format(time.RFC3339),
+func readMetadata(dir string) (*Metadata, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metadataFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read release metadata: %w", err)
 	}
+
+	var meta Metadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parse release metadata: %w", err)
+	}
+	return &meta, nil
+}
+
+// RenderedDigest fingerprints a rendered release: the compose file, the hooks file and every
+// files/ entry in sorted order.
+func RenderedDigest(composeYAML, hooksYAML []byte, files map[string][]byte) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
 	hash := sha256.New()
-	for _, field := range fieldsToBeHashed {
-		hash.Write([]byte(field))
-		hash.Write([]byte{0})
+	for _, part := range []struct {
+		name string
+		data []byte
+	}{{revisionCompose, composeYAML}, {hooks.FileName, hooksYAML}} {
+		fmt.Fprintf(hash, "%s\x00%d\x00", part.name, len(part.data))
+		hash.Write(part.data)
+	}
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s/%s\x00%d\x00", revisionFilesDir, name, len(files[name]))
+		hash.Write(files[name])
 	}
-	m.ChartDigest = hex.EncodeToString(hash.Sum(nil))
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
 }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
+	"composepack/internal/core/hooks"
 	"composepack/internal/util/fsutil"
 )
 
@@ -24,16 +26,20 @@ type Writer struct{}
 type WriteOptions struct {
 	ReleaseName string
 	BaseDir     string
+	// Dir writes straight into this directory instead of `<BaseDir>/<ReleaseName>`.
+	Dir         string
 	ComposeYAML []byte
-	Files       map[string][]byte
+	// HooksYAML holds hook services; the hooks file is removed when empty.
+	HooksYAML []byte
+	Files     map[string][]byte
 }
 
 // Write commits the rendered artifacts to `.cpack-releases/<release>`.
 func (w *Writer) Write(ctx context.Context, opts WriteOptions) (string, error) {
-	if opts.ReleaseName == "" {
+	if opts.Dir == "" && opts.ReleaseName == "" {
 		return "", errors.New("release name is required")
 	}
-	if opts.BaseDir == "" {
+	if opts.Dir == "" && opts.BaseDir == "" {
 		return "", errors.New("base directory is required")
 	}
 	if len(opts.ComposeYAML) == 0 {
@@ -45,7 +51,10 @@ func (w *Writer) Write(ctx context.Context, opts WriteOptions) (string, error) {
 		}
 	}
 
-	runtimeDir := filepath.Join(opts.BaseDir, opts.ReleaseName)
+	runtimeDir := opts.Dir
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join(opts.BaseDir, opts.ReleaseName)
+	}
 	if err := fsutil.EnsureDir(runtimeDir); err != nil {
 		return "", fmt.Errorf("ensure runtime dir: %w", err)
 	}
@@ -55,6 +64,15 @@ func (w *Writer) Write(ctx context.Context, opts WriteOptions) (string, error) {
 		return "", fmt.Errorf("write compose file: %w", err)
 	}
 
+	hooksPath := filepath.Join(runtimeDir, hooks.FileName)
+	if len(opts.HooksYAML) > 0 {
+		if err := fsutil.WriteFileAtomic(ctx, hooksPath, opts.HooksYAML, 0o644); err != nil {
+			return "", fmt.Errorf("write hooks file: %w", err)
+		}
+	} else if err := os.Remove(hooksPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("remove hooks file: %w", err)
+	}
+
 	filesRoot := filepath.Join(runtimeDir, filesDirName)
 	if err := os.RemoveAll(filesRoot); err != nil {
 		return "", fmt.Errorf("clean files dir: %w", err)
@@ -72,6 +90,66 @@ func (w *Writer) Write(ctx context.Context, opts WriteOptions) (string, error) {
 	return runtimeDir, nil
 }
 
+// Snapshot captures the rendered artifacts currently present in a runtime directory.
+type Snapshot struct {
+	ComposeYAML []byte
+	HooksYAML   []byte
+	Files       map[string][]byte
+}
+
+// Read loads the compose file and `files/` tree of a runtime directory; missing pieces come back empty.
+func (w *Writer) Read(ctx context.Context, runtimeDir string) (*Snapshot, error) {
+	if runtimeDir == "" {
+		return nil, errors.New("runtime directory is required")
+	}
+	if ctx != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	snap := &Snapshot{Files: map[string][]byte{}}
+	compose, err := os.ReadFile(filepath.Join(runtimeDir, composeFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read compose file: %w", err)
+	}
+	snap.ComposeYAML = compose
+
+	hooksYAML, err := os.ReadFile(filepath.Join(runtimeDir, hooks.FileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read hooks file: %w", err)
+	}
+	snap.HooksYAML = hooksYAML
+
+	filesRoot := filepath.Join(runtimeDir, filesDirName)
+	err = filepath.WalkDir(filesRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == filesRoot {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(filesRoot, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		snap.Files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read files dir: %w", err)
+	}
+
+	return snap, nil
+}
+
 func (w *Writer) writeFiles(ctx context.Context, root string, files map[string][]byte) error {
 	keys := make([]string, 0, len(files))
 	for rel := range files {
@@ -99,6 +99,18 @@ func (e *Engine) RenderFiles(ctx context.Context, ch *chart.Chart, rc RenderCont
 	return rendered, nil
 }
 
+// RenderNotes renders templates/NOTES.txt; charts without notes render to nil.
+func (e *Engine) RenderNotes(ctx context.Context, ch *chart.Chart, rc RenderContext) ([]byte, error) {
+	if ch.NotesTpl == "" {
+		return nil, nil
+	}
+	rendered, err := e.renderTemplates(ctx, "notes", map[string]string{chart.NotesFile: ch.NotesTpl}, ch.HelperTpls, rc)
+	if err != nil {
+		return nil, err
+	}
+	return rendered[chart.NotesFile], nil
+}
+
 func (e *Engine) renderTemplates(ctx context.Context, scope string, templates map[string]string, helpers map[string]string, rc RenderContext) (map[string][]byte, error) {
 	if len(templates) == 0 {
 		return map[string][]byte{}, nil
@@ -0,0 +1,117 @@
+package values
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Assignment is one resolved --set style assignment; a nil Value removes the key.
+type Assignment struct {
+	// Path is the key in set syntax, e.g. `svc.ports[0]` or `labels.app\.io`.
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// Layer is one user-supplied values layer: the merged content of values files, or
+// assignments made with --set and friends (set-file contents already read).
+type Layer struct {
+	Values map[string]any `json:"values,omitempty"`
+	Set    []Assignment   `json:"set,omitempty"`
+}
+
+// Layers are user-supplied values in the order they were given. Keeping assignments apart from
+// values files lets an upgrade replay them exactly: list indexes still address the chart's
+// lists and nulls still remove chart defaults.
+type Layers []Layer
+
+// Layer turns every expression into assignments, in the order Apply would set them.
+func (o Overrides) Layer() (Layer, error) {
+	var layer Layer
+	for _, kind := range o.kinds() {
+		for _, expr := range kind.exprs {
+			err := kind.parse(expr, map[string]any{}, func(path []pathElem, val any) {
+				layer.Set = append(layer.Set, Assignment{Path: formatPath(path), Value: deepCopyValue(val)})
+			})
+			if err != nil {
+				return Layer{}, err
+			}
+		}
+	}
+	return layer, nil
+}
+
+// Apply replays every layer over a copy of base: values are merged, assignments set in place.
+func (l Layers) Apply(base map[string]any) (map[string]any, error) {
+	result := deepCopyMap(base)
+	if result == nil {
+		result = map[string]any{}
+	}
+	for _, layer := range l {
+		if layer.Values != nil {
+			mergeMaps(result, layer.Values)
+		}
+		for _, a := range layer.Set {
+			path, err := parsePath(a.Path)
+			if err != nil {
+				return nil, fmt.Errorf("replay %s: %w", a.Path, err)
+			}
+			assign(result, path, deepCopyValue(a.Value))
+		}
+	}
+	return result, nil
+}
+
+// Append adds layers, merging neighbouring values layers and dropping assignments a later
+// assignment to the same key (or a parent of it) overrides, so reused values do not pile up
+// across upgrades.
+func (l Layers) Append(more ...Layer) Layers {
+	out := append(Layers{}, l...)
+	for _, layer := range more {
+		if layer.Values == nil && len(layer.Set) == 0 {
+			continue
+		}
+		if len(out) > 0 {
+			last := &out[len(out)-1]
+			switch {
+			case layer.Values != nil && len(last.Set) == 0 && len(layer.Set) == 0:
+				last.Values, _ = Merge(last.Values, layer.Values)
+				continue
+			case layer.Values == nil && last.Values == nil:
+				last.Set = compactAssignments(append(append([]Assignment{}, last.Set...), layer.Set...))
+				continue
+			}
+		}
+		out = append(out, Layer{Values: deepCopyMap(layer.Values), Set: compactAssignments(layer.Set)})
+	}
+	return out
+}
+
+func compactAssignments(set []Assignment) []Assignment {
+	var out []Assignment
+	for i, a := range set {
+		overridden := false
+		for _, later := range set[i+1:] {
+			if later.Path == a.Path || strings.HasPrefix(a.Path, later.Path+".") || strings.HasPrefix(a.Path, later.Path+"[") {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// parsePath reads a key in set syntax, as produced by formatPath.
+func parsePath(key string) ([]pathElem, error) {
+	p := &setParser{in: []rune(key + "=")}
+	path, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.in) {
+		return nil, fmt.Errorf("invalid key %q", key)
+	}
+	return path, nil
+}
@@ -0,0 +1,82 @@
+package values
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestLayersReplay(t *testing.T) {
+	defaults := map[string]any{
+		"foo":   "default",
+		"list":  []any{"a", "b", "c"},
+		"image": map[string]any{"repo": "nginx", "tag": "1"},
+	}
+	overrides, err := Overrides{Values: []string{"foo=null,list[1]=x"}}.Layer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := Layers{}.Append(Layer{Values: map[string]any{"image": map[string]any{"tag": "2"}}}, overrides)
+
+	// layers survive the round trip through user-values.yaml
+	data, err := yaml.Marshal(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stored Layers
+	if err := yaml.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{
+		"list":  []any{"a", "x", "c"},
+		"image": map[string]any{"repo": "nginx", "tag": "2"},
+	}
+	for name, layers := range map[string]Layers{"fresh": user, "stored": stored} {
+		got, err := layers.Apply(defaults)
+		if err != nil {
+			t.Fatalf("%s: Apply: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: Apply() = %#v, want %#v", name, got, want)
+		}
+	}
+	if _, ok := defaults["list"].([]any)[1].(string); !ok || defaults["foo"] != "default" {
+		t.Errorf("Apply modified the defaults: %#v", defaults)
+	}
+}
+
+func TestLayersAppend(t *testing.T) {
+	set := func(exprs ...string) Layer {
+		layer, err := Overrides{Values: exprs}.Layer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return layer
+	}
+
+	layers := Layers{}.
+		Append(Layer{Values: map[string]any{"a": int64(1)}}).
+		Append(Layer{Values: map[string]any{"b": int64(2)}}).
+		Append(set("tag=1", "list[0]=x")).
+		Append(set("tag=2", "list=null"))
+
+	want := Layers{
+		{Values: map[string]any{"a": int64(1), "b": int64(2)}},
+		{Set: []Assignment{{Path: "tag", Value: int64(2)}, {Path: "list", Value: nil}}},
+	}
+	if !reflect.DeepEqual(layers, want) {
+		t.Errorf("Append() = %#v, want %#v", layers, want)
+	}
+
+	// a values file after assignments starts a new layer so it still wins over them
+	layers = layers.Append(Layer{Values: map[string]any{"tag": "file"}})
+	got, err := layers.Apply(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["tag"] != "file" {
+		t.Errorf("tag = %#v, want the later values file to win", got["tag"])
+	}
+}
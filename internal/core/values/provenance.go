@@ -0,0 +1,163 @@
+package values
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	yamlv3 "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+// Origin is the value one merge layer gave a key.
+type Origin struct {
+	// Source names the layer: `chart:values.yaml:12`, `custom.yaml:3`, `--set a=1`...
+	Source string `json:"source"`
+	// Path is the key the layer set; it differs from the explained key when the layer set a
+	// parent map or a list element.
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// Explanation is a key's effective value and every layer that set it, in merge order.
+type Explanation struct {
+	Key   string   `json:"key"`
+	Value any      `json:"value"`
+	Chain []Origin `json:"chain"`
+}
+
+// Provenance records which layer of a values merge set each leaf. Maps are walked; lists and
+// scalars are leaves. A nil *Provenance records nothing, so callers can track optionally.
+type Provenance struct {
+	origins []Origin
+}
+
+// NewProvenance returns an empty tracker.
+func NewProvenance() *Provenance {
+	return &Provenance{}
+}
+
+// Record notes every leaf of layer as set by source. When data holds the layer's YAML, each
+// source is suffixed with the line the key appears on.
+func (p *Provenance) Record(source string, layer map[string]any, data []byte) {
+	if p == nil {
+		return
+	}
+	lines := yamlLines(data)
+	walkLeaves(nil, layer, func(path []pathElem, val any) {
+		key := formatPath(path)
+		src := source
+		if line, ok := lines[key]; ok {
+			src = fmt.Sprintf("%s:%d", source, line)
+		}
+		p.origins = append(p.origins, Origin{Source: src, Path: key, Value: val})
+	})
+}
+
+// RecordOverrides notes every assignment of o, attributed to its flag and expression.
+func (p *Provenance) RecordOverrides(o Overrides) error {
+	if p == nil {
+		return nil
+	}
+	for _, kind := range o.kinds() {
+		for _, expr := range kind.exprs {
+			source := fmt.Sprintf("--%s %s", kind.flag, expr)
+			err := kind.parse(expr, map[string]any{}, func(path []pathElem, val any) {
+				walkLeaves(path, val, func(leaf []pathElem, v any) {
+					p.origins = append(p.origins, Origin{Source: source, Path: formatPath(leaf), Value: v})
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RecordAssignments notes replayed assignments, attributed to source.
+func (p *Provenance) RecordAssignments(source string, set []Assignment) {
+	if p == nil {
+		return
+	}
+	for _, a := range set {
+		path, err := parsePath(a.Path)
+		if err != nil {
+			continue
+		}
+		walkLeaves(path, a.Value, func(leaf []pathElem, v any) {
+			p.origins = append(p.origins, Origin{Source: source, Path: formatPath(leaf), Value: v})
+		})
+	}
+}
+
+// Explain lists every leaf of the merged values, sorted by key, with the layers that set the
+// key itself, a parent of it or something below it.
+func (p *Provenance) Explain(merged map[string]any) []Explanation {
+	out := []Explanation{}
+	walkLeaves(nil, merged, func(path []pathElem, val any) {
+		key := formatPath(path)
+		exp := Explanation{Key: key, Value: val, Chain: []Origin{}}
+		if p != nil {
+			for _, origin := range p.origins {
+				if related(key, origin.Path) {
+					exp.Chain = append(exp.Chain, origin)
+				}
+			}
+		}
+		out = append(out, exp)
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// related reports whether one path equals or contains the other.
+func related(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a == b || strings.HasPrefix(b, a+".") || strings.HasPrefix(b, a+"[")
+}
+
+// walkLeaves calls visit for every non-map value (and every empty map) below val.
+func walkLeaves(prefix []pathElem, val any, visit func(path []pathElem, val any)) {
+	m, ok := val.(map[string]any)
+	if !ok || len(m) == 0 {
+		if len(prefix) > 0 {
+			visit(prefix, val)
+		}
+		return
+	}
+	for key, child := range m {
+		path := append(append([]pathElem{}, prefix...), pathElem{key: key})
+		walkLeaves(path, child, visit)
+	}
+}
+
+// yamlLines maps the leaf paths of a YAML mapping document to the lines their keys are on.
+// Unparseable input yields no lines; the values themselves were already parsed elsewhere.
+func yamlLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	if len(data) == 0 {
+		return lines
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return lines
+	}
+	var walk func(prefix []pathElem, node *yamlv3.Node)
+	walk = func(prefix []pathElem, node *yamlv3.Node) {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			path := append(append([]pathElem{}, prefix...), pathElem{key: key.Value})
+			if value.Kind == yamlv3.MappingNode && len(value.Content) > 0 {
+				walk(path, value)
+				continue
+			}
+			lines[formatPath(path)] = key.Line
+		}
+	}
+	if root := doc.Content[0]; root.Kind == yamlv3.MappingNode {
+		walk(nil, root)
+	}
+	return lines
+}
@@ -0,0 +1,168 @@
+package values
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	yamlv3 "sigs.k8s.io/yaml/goyaml.v3"
+)
+
+func parseYAML(t *testing.T, data string) map[string]any {
+	t.Helper()
+	var out map[string]any
+	if err := yamlv3.Unmarshal([]byte(data), &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// origins renders a chain as "source path=value" lines so values compare regardless of the
+// integer type each parser produced.
+func origins(chain []Origin) []string {
+	out := []string{}
+	for _, o := range chain {
+		out = append(out, fmt.Sprintf("%s %s=%v", o.Source, o.Path, o.Value))
+	}
+	return out
+}
+
+func TestProvenanceExplain(t *testing.T) {
+	chartData := `image:
+  repository: nginx
+  tag: "1.25"
+ports:
+  - 80
+  - 443
+debug: true
+`
+	customData := `# production overrides
+image:
+  tag: "1.27"
+`
+	set := Overrides{Values: []string{"ports[1]=8443", "debug=null"}}
+
+	chartValues, customValues := parseYAML(t, chartData), parseYAML(t, customData)
+	prov := NewProvenance()
+	prov.Record("chart:values.yaml", chartValues, []byte(chartData))
+	prov.Record("custom.yaml", customValues, []byte(customData))
+	if err := prov.RecordOverrides(set); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := Merge(chartValues, customValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged, err = set.Apply(merged); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string][]string{}
+	var keys []string
+	for _, exp := range prov.Explain(merged) {
+		keys = append(keys, exp.Key)
+		got[exp.Key] = append([]string{fmt.Sprint(exp.Value)}, origins(exp.Chain)...)
+	}
+	// debug was removed by null, so nothing explains it
+	if want := []string{"image.repository", "image.tag", "ports"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("explained keys = %v, want %v", keys, want)
+	}
+	want := map[string][]string{
+		"image.repository": {"nginx", "chart:values.yaml:2 image.repository=nginx"},
+		"image.tag":        {"1.27", "chart:values.yaml:3 image.tag=1.25", "custom.yaml:3 image.tag=1.27"},
+		"ports":            {"[80 8443]", "chart:values.yaml:4 ports=[80 443]", "--set ports[1]=8443 ports[1]=8443"},
+	}
+	for key, chain := range want {
+		if !reflect.DeepEqual(got[key], chain) {
+			t.Errorf("%s: got %q, want %q", key, got[key], chain)
+		}
+	}
+}
+
+func TestProvenanceParentAndChild(t *testing.T) {
+	prov := NewProvenance()
+	prov.Record("base.yaml", map[string]any{"db": map[string]any{"host": "a", "port": 5432}}, nil)
+	if err := prov.RecordOverrides(Overrides{JSON: []string{`db={"host":"b"}`}, Values: []string{"db=null"}}); err != nil {
+		t.Fatal(err)
+	}
+	prov.RecordAssignments("previous release", []Assignment{{Path: "db.host", Value: "c"}})
+
+	exps := prov.Explain(map[string]any{"db": map[string]any{"host": "c"}, "dbx": 1})
+	if len(exps) != 2 || exps[0].Key != "db.host" || exps[1].Key != "dbx" {
+		t.Fatalf("explanations = %+v", exps)
+	}
+	want := []string{
+		"base.yaml db.host=a",
+		`--set-json db={"host":"b"} db.host=b`,
+		"--set db=null db=<nil>",
+		"previous release db.host=c",
+	}
+	if got := origins(exps[0].Chain); !reflect.DeepEqual(got, want) {
+		t.Errorf("db.host chain = %q, want %q", got, want)
+	}
+	// dbx shares a prefix with db but is a different key
+	if len(exps[1].Chain) != 0 {
+		t.Errorf("dbx chain = %q", origins(exps[1].Chain))
+	}
+}
+
+func TestRelated(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"a.b", "a.b", true},
+		{"a", "a.b", true},
+		{"a.b.c", "a", true},
+		{"list", "list[2]", true},
+		{"list[2].name", "list", true},
+		{"a.b", "a.bc", false},
+		{"list", "lists[0]", false},
+		{"a.b", "a.c", false},
+	}
+	for _, tt := range tests {
+		if got := related(tt.a, tt.b); got != tt.want {
+			t.Errorf("related(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestYAMLLines(t *testing.T) {
+	data := `name: demo
+db:
+  host: localhost
+  options: {}
+  tags:
+    - a
+    - b
+empty:
+`
+	want := map[string]int{"name": 1, "db.host": 3, "db.options": 4, "db.tags": 5, "empty": 8}
+	if got := yamlLines([]byte(data)); !reflect.DeepEqual(got, want) {
+		t.Errorf("yamlLines() = %v, want %v", got, want)
+	}
+	for _, data := range []string{"", "[1, 2]", "a: [unclosed"} {
+		if got := yamlLines([]byte(data)); len(got) != 0 {
+			t.Errorf("yamlLines(%q) = %v, want none", data, got)
+		}
+	}
+
+	prov := NewProvenance()
+	prov.Record("broken.yaml", map[string]any{"a": 1}, []byte("a: [unclosed"))
+	if got := origins(prov.origins); !reflect.DeepEqual(got, []string{"broken.yaml a=1"}) {
+		t.Errorf("origins without lines = %q", got)
+	}
+}
+
+func TestNilProvenance(t *testing.T) {
+	var prov *Provenance
+	prov.Record("x.yaml", map[string]any{"a": 1}, nil)
+	prov.RecordAssignments("x", []Assignment{{Path: "a", Value: 1}})
+	if err := prov.RecordOverrides(Overrides{Values: []string{"a=1"}}); err != nil {
+		t.Fatal(err)
+	}
+	exps := prov.Explain(map[string]any{"a": 1})
+	if len(exps) != 1 || exps[0].Key != "a" || len(exps[0].Chain) != 0 {
+		t.Errorf("Explain on nil = %+v", exps)
+	}
+}
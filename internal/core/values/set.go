@@ -0,0 +1,411 @@
+package values
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSetIndex bounds list indexes in set expressions so `a[99999999]=x` cannot exhaust memory.
+const maxSetIndex = 65536
+
+// Overrides are command-line value overrides. Like Helm they are applied after values files
+// in a fixed order, --set-json, --set, --set-string then --set-file, each in the order given.
+type Overrides struct {
+	// JSON holds `key=<json>` expressions.
+	JSON []string
+	// Values holds `a.b=1,c[0]=x,d={x,y}` expressions; ints, bools and null are typed.
+	Values []string
+	// Strings holds expressions whose values always stay strings.
+	Strings []string
+	// Files holds `key=path` expressions; the value is the file's content.
+	Files []string
+}
+
+// IsZero reports whether no override was given.
+func (o Overrides) IsZero() bool {
+	return len(o.JSON) == 0 && len(o.Values) == 0 && len(o.Strings) == 0 && len(o.Files) == 0
+}
+
+// Sources lists a provenance label for every kind of override present, in application order.
+func (o Overrides) Sources() []string {
+	var sources []string
+	for _, kind := range o.kinds() {
+		if len(kind.exprs) > 0 {
+			sources = append(sources, "cli:"+kind.flag)
+		}
+	}
+	return sources
+}
+
+// Apply sets every expression on a copy of base, in order, the way Helm's strvals.ParseInto
+// does: list indexes address the existing elements and keys set to null are removed.
+func (o Overrides) Apply(base map[string]any) (map[string]any, error) {
+	layer, err := o.Layer()
+	if err != nil {
+		return nil, err
+	}
+	return Layers{layer}.Apply(base)
+}
+
+type overrideKind struct {
+	flag  string
+	exprs []string
+	value func(raw string) (any, error)
+}
+
+func (k overrideKind) parse(expr string, dst map[string]any, onSet func(path []pathElem, val any)) error {
+	p := &setParser{in: []rune(expr), value: k.value, json: k.flag == "set-json", onSet: onSet}
+	if err := p.parseInto(dst); err != nil {
+		return fmt.Errorf("parse --%s %q: %w", k.flag, expr, err)
+	}
+	return nil
+}
+
+func (o Overrides) kinds() []overrideKind {
+	return []overrideKind{
+		{flag: "set-json", exprs: o.JSON},
+		{flag: "set", exprs: o.Values, value: func(raw string) (any, error) { return typedValue(raw), nil }},
+		{flag: "set-string", exprs: o.Strings, value: func(raw string) (any, error) { return raw, nil }},
+		{flag: "set-file", exprs: o.Files, value: func(raw string) (any, error) {
+			data, err := os.ReadFile(raw)
+			if err != nil {
+				return nil, err
+			}
+			return string(data), nil
+		}},
+	}
+}
+
+// typedValue infers --set types the way Helm does: bools, null and integers without a
+// leading zero; everything else (floats included) stays a string.
+func typedValue(raw string) any {
+	switch {
+	case strings.EqualFold(raw, "true"):
+		return true
+	case strings.EqualFold(raw, "false"):
+		return false
+	case strings.EqualFold(raw, "null"):
+		return nil
+	case len(raw) > 1 && raw[0] == '0':
+		return raw
+	}
+	if n, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return n
+	}
+	return raw
+}
+
+// pathElem is one step of a set key: a map key or a list index.
+type pathElem struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// setParser reads comma-separated `key=value` pairs. Keys are dotted paths with `[n]` list
+// indexes; a backslash makes the next character literal in keys and values.
+type setParser struct {
+	in    []rune
+	pos   int
+	value func(raw string) (any, error)
+	json  bool
+	// onSet, when set, observes every assignment in order.
+	onSet func(path []pathElem, val any)
+}
+
+func (p *setParser) parseInto(dst map[string]any) error {
+	for p.pos < len(p.in) {
+		path, err := p.key()
+		if err != nil {
+			return err
+		}
+		val, err := p.parseValue()
+		if err != nil {
+			return fmt.Errorf("key %s: %w", formatPath(path), err)
+		}
+		assign(dst, path, val)
+		if p.onSet != nil {
+			p.onSet(path, val)
+		}
+		if p.pos < len(p.in) {
+			if p.in[p.pos] != ',' {
+				return fmt.Errorf("unexpected %q after value of %s", p.in[p.pos], formatPath(path))
+			}
+			p.pos++
+		}
+	}
+	return nil
+}
+
+// key reads a path up to and including the `=` that ends it.
+func (p *setParser) key() ([]pathElem, error) {
+	var (
+		path []pathElem
+		buf  strings.Builder
+		// closed is set right after `]`, where only `.`, `[` or `=` may follow
+		closed bool
+	)
+	flush := func() error {
+		if buf.Len() == 0 {
+			return errors.New("empty key segment")
+		}
+		path = append(path, pathElem{key: buf.String()})
+		buf.Reset()
+		return nil
+	}
+	for p.pos < len(p.in) {
+		r := p.in[p.pos]
+		p.pos++
+		if closed && r != '.' && r != '[' && r != '=' {
+			return nil, fmt.Errorf("unexpected %q after ] in key", r)
+		}
+		switch r {
+		case '\\':
+			if p.pos == len(p.in) {
+				return nil, errors.New("key ends with an escape character")
+			}
+			buf.WriteRune(p.in[p.pos])
+			p.pos++
+		case '.':
+			if !closed {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			closed = false
+		case '[':
+			if !closed {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			index, err := p.index()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, pathElem{index: index, isIndex: true})
+			closed = true
+		case '=':
+			if !closed {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			return path, nil
+		case ',':
+			return nil, fmt.Errorf("key %q has no value", buf.String())
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return nil, fmt.Errorf("key %q has no value", buf.String())
+}
+
+// index reads the digits of `[n]`, after the `[`.
+func (p *setParser) index() (int, error) {
+	end := p.pos
+	for end < len(p.in) && p.in[end] != ']' {
+		end++
+	}
+	if end == len(p.in) {
+		return 0, errors.New("unterminated [ in key")
+	}
+	raw := string(p.in[p.pos:end])
+	p.pos = end + 1
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid list index [%s]", raw)
+	}
+	if n > maxSetIndex {
+		return 0, fmt.Errorf("list index %d exceeds the limit of %d", n, maxSetIndex)
+	}
+	return n, nil
+}
+
+func (p *setParser) parseValue() (any, error) {
+	if p.json {
+		return p.jsonValue()
+	}
+	if p.pos < len(p.in) && p.in[p.pos] == '{' {
+		p.pos++
+		return p.list()
+	}
+	return p.value(p.until(","))
+}
+
+// jsonValue decodes a single JSON document; integral numbers become int64.
+func (p *setParser) jsonValue() (any, error) {
+	rest := string(p.in[p.pos:])
+	dec := json.NewDecoder(strings.NewReader(rest))
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	p.pos += utf8.RuneCountInString(rest[:dec.InputOffset()])
+	for p.pos < len(p.in) && unicode.IsSpace(p.in[p.pos]) {
+		p.pos++
+	}
+	return normalizeJSON(val), nil
+}
+
+// list reads `a,b}` after the opening brace.
+func (p *setParser) list() (any, error) {
+	out := []any{}
+	if p.pos < len(p.in) && p.in[p.pos] == '}' {
+		p.pos++
+		return out, nil
+	}
+	for {
+		raw := p.until(",}")
+		if p.pos == len(p.in) {
+			return nil, errors.New("list is missing its closing }")
+		}
+		val, err := p.value(raw)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, val)
+		closing := p.in[p.pos] == '}'
+		p.pos++
+		if closing {
+			return out, nil
+		}
+	}
+}
+
+// until reads up to (not including) the first unescaped rune in stop, unescaping as it goes.
+func (p *setParser) until(stop string) string {
+	var buf strings.Builder
+	for p.pos < len(p.in) {
+		r := p.in[p.pos]
+		if strings.ContainsRune(stop, r) {
+			break
+		}
+		p.pos++
+		if r == '\\' && p.pos < len(p.in) {
+			r = p.in[p.pos]
+			p.pos++
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// assign stores val at path below dst; a null value removes the key instead, and nulls inside
+// a JSON object are dropped the same way.
+func assign(dst map[string]any, path []pathElem, val any) {
+	if val == nil && !path[len(path)-1].isIndex {
+		unsetPath(dst, path)
+		return
+	}
+	setPath(dst, path, dropNulls(val))
+}
+
+// unsetPath deletes the key path points at; missing parents are left alone.
+func unsetPath(node any, path []pathElem) {
+	for _, elem := range path[:len(path)-1] {
+		if elem.isIndex {
+			list, _ := node.([]any)
+			if elem.index >= len(list) {
+				return
+			}
+			node = list[elem.index]
+			continue
+		}
+		m, _ := node.(map[string]any)
+		node = m[elem.key]
+	}
+	if m, ok := node.(map[string]any); ok {
+		delete(m, path[len(path)-1].key)
+	}
+}
+
+func dropNulls(val any) any {
+	switch typed := val.(type) {
+	case map[string]any:
+		for k, v := range typed {
+			if v == nil {
+				delete(typed, k)
+				continue
+			}
+			typed[k] = dropNulls(v)
+		}
+	case []any:
+		for i, v := range typed {
+			typed[i] = dropNulls(v)
+		}
+	}
+	return val
+}
+
+// setPath stores val at path below node and returns the (possibly new) node.
+func setPath(node any, path []pathElem, val any) any {
+	if len(path) == 0 {
+		return val
+	}
+	elem := path[0]
+	if elem.isIndex {
+		list, _ := node.([]any)
+		for len(list) <= elem.index {
+			list = append(list, nil)
+		}
+		list[elem.index] = setPath(list[elem.index], path[1:], val)
+		return list
+	}
+	m, ok := node.(map[string]any)
+	if !ok {
+		m = map[string]any{}
+	}
+	m[elem.key] = setPath(m[elem.key], path[1:], val)
+	return m
+}
+
+func normalizeJSON(val any) any {
+	switch typed := val.(type) {
+	case json.Number:
+		if n, err := typed.Int64(); err == nil {
+			return n
+		}
+		f, _ := typed.Float64()
+		return f
+	case map[string]any:
+		for k, v := range typed {
+			typed[k] = normalizeJSON(v)
+		}
+	case []any:
+		for i, v := range typed {
+			typed[i] = normalizeJSON(v)
+		}
+	}
+	return val
+}
+
+// formatPath renders path in set syntax, escaping key characters the parser treats specially.
+func formatPath(path []pathElem) string {
+	var buf bytes.Buffer
+	for i, elem := range path {
+		switch {
+		case elem.isIndex:
+			fmt.Fprintf(&buf, "[%d]", elem.index)
+		case i > 0:
+			buf.WriteString("." + escapeKey(elem.key))
+		default:
+			buf.WriteString(escapeKey(elem.key))
+		}
+	}
+	return buf.String()
+}
+
+var keyEscaper = strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`, "=", `\=`, ",", `\,`)
+
+func escapeKey(key string) string {
+	return keyEscaper.Replace(key)
+}
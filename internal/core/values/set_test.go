@@ -0,0 +1,184 @@
+package values
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOverridesApply(t *testing.T) {
+	base := func() map[string]any {
+		return map[string]any{
+			"ports": []any{int64(80), int64(443)},
+			"svc": map[string]any{
+				"list": []any{
+					map[string]any{"name": "a", "port": int64(1)},
+					map[string]any{"name": "b", "port": int64(2)},
+				},
+			},
+			"keep": "x",
+			"drop": "y",
+		}
+	}
+
+	tests := []struct {
+		name string
+		o    Overrides
+		want map[string]any
+	}{
+		{
+			name: "index into existing scalar list",
+			o:    Overrides{Values: []string{"ports[1]=8080"}},
+			want: map[string]any{"ports": []any{int64(80), int64(8080)}},
+		},
+		{
+			name: "index into existing list of maps",
+			o:    Overrides{Values: []string{"svc.list[0].port=9"}},
+			want: map[string]any{"svc": map[string]any{"list": []any{
+				map[string]any{"name": "a", "port": int64(9)},
+				map[string]any{"name": "b", "port": int64(2)},
+			}}},
+		},
+		{
+			name: "index past the end grows the list",
+			o:    Overrides{Values: []string{"ports[3]=1"}},
+			want: map[string]any{"ports": []any{int64(80), int64(443), nil, int64(1)}},
+		},
+		{
+			name: "null removes the key",
+			o:    Overrides{Values: []string{"drop=null"}},
+			want: map[string]any{"drop": nil},
+		},
+		{
+			name: "null below a missing parent is a no-op",
+			o:    Overrides{Values: []string{"missing.key=null"}},
+			want: map[string]any{},
+		},
+		{
+			name: "brace list replaces the list",
+			o:    Overrides{Values: []string{"ports={1,2,3}"}},
+			want: map[string]any{"ports": []any{int64(1), int64(2), int64(3)}},
+		},
+		{
+			name: "empty brace list",
+			o:    Overrides{Values: []string{"ports={}"}},
+			want: map[string]any{"ports": []any{}},
+		},
+		{
+			name: "escaped dot and comma",
+			o:    Overrides{Values: []string{`labels.app\.kubernetes\.io/name=web,msg=a\,b`}},
+			want: map[string]any{
+				"labels": map[string]any{"app.kubernetes.io/name": "web"},
+				"msg":    "a,b",
+			},
+		},
+		{
+			name: "type inference",
+			o:    Overrides{Values: []string{"a=true,b=FALSE,c=42,d=007,e=1.5,f=-3,g=text"}},
+			want: map[string]any{
+				"a": true, "b": false, "c": int64(42), "d": "007", "e": "1.5", "f": int64(-3), "g": "text",
+			},
+		},
+		{
+			name: "set-string keeps strings",
+			o:    Overrides{Strings: []string{"a=true,c=42"}},
+			want: map[string]any{"a": "true", "c": "42"},
+		},
+		{
+			name: "set-json object replaces and drops nulls",
+			o:    Overrides{JSON: []string{`svc={"list":[{"name":"z","port":null}],"x":null}`}},
+			want: map[string]any{"svc": map[string]any{"list": []any{map[string]any{"name": "z"}}}},
+		},
+		{
+			name: "set wins over set-json",
+			o:    Overrides{JSON: []string{`keep="json"`}, Values: []string{"keep=set"}},
+			want: map[string]any{"keep": "set"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := base()
+			got, err := tt.o.Apply(in)
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			want := base()
+			for k, v := range tt.want {
+				if v == nil {
+					delete(want, k)
+					continue
+				}
+				want[k] = v
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply() = %#v\nwant %#v", got, want)
+			}
+			if !reflect.DeepEqual(in, base()) {
+				t.Errorf("Apply modified its base: %#v", in)
+			}
+		})
+	}
+}
+
+func TestOverridesApplySetFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(path, []byte("PEM"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Overrides{Files: []string{"tls.cert=" + path}}.Apply(nil)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := map[string]any{"tls": map[string]any{"cert": "PEM"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %#v, want %#v", got, want)
+	}
+}
+
+func TestOverridesApplyErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"a", "has no value"},
+		{"a.=1", "empty key segment"},
+		{"a[x]=1", "invalid list index"},
+		{"a[-1]=1", "invalid list index"},
+		{"a[99999999]=1", "exceeds the limit"},
+		{"a[1=1", "unterminated ["},
+		{"a[0]b=1", "after ] in key"},
+		{"a={1,2", "missing its closing }"},
+		{`a\`, "escape character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Overrides{Values: []string{tt.expr}}.Apply(nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Apply(%q) error = %v, want it to mention %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := (Overrides{JSON: []string{"a={"}}).Apply(nil); err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Errorf("set-json error = %v, want invalid JSON", err)
+	}
+}
+
+func TestFormatPathRoundTrip(t *testing.T) {
+	path := []pathElem{{key: "a.b"}, {index: 2, isIndex: true}, {key: "c=d,e"}}
+	formatted := formatPath(path)
+	if want := `a\.b[2].c\=d\,e`; formatted != want {
+		t.Fatalf("formatPath = %q, want %q", formatted, want)
+	}
+	got, err := Overrides{Values: []string{formatted + "=1"}}.Apply(nil)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	want := map[string]any{"a.b": []any{nil, nil, map[string]any{"c=d,e": int64(1)}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %#v, want %#v", got, want)
+	}
+}
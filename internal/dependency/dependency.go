@@ -0,0 +1,512 @@
+package dependency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/oci"
+	"composepack/internal/packager"
+	"composepack/internal/repo"
+	"composepack/internal/util/fsutil"
+)
+
+// Dependency states reported by List.
+const (
+	StatusOK       = "ok"
+	StatusMissing  = "missing"
+	StatusUnpacked = "unpacked"
+)
+
+// Lock mirrors the contents of Chart.lock.
+type Lock struct {
+	Generated time.Time `json:"generated"`
+	// Digest fingerprints the Chart.yaml dependencies section the lock was generated from.
+	Digest       string             `json:"digest"`
+	Dependencies []LockedDependency `json:"dependencies"`
+}
+
+// LockedDependency pins one dependency to an exact version and archive digest.
+type LockedDependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository,omitempty"`
+	// Digest is the sha256 of the vendored archive; empty for file:// dependencies, which are repackaged on every build.
+	Digest string `json:"digest,omitempty"`
+}
+
+// Status describes one declared dependency for `dependency list`.
+type Status struct {
+	Name       string `json:"name"`
+	Alias      string `json:"alias,omitempty"`
+	Version    string `json:"version"`
+	Repository string `json:"repository,omitempty"`
+	Locked     string `json:"locked,omitempty"`
+	Status     string `json:"status"`
+}
+
+// downloadTimeout bounds a whole repository request, index or archive, body included.
+const downloadTimeout = 5 * time.Minute
+
+// Manager vendors chart dependencies into `charts/` and maintains Chart.lock.
+type Manager struct {
+	loader chart.Loader
+	client *http.Client
+	// maxSize caps a downloaded archive; larger ones could not be extracted anyway.
+	maxSize int64
+}
+
+// NewManager constructs a dependency manager that uses loader to inspect charts.
+func NewManager(loader chart.Loader) *Manager {
+	return &Manager{
+		loader:  loader,
+		client:  &http.Client{Timeout: downloadTimeout},
+		maxSize: chart.DefaultExtractLimits.MaxTotalSize,
+	}
+}
+
+// List reports every dependency declared in Chart.yaml and whether it is available.
+func (m *Manager) List(ctx context.Context, chartDir string) ([]Status, error) {
+	meta, err := readMetadata(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := ReadLock(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	vendored, err := m.vendored(ctx, chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Status, 0, len(meta.Dependencies))
+	for _, dep := range meta.Dependencies {
+		st := Status{
+			Name:       dep.Name,
+			Alias:      dep.Alias,
+			Version:    dep.Version,
+			Repository: dep.Repository,
+			Status:     StatusMissing,
+		}
+		if locked := lock.find(dep.Name); locked != nil {
+			st.Locked = locked.Version
+		}
+		switch {
+		case findVendored(vendored, dep) != nil:
+			st.Status = StatusOK
+		case chart.IsLocalRepository(dep.Repository):
+			if _, err := os.Stat(chart.LocalRepositoryPath(chartDir, dep.Repository)); err == nil {
+				st.Status = StatusUnpacked
+			}
+		}
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Update resolves every dependency to its newest matching version, vendors it into `charts/`
+// and rewrites Chart.lock.
+func (m *Manager) Update(ctx context.Context, chartDir string) (*Lock, error) {
+	meta, err := readMetadata(chartDir)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{Generated: time.Now().UTC(), Digest: dependenciesDigest(meta.Dependencies)}
+	done := map[string]LockedDependency{}
+	for _, dep := range meta.Dependencies {
+		if locked, ok := done[dep.Name+"@"+dep.Repository+"@"+dep.Version]; ok {
+			lock.Dependencies = append(lock.Dependencies, locked)
+			continue
+		}
+		locked, err := m.fetch(ctx, chartDir, dep, "")
+		if err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", dep.Name, err)
+		}
+		done[dep.Name+"@"+dep.Repository+"@"+dep.Version] = locked
+		lock.Dependencies = append(lock.Dependencies, locked)
+	}
+
+	if err := WriteLock(chartDir, lock); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Build re-creates `charts/` from Chart.lock, verifying archive digests.
+func (m *Manager) Build(ctx context.Context, chartDir string) (*Lock, error) {
+	meta, err := readMetadata(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := ReadLock(chartDir)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, fmt.Errorf("%s not found; run composepack dependency update first", chart.LockFile)
+	}
+	if lock.Digest != dependenciesDigest(meta.Dependencies) {
+		return nil, fmt.Errorf("%s is out of date with %s; run composepack dependency update", chart.LockFile, chart.MetadataFile)
+	}
+
+	for _, locked := range lock.Dependencies {
+		dep := chart.Dependency{Name: locked.Name, Version: locked.Version, Repository: locked.Repository}
+		target := filepath.Join(chartDir, chart.ChartsDir, archiveName(locked.Name, locked.Version))
+		if locked.Digest != "" {
+			if digest, err := fileDigest(target); err == nil && digest == locked.Digest {
+				continue
+			}
+		}
+		if _, err := m.fetch(ctx, chartDir, dep, locked.Digest); err != nil {
+			return nil, fmt.Errorf("dependency %s: %w", locked.Name, err)
+		}
+	}
+	return lock, nil
+}
+
+// fetch vendors a single dependency into `charts/` and returns its lock entry.
+// When wantDigest is set the fetched archive must match it.
+func (m *Manager) fetch(ctx context.Context, chartDir string, dep chart.Dependency, wantDigest string) (LockedDependency, error) {
+	chartsDir := filepath.Join(chartDir, chart.ChartsDir)
+	if err := os.MkdirAll(chartsDir, 0o755); err != nil {
+		return LockedDependency{}, fmt.Errorf("ensure %s dir: %w", chart.ChartsDir, err)
+	}
+
+	switch {
+	case chart.IsLocalRepository(dep.Repository):
+		return m.fetchLocal(ctx, chartDir, chartsDir, dep)
+	case strings.HasPrefix(dep.Repository, "http://") || strings.HasPrefix(dep.Repository, "https://") || oci.IsReference(dep.Repository):
+		return m.fetchRemote(ctx, chartsDir, dep, wantDigest)
+	case dep.Repository == "":
+		vendored, err := m.vendored(ctx, chartDir)
+		if err != nil {
+			return LockedDependency{}, err
+		}
+		found := findVendored(vendored, dep)
+		if found == nil {
+			return LockedDependency{}, fmt.Errorf("no repository set and no matching chart in %s/", chart.ChartsDir)
+		}
+		locked := LockedDependency{Name: dep.Name, Version: found.chart.Metadata.Version}
+		if !found.dir {
+			locked.Digest, _ = fileDigest(found.path)
+		}
+		return locked, nil
+	default:
+		return LockedDependency{}, fmt.Errorf("unsupported repository %q", dep.Repository)
+	}
+}
+
+func (m *Manager) fetchLocal(ctx context.Context, chartDir, chartsDir string, dep chart.Dependency) (LockedDependency, error) {
+	src := chart.LocalRepositoryPath(chartDir, dep.Repository)
+	sub, err := m.loader.Load(ctx, src)
+	if err != nil {
+		return LockedDependency{}, fmt.Errorf("load %s: %w", src, err)
+	}
+	if sub.Metadata.Name != dep.Name {
+		return LockedDependency{}, fmt.Errorf("%s contains chart %s", src, sub.Metadata.Name)
+	}
+	if !chart.VersionMatches(dep.Version, sub.Metadata.Version) {
+		return LockedDependency{}, fmt.Errorf("%s has version %s which does not satisfy %s", src, sub.Metadata.Version, dep.Version)
+	}
+
+	if _, err := packager.PackageChart(ctx, m.loader, packager.Options{
+		ChartPath:   src,
+		Destination: chartsDir,
+		Force:       true,
+	}); err != nil {
+		return LockedDependency{}, err
+	}
+	if err := removeStale(chartsDir, dep.Name, sub.Metadata.Version); err != nil {
+		return LockedDependency{}, err
+	}
+	return LockedDependency{Name: dep.Name, Version: sub.Metadata.Version, Repository: dep.Repository}, nil
+}
+
+func (m *Manager) fetchRemote(ctx context.Context, chartsDir string, dep chart.Dependency, wantDigest string) (LockedDependency, error) {
+	version, url, indexDigest, err := m.resolveRemote(ctx, dep)
+	if err != nil {
+		return LockedDependency{}, err
+	}
+
+	target := filepath.Join(chartsDir, archiveName(dep.Name, version))
+	var digest string
+	if oci.IsReference(dep.Repository) {
+		digest, err = m.pull(ctx, url, target)
+	} else {
+		digest, err = m.download(ctx, url, target)
+	}
+	if err != nil {
+		return LockedDependency{}, err
+	}
+	for _, want := range []string{wantDigest, indexDigest} {
+		if want != "" && digest != want {
+			os.Remove(target)
+			return LockedDependency{}, fmt.Errorf("digest mismatch for %s: got %s, want %s", url, digest, want)
+		}
+	}
+
+	sub, err := m.loader.Load(ctx, target)
+	if err != nil {
+		os.Remove(target)
+		return LockedDependency{}, fmt.Errorf("load downloaded chart: %w", err)
+	}
+	if sub.Metadata.Name != dep.Name || sub.Metadata.Version != version {
+		os.Remove(target)
+		return LockedDependency{}, fmt.Errorf("%s contains %s %s", url, sub.Metadata.Name, sub.Metadata.Version)
+	}
+	if err := removeStale(chartsDir, dep.Name, version); err != nil {
+		return LockedDependency{}, err
+	}
+	return LockedDependency{Name: dep.Name, Version: version, Repository: dep.Repository, Digest: digest}, nil
+}
+
+// resolveRemote picks the version and download location of a remote dependency. Exact
+// versions map straight to `<repo>/<name>-<version>.cpack.tgz` (or `<repo>/<name>:<version>`
+// for OCI); ranges are resolved through the repository's index.yaml.
+func (m *Manager) resolveRemote(ctx context.Context, dep chart.Dependency) (version, url, digest string, err error) {
+	base := strings.TrimSuffix(dep.Repository, "/")
+	if _, err := semver.StrictNewVersion(strings.TrimPrefix(dep.Version, "v")); err == nil {
+		if oci.IsReference(dep.Repository) {
+			return dep.Version, base + "/" + dep.Name + ":" + dep.Version, "", nil
+		}
+		return dep.Version, base + "/" + archiveName(dep.Name, dep.Version), "", nil
+	}
+	if oci.IsReference(dep.Repository) {
+		return "", "", "", fmt.Errorf("version %q must be an exact version for repository %s", dep.Version, dep.Repository)
+	}
+
+	index, _, err := repo.FetchIndex(ctx, m.client, dep.Repository)
+	if err != nil {
+		return "", "", "", err
+	}
+	cv, err := index.Find(dep.Name, dep.Version)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%s: %w", dep.Repository, err)
+	}
+	url, err = cv.ResolveURL(dep.Repository)
+	if err != nil {
+		return "", "", "", err
+	}
+	return cv.Version, url, cv.Digest, nil
+}
+
+func (m *Manager) download(ctx context.Context, url, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download %s: unexpected status %s", url, resp.Status)
+	}
+	limit := m.maxSize
+	if resp.ContentLength > limit {
+		return "", fmt.Errorf("download %s: archive is %d bytes, more than the %d allowed", url, resp.ContentLength, limit)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".download-*")
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("save %s: %w", url, err)
+	}
+	if n > limit {
+		tmp.Close()
+		return "", fmt.Errorf("save %s: more than the %d bytes allowed", url, limit)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return "", fmt.Errorf("move downloaded chart: %w", err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// pull fetches an OCI chart artifact into target and returns the archive digest.
+func (m *Manager) pull(ctx context.Context, source, target string) (string, error) {
+	ref, err := oci.ParseReference(source)
+	if err != nil {
+		return "", err
+	}
+	client := oci.NewClient()
+	client.MaxBlobSize = chart.DefaultExtractLimits.MaxTotalSize
+	artifact, _, err := client.Pull(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("pull %s: %w", source, err)
+	}
+	if err := fsutil.WriteFileAtomic(ctx, target, artifact.Archive, 0o644); err != nil {
+		return "", fmt.Errorf("save %s: %w", source, err)
+	}
+	sum := sha256.Sum256(artifact.Archive)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+type vendoredChart struct {
+	path  string
+	dir   bool
+	chart *chart.Chart
+}
+
+// vendored loads every chart directory/archive under `charts/`.
+func (m *Manager) vendored(ctx context.Context, chartDir string) ([]vendoredChart, error) {
+	dir := filepath.Join(chartDir, chart.ChartsDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", chart.ChartsDir, err)
+	}
+
+	var out []vendoredChart
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		ch, err := m.loader.Load(ctx, path)
+		if err != nil {
+			continue
+		}
+		out = append(out, vendoredChart{path: path, dir: entry.IsDir(), chart: ch})
+	}
+	return out, nil
+}
+
+func findVendored(charts []vendoredChart, dep chart.Dependency) *vendoredChart {
+	for i := range charts {
+		meta := charts[i].chart.Metadata
+		if meta.Name == dep.Name && chart.VersionMatches(dep.Version, meta.Version) {
+			return &charts[i]
+		}
+	}
+	return nil
+}
+
+// removeStale deletes archives of other versions of the same chart from `charts/`.
+func removeStale(chartsDir, name, keepVersion string) error {
+	entries, err := os.ReadDir(chartsDir)
+	if err != nil {
+		return err
+	}
+	prefix := name + "-"
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(file, prefix) || !strings.HasSuffix(file, ".cpack.tgz") {
+			continue
+		}
+		version := strings.TrimSuffix(strings.TrimPrefix(file, prefix), ".cpack.tgz")
+		if version == keepVersion {
+			continue
+		}
+		if _, err := semver.NewVersion(version); err != nil {
+			continue
+		}
+		if err := os.Remove(filepath.Join(chartsDir, file)); err != nil {
+			return fmt.Errorf("remove stale dependency %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// ReadLock loads Chart.lock, returning (nil, nil) when it does not exist.
+func ReadLock(chartDir string) (*Lock, error) {
+	data, err := os.ReadFile(filepath.Join(chartDir, chart.LockFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", chart.LockFile, err)
+	}
+	var lock Lock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", chart.LockFile, err)
+	}
+	return &lock, nil
+}
+
+// WriteLock persists Chart.lock next to Chart.yaml.
+func WriteLock(chartDir string, lock *Lock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("serialize %s: %w", chart.LockFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(chartDir, chart.LockFile), data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", chart.LockFile, err)
+	}
+	return nil
+}
+
+func (l *Lock) find(name string) *LockedDependency {
+	if l == nil {
+		return nil
+	}
+	for i := range l.Dependencies {
+		if l.Dependencies[i].Name == name {
+			return &l.Dependencies[i]
+		}
+	}
+	return nil
+}
+
+func readMetadata(chartDir string) (*chart.ChartMetadata, error) {
+	data, err := os.ReadFile(filepath.Join(chartDir, chart.MetadataFile))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", chart.MetadataFile, err)
+	}
+	var meta chart.ChartMetadata
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", chart.MetadataFile, err)
+	}
+	return &meta, nil
+}
+
+func dependenciesDigest(deps []chart.Dependency) string {
+	data, _ := json.Marshal(deps)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func archiveName(name, version string) string {
+	return fmt.Sprintf("%s-%s.cpack.tgz", name, version)
+}
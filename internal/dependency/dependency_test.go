@@ -0,0 +1,186 @@
+package dependency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/cache"
+	"composepack/internal/packager"
+	"composepack/internal/util/fileloader"
+)
+
+func testManager(t *testing.T) *Manager {
+	fsLoader := chart.NewFileSystemChartLoader(fileloader.NewFileSystemLoader())
+	return NewManager(chart.NewCompositeLoader(fsLoader, &cache.Cache{Dir: t.TempDir()}))
+}
+
+func writeChart(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// serveDependency packages a `db` 1.0.0 chart and serves it from a repository root; requests
+// counts the archive downloads.
+func serveDependency(t *testing.T, m *Manager) (srv *httptest.Server, requests *int) {
+	t.Helper()
+	src := filepath.Join(t.TempDir(), "db")
+	writeChart(t, src, map[string]string{
+		"Chart.yaml":                    "name: db\nversion: 1.0.0\n",
+		"values.yaml":                   "image: postgres\n",
+		"templates/compose/db.tpl.yaml": "services:\n  db:\n    image: {{ .Values.image }}\n",
+	})
+	archive, err := packager.PackageChart(context.Background(), m.loader, packager.Options{ChartPath: src, Destination: t.TempDir()})
+	if err != nil {
+		t.Fatalf("package dependency: %v", err)
+	}
+	requests = new(int)
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/db-1.0.0.cpack.tgz" {
+			http.NotFound(w, r)
+			return
+		}
+		*requests++
+		http.ServeFile(w, r, archive)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func parentChart(t *testing.T, repository string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeChart(t, dir, map[string]string{
+		"Chart.yaml":                     "name: app\nversion: 0.1.0\ndependencies:\n  - name: db\n    version: 1.0.0\n    repository: " + repository + "\n",
+		"values.yaml":                    "{}\n",
+		"templates/compose/app.tpl.yaml": "services:\n  app:\n    image: busybox\n",
+	})
+	return dir
+}
+
+func TestUpdateWritesLock(t *testing.T) {
+	m := testManager(t)
+	srv, _ := serveDependency(t, m)
+	dir := parentChart(t, srv.URL)
+
+	lock, err := m.Update(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	vendored := filepath.Join(dir, chart.ChartsDir, "db-1.0.0.cpack.tgz")
+	digest, err := fileDigest(vendored)
+	if err != nil {
+		t.Fatalf("vendored archive: %v", err)
+	}
+	if len(lock.Dependencies) != 1 {
+		t.Fatalf("lock = %+v", lock)
+	}
+	if got := lock.Dependencies[0]; got.Name != "db" || got.Version != "1.0.0" || got.Repository != srv.URL || got.Digest != digest {
+		t.Errorf("locked dependency = %+v, want digest %s", got, digest)
+	}
+
+	written, err := ReadLock(dir)
+	if err != nil || written == nil {
+		t.Fatalf("ReadLock = %v, %v", written, err)
+	}
+	meta, _ := readMetadata(dir)
+	if written.Digest != dependenciesDigest(meta.Dependencies) || written.Dependencies[0] != lock.Dependencies[0] {
+		t.Errorf("%s = %+v", chart.LockFile, written)
+	}
+}
+
+func TestBuildFromLock(t *testing.T) {
+	m := testManager(t)
+	srv, requests := serveDependency(t, m)
+	dir := parentChart(t, srv.URL)
+	ctx := context.Background()
+	if _, err := m.Update(ctx, dir); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if _, err := m.Build(ctx, dir); err != nil || *requests != 1 {
+		t.Fatalf("Build with the archive in place = %v after %d downloads, want no new download", err, *requests)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, chart.ChartsDir)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Build(ctx, dir); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, chart.ChartsDir, "db-1.0.0.cpack.tgz")); err != nil || *requests != 2 {
+		t.Errorf("Build did not restore the archive (%d downloads): %v", *requests, err)
+	}
+
+	writeChart(t, dir, map[string]string{
+		"Chart.yaml": "name: app\nversion: 0.1.0\ndependencies:\n  - name: db\n    version: 1.0.1\n    repository: " + srv.URL + "\n",
+	})
+	if _, err := m.Build(ctx, dir); err == nil || !strings.Contains(err.Error(), "out of date") {
+		t.Errorf("Build after editing dependencies = %v", err)
+	}
+}
+
+func TestBuildRejectsDigestMismatch(t *testing.T) {
+	m := testManager(t)
+	srv, _ := serveDependency(t, m)
+	dir := parentChart(t, srv.URL)
+	ctx := context.Background()
+	lock, err := m.Update(ctx, dir)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	lock.Dependencies[0].Digest = "sha256:" + strings.Repeat("0", 64)
+	if err := WriteLock(dir, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Build(ctx, dir); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("Build error = %v, want a digest mismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, chart.ChartsDir, "db-1.0.0.cpack.tgz")); !os.IsNotExist(err) {
+		t.Errorf("archive with the wrong digest was kept: %v", err)
+	}
+}
+
+func TestDownloadRejectsOversizedArchive(t *testing.T) {
+	body := strings.Repeat("x", 2<<10)
+	tests := map[string]http.HandlerFunc{
+		"declared length": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		},
+		// flushing before the body makes the response chunked, with no Content-Length
+		"chunked body": func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			w.Write([]byte(body))
+		},
+	}
+	for name, handler := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+			m := testManager(t)
+			m.maxSize = 1 << 10
+
+			dir := t.TempDir()
+			_, err := m.download(context.Background(), srv.URL+"/huge.cpack.tgz", filepath.Join(dir, "huge.cpack.tgz"))
+			if err == nil || !strings.Contains(err.Error(), "1024") {
+				t.Fatalf("download error = %v, want the size cap to be reported", err)
+			}
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Errorf("oversized download left %v behind", entries)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ package di
 import (
 	"composepack/internal/app"
 	"composepack/internal/core/chart"
+	"composepack/internal/infra/cache"
 	"composepack/internal/infra/config"
 	"composepack/internal/infra/logging"
 	"composepack/internal/util/fileloader"
@@ -22,8 +23,9 @@ func InitializeApplication() (*app.Application, error) {
 	logger := provideLogger()
 	fileSystemLoader := fileloader.NewFileSystemLoader()
 	fileSystemChartLoader := chart.NewFileSystemChartLoader(fileSystemLoader)
-	compositeLoader := chart.NewCompositeLoader(fileSystemChartLoader)
-	runtime := app.NewRuntime(config, logger, compositeLoader)
+	cacheCache := cache.New()
+	compositeLoader := chart.NewCompositeLoader(fileSystemChartLoader, cacheCache)
+	runtime := app.NewRuntime(config, logger, compositeLoader, cacheCache)
 	application := app.NewApplication(runtime)
 	return application, nil
 }
@@ -0,0 +1,320 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"composepack/internal/infra/config"
+	"composepack/internal/util/fsutil"
+)
+
+// ErrNotCached is returned in offline mode when a source has never been downloaded.
+var ErrNotCached = errors.New("not in the local chart cache")
+
+// defaultMaxSize caps stored archives when Cache.MaxSize is unset.
+const defaultMaxSize = 256 << 20
+
+// Entry records where a cached chart archive came from.
+type Entry struct {
+	Source       string    `json:"source"`
+	Digest       string    `json:"digest"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchedAt    time.Time `json:"fetchedAt"`
+	// Path is the cached archive; it is derived from Digest and not stored.
+	Path string `json:"-"`
+}
+
+// Cache stores downloaded chart archives by content digest, with a small index mapping each
+// source (URL or OCI reference) to its digest and HTTP validators.
+//
+// Layout under Dir:
+//
+//	blobs/sha256/<hex>.cpack.tgz
+//	sources/<sha256 of source>.json
+type Cache struct {
+	// Dir defaults to $XDG_CACHE_HOME/composepack/charts.
+	Dir string
+	// Offline serves sources only from the cache and never touches the network.
+	Offline bool
+	// MaxSize rejects archives larger than this many bytes; 0 uses a 256 MiB cap.
+	MaxSize int64
+	client  *http.Client
+}
+
+// New returns a cache in the user's cache directory.
+func New() *Cache {
+	return &Cache{client: http.DefaultClient}
+}
+
+// Fetch returns a local path for an http(s) chart URL, revalidating a cached copy with
+// If-None-Match / If-Modified-Since instead of downloading it again.
+func (c *Cache) Fetch(ctx context.Context, url string) (string, error) {
+	entry, cached, err := c.Lookup(url)
+	if err != nil {
+		return "", err
+	}
+	if c.Offline {
+		if !cached {
+			return "", fmt.Errorf("%s: %w (offline)", url, ErrNotCached)
+		}
+		return entry.Path, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	if cached {
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download chart: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		entry.FetchedAt = time.Now().UTC()
+		if err := c.writeEntry(ctx, entry); err != nil {
+			return "", err
+		}
+		return entry.Path, nil
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("download chart: unexpected status %s", resp.Status)
+	case resp.ContentLength > c.maxSize():
+		return "", fmt.Errorf("download chart: %s declares %d bytes, more than the %d allowed", url, resp.ContentLength, c.maxSize())
+	}
+
+	stored, err := c.store(url, resp.Body)
+	if err != nil {
+		return "", err
+	}
+	stored.ETag = resp.Header.Get("ETag")
+	stored.LastModified = resp.Header.Get("Last-Modified")
+	if err := c.writeEntry(ctx, stored); err != nil {
+		return "", err
+	}
+	return stored.Path, nil
+}
+
+// Put stores data fetched from source and returns the cached archive path.
+func (c *Cache) Put(ctx context.Context, source string, data []byte) (string, error) {
+	entry, err := c.store(source, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	if err := c.writeEntry(ctx, entry); err != nil {
+		return "", err
+	}
+	return entry.Path, nil
+}
+
+// Lookup returns the cached entry for source; ok is false when it is missing or its
+// archive has been removed.
+func (c *Cache) Lookup(source string) (Entry, bool, error) {
+	dir, err := c.dir()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	entry, err := readEntry(filepath.Join(dir, "sources", sourceKey(source)+".json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Entry{}, false, nil
+		}
+		return Entry{}, false, err
+	}
+	entry.Path = c.blobPath(dir, entry.Digest)
+	if _, err := os.Stat(entry.Path); err != nil {
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// List returns every cached source, most recently fetched first.
+func (c *Cache) List() ([]Entry, error) {
+	dir, err := c.dir()
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(filepath.Join(dir, "sources"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read chart cache: %w", err)
+	}
+	var entries []Entry
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		entry, err := readEntry(filepath.Join(dir, "sources", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entry.Path = c.blobPath(dir, entry.Digest)
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].FetchedAt.After(entries[j].FetchedAt) })
+	return entries, nil
+}
+
+// Clean removes every cached chart and returns how many archives and bytes were freed.
+func (c *Cache) Clean() (int, int64, error) {
+	dir, err := c.dir()
+	if err != nil {
+		return 0, 0, err
+	}
+	count, size := 0, int64(0)
+	blobs := filepath.Join(dir, "blobs")
+	err = filepath.WalkDir(blobs, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			count++
+			size += info.Size()
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, 0, fmt.Errorf("scan chart cache: %w", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return 0, 0, fmt.Errorf("remove chart cache: %w", err)
+	}
+	return count, size, nil
+}
+
+// store copies r into the blob store and returns an entry for source (without validators).
+func (c *Cache) store(source string, r io.Reader) (Entry, error) {
+	dir, err := c.dir()
+	if err != nil {
+		return Entry{}, err
+	}
+	blobDir := filepath.Join(dir, "blobs", "sha256")
+	if err := fsutil.EnsureDir(blobDir); err != nil {
+		return Entry{}, fmt.Errorf("ensure chart cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(blobDir, ".download-*")
+	if err != nil {
+		return Entry{}, fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// read one byte past the limit so an oversized body is detected rather than truncated
+	limit := c.maxSize()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(r, limit+1))
+	if err != nil {
+		tmp.Close()
+		return Entry{}, fmt.Errorf("save %s: %w", source, err)
+	}
+	if size > limit {
+		tmp.Close()
+		return Entry{}, fmt.Errorf("save %s: more than the %d bytes allowed", source, limit)
+	}
+	if err := tmp.Close(); err != nil {
+		return Entry{}, err
+	}
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	path := c.blobPath(dir, digest)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Entry{}, fmt.Errorf("move cached chart: %w", err)
+	}
+	return Entry{Source: source, Digest: digest, Size: size, FetchedAt: time.Now().UTC(), Path: path}, nil
+}
+
+func (c *Cache) writeEntry(ctx context.Context, entry Entry) error {
+	dir, err := c.dir()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize cache entry: %w", err)
+	}
+	sources := filepath.Join(dir, "sources")
+	if err := fsutil.EnsureDir(sources); err != nil {
+		return fmt.Errorf("ensure chart cache: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(ctx, filepath.Join(sources, sourceKey(entry.Source)+".json"), data, 0o644); err != nil {
+		return fmt.Errorf("write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) dir() (string, error) {
+	if c.Dir != "" {
+		return c.Dir, nil
+	}
+	base, err := config.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "charts"), nil
+}
+
+func (c *Cache) maxSize() int64 {
+	if c.MaxSize > 0 {
+		return c.MaxSize
+	}
+	return defaultMaxSize
+}
+
+func (c *Cache) blobPath(dir, digest string) string {
+	return filepath.Join(dir, "blobs", "sha256", hexDigest(digest)+".cpack.tgz")
+}
+
+func (c *Cache) httpClient() *http.Client {
+	if c.client != nil {
+		return c.client
+	}
+	return http.DefaultClient
+}
+
+func readEntry(path string) (Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("parse cache entry %s: %w", path, err)
+	}
+	return entry, nil
+}
+
+func sourceKey(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+func hexDigest(digest string) string {
+	return strings.TrimPrefix(digest, "sha256:")
+}
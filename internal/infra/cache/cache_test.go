@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testCache(t *testing.T) *Cache {
+	c := New()
+	c.Dir = t.TempDir()
+	c.MaxSize = 1 << 10
+	return c
+}
+
+// blobs lists the archives in the blob store, temp downloads included.
+func blobs(t *testing.T, c *Cache) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(c.Dir, "blobs", "sha256"))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestFetchRevalidates(t *testing.T) {
+	requests, notModified := 0, 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("chart archive"))
+	}))
+	defer srv.Close()
+
+	c := testCache(t)
+	ctx := context.Background()
+	first, err := c.Fetch(ctx, srv.URL+"/demo.cpack.tgz")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if data, _ := os.ReadFile(first); string(data) != "chart archive" {
+		t.Errorf("cached archive = %q", data)
+	}
+	second, err := c.Fetch(ctx, srv.URL+"/demo.cpack.tgz")
+	if err != nil {
+		t.Fatalf("second Fetch: %v", err)
+	}
+	if second != first || requests != 2 || notModified != 1 {
+		t.Errorf("second Fetch = %s after %d requests (%d not modified), want the cached %s revalidated", second, requests, notModified, first)
+	}
+}
+
+func TestFetchRejectsOversizedArchive(t *testing.T) {
+	body := strings.Repeat("x", 2<<10)
+	tests := map[string]http.HandlerFunc{
+		"declared length": func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		},
+		// flushing before the body makes the response chunked, with no Content-Length
+		"chunked body": func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			w.Write([]byte(body))
+		},
+	}
+	for name, handler := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(handler)
+			defer srv.Close()
+
+			c := testCache(t)
+			_, err := c.Fetch(context.Background(), srv.URL+"/huge.cpack.tgz")
+			if err == nil || !strings.Contains(err.Error(), "1024") {
+				t.Fatalf("Fetch error = %v, want the size cap to be reported", err)
+			}
+			if left := blobs(t, c); len(left) != 0 {
+				t.Errorf("oversized download left %v in the cache", left)
+			}
+			if _, ok, _ := c.Lookup(srv.URL + "/huge.cpack.tgz"); ok {
+				t.Error("oversized download was indexed")
+			}
+		})
+	}
+}
+
+func TestPutLimit(t *testing.T) {
+	c := testCache(t)
+	ctx := context.Background()
+	if _, err := c.Put(ctx, "oci://example/demo:1", make([]byte, c.MaxSize)); err != nil {
+		t.Fatalf("Put at the limit: %v", err)
+	}
+	if _, err := c.Put(ctx, "oci://example/demo:2", make([]byte, c.MaxSize+1)); err == nil {
+		t.Fatal("Put over the limit succeeded")
+	}
+	if left := blobs(t, c); len(left) != 1 {
+		t.Errorf("blob store holds %v, want only the archive at the limit", left)
+	}
+}
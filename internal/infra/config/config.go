@@ -6,15 +6,25 @@ import (
 	ms "github.com/go-viper/mapstructure/v2"
 )
 
+// DefaultMaxHistory is the number of revisions kept per release unless configured otherwise.
+const DefaultMaxHistory = 10
+
 // Config contains process-wide settings derived from flags/env.
 type Config struct {
 	ReleasesBaseDir string `mapstructure:"releases_base_dir"`
+	MaxHistory      int    `mapstructure:"max_history"`
+	// MergeEngine selects how compose fragments are merged: auto, docker or native.
+	MergeEngine string `mapstructure:"merge_engine"`
+	// Offline serves remote charts only from the local chart cache.
+	Offline bool `mapstructure:"offline"`
 }
 
 // Default returns baseline configuration derived from the PRD runtime layout.
 func Default() Config {
 	return Config{
 		ReleasesBaseDir: ".cpack-releases",
+		MaxHistory:      DefaultMaxHistory,
+		MergeEngine:     "auto",
 	}
 }
 
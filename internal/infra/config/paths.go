@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UserConfigDir returns ComposePack's per-user settings directory
+// ($XDG_CONFIG_HOME/composepack, falling back to ~/.config/composepack).
+func UserConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// UserCacheDir returns ComposePack's per-user cache directory
+// ($XDG_CACHE_HOME/composepack, falling back to ~/.cache/composepack).
+func UserCacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache")
+}
+
+func xdgDir(env, fallback string) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, "composepack"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, fallback, "composepack"), nil
+}
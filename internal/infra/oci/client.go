@@ -0,0 +1,525 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Media types of ComposePack chart artifacts.
+const (
+	ManifestMediaType   = "application/vnd.oci.image.manifest.v1+json"
+	ConfigMediaType     = "application/vnd.composepack.chart.config.v1+json"
+	ChartLayerMediaType = "application/vnd.composepack.chart.content.v1.tar+gzip"
+	ProvenanceMediaType = "application/vnd.composepack.chart.provenance.v1.prov"
+)
+
+// maxManifestSize guards against registries returning something that is not a manifest.
+const maxManifestSize = 4 << 20
+
+// defaultMaxBlobSize caps blobs when Client.MaxBlobSize is unset.
+const defaultMaxBlobSize = 256 << 20
+
+// Descriptor references a blob in a registry.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest describing a chart artifact.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Artifact is a chart archive together with its config blob and optional provenance file.
+type Artifact struct {
+	Config      []byte
+	Archive     []byte
+	Provenance  []byte
+	Annotations map[string]string
+}
+
+// Client talks to OCI distribution registries (push/pull of chart artifacts).
+type Client struct {
+	HTTP        *http.Client
+	Credentials *CredentialStore
+	// PlainHTTP talks to every registry over http; localhost registries always use http.
+	PlainHTTP bool
+	// MaxBlobSize rejects blobs whose manifest declares more bytes; 0 uses a 256 MiB cap.
+	MaxBlobSize int64
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient returns a client that reuses Docker's stored registry credentials.
+func NewClient() *Client {
+	return &Client{HTTP: http.DefaultClient, Credentials: NewCredentialStore()}
+}
+
+// Push uploads artifact to ref (which must carry a tag) and returns the manifest digest.
+func (c *Client) Push(ctx context.Context, ref Reference, artifact Artifact) (string, error) {
+	if ref.Tag == "" {
+		return "", fmt.Errorf("reference %s must include a tag", ref)
+	}
+	scope := "repository:" + ref.Repository + ":pull,push"
+
+	config := Descriptor{MediaType: ConfigMediaType, Digest: digestOf(artifact.Config), Size: int64(len(artifact.Config))}
+	layer := Descriptor{MediaType: ChartLayerMediaType, Digest: digestOf(artifact.Archive), Size: int64(len(artifact.Archive))}
+	if err := c.pushBlob(ctx, ref, scope, config, artifact.Config); err != nil {
+		return "", err
+	}
+	if err := c.pushBlob(ctx, ref, scope, layer, artifact.Archive); err != nil {
+		return "", err
+	}
+	layers := []Descriptor{layer}
+	if len(artifact.Provenance) > 0 {
+		prov := Descriptor{MediaType: ProvenanceMediaType, Digest: digestOf(artifact.Provenance), Size: int64(len(artifact.Provenance))}
+		if err := c.pushBlob(ctx, ref, scope, prov, artifact.Provenance); err != nil {
+			return "", err
+		}
+		layers = append(layers, prov)
+	}
+
+	manifest, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		ArtifactType:  ConfigMediaType,
+		Config:        config,
+		Layers:        layers,
+		Annotations:   artifact.Annotations,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode manifest: %w", err)
+	}
+	resp, err := c.do(ctx, ref.Registry, scope, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.url(ref, "/manifests/"+ref.Tag), bytes.NewReader(manifest))
+		if err == nil {
+			req.Header.Set("Content-Type", ManifestMediaType)
+		}
+		return req, err
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", registryError("push manifest", resp)
+	}
+	return digestOf(manifest), nil
+}
+
+// Pull downloads the chart artifact referenced by ref and verifies every blob digest.
+func (c *Client) Pull(ctx context.Context, ref Reference) (*Artifact, string, error) {
+	if ref.version() == "" {
+		return nil, "", fmt.Errorf("reference %s must include a tag or digest", ref)
+	}
+	scope := "repository:" + ref.Repository + ":pull"
+
+	resp, err := c.do(ctx, ref.Registry, scope, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(ref, "/manifests/"+ref.version()), nil)
+		if err == nil {
+			req.Header.Set("Accept", ManifestMediaType)
+		}
+		return req, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", registryError("fetch manifest", resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, "", fmt.Errorf("read manifest: %w", err)
+	}
+	manifestDigest := digestOf(data)
+	if ref.Digest != "" && ref.Digest != manifestDigest {
+		return nil, "", fmt.Errorf("manifest digest mismatch: got %s, want %s", manifestDigest, ref.Digest)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, "", fmt.Errorf("parse manifest: %w", err)
+	}
+	if manifest.Config.MediaType != ConfigMediaType {
+		return nil, "", fmt.Errorf("%s is not a ComposePack chart (config media type %q)", ref, manifest.Config.MediaType)
+	}
+	var layer, prov *Descriptor
+	for i := range manifest.Layers {
+		switch manifest.Layers[i].MediaType {
+		case ChartLayerMediaType:
+			if layer == nil {
+				layer = &manifest.Layers[i]
+			}
+		case ProvenanceMediaType:
+			if prov == nil {
+				prov = &manifest.Layers[i]
+			}
+		}
+	}
+	if layer == nil {
+		return nil, "", fmt.Errorf("%s has no %s layer", ref, ChartLayerMediaType)
+	}
+
+	config, err := c.fetchBlob(ctx, ref, scope, manifest.Config)
+	if err != nil {
+		return nil, "", err
+	}
+	archive, err := c.fetchBlob(ctx, ref, scope, *layer)
+	if err != nil {
+		return nil, "", err
+	}
+	artifact := &Artifact{Config: config, Archive: archive, Annotations: manifest.Annotations}
+	if prov != nil {
+		if artifact.Provenance, err = c.fetchBlob(ctx, ref, scope, *prov); err != nil {
+			return nil, "", err
+		}
+	}
+	return artifact, manifestDigest, nil
+}
+
+// Login verifies cred against registry and stores it in Docker's config.json.
+func (c *Client) Login(ctx context.Context, registry string, cred Credential) error {
+	resp, err := c.doWith(ctx, registry, "", &cred, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL(registry)+"/v2/", nil)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return registryError("login", resp)
+	}
+	return c.Credentials.Store(ctx, registry, cred)
+}
+
+// Logout removes stored credentials for registry.
+func (c *Client) Logout(ctx context.Context, registry string) error {
+	return c.Credentials.Erase(ctx, registry)
+}
+
+func (c *Client) pushBlob(ctx context.Context, ref Reference, scope string, desc Descriptor, data []byte) error {
+	resp, err := c.do(ctx, ref.Registry, scope, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodHead, c.url(ref, "/blobs/"+desc.Digest), nil)
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, ref.Registry, scope, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, c.url(ref, "/blobs/uploads/"), nil)
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return registryError("start blob upload", resp)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return fmt.Errorf("start blob upload: missing upload location: %w", err)
+	}
+	query := location.Query()
+	query.Set("digest", desc.Digest)
+	location.RawQuery = query.Encode()
+
+	resp, err = c.do(ctx, ref.Registry, scope, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, location.String(), bytes.NewReader(data))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/octet-stream")
+			req.ContentLength = int64(len(data))
+		}
+		return req, err
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return registryError("upload blob", resp)
+	}
+	return nil
+}
+
+func (c *Client) fetchBlob(ctx context.Context, ref Reference, scope string, desc Descriptor) ([]byte, error) {
+	// the size comes from the registry's own manifest, so bound it before reading
+	limit := c.MaxBlobSize
+	if limit <= 0 {
+		limit = defaultMaxBlobSize
+	}
+	if desc.Size < 0 || desc.Size > limit {
+		return nil, fmt.Errorf("blob %s declares %d bytes, more than the %d allowed", desc.Digest, desc.Size, limit)
+	}
+	resp, err := c.do(ctx, ref.Registry, scope, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, c.url(ref, "/blobs/"+desc.Digest), nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, registryError("fetch blob", resp)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, desc.Size+1))
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", desc.Digest, err)
+	}
+	if int64(len(data)) != desc.Size || digestOf(data) != desc.Digest {
+		return nil, fmt.Errorf("blob %s failed verification", desc.Digest)
+	}
+	return data, nil
+}
+
+// do sends a request, answering a 401 challenge once with stored credentials.
+func (c *Client) do(ctx context.Context, registry, scope string, build func() (*http.Request, error)) (*http.Response, error) {
+	return c.doWith(ctx, registry, scope, nil, build)
+}
+
+func (c *Client) doWith(ctx context.Context, registry, scope string, cred *Credential, build func() (*http.Request, error)) (*http.Response, error) {
+	// uploads may be redirected to another host (blob storage, say); the registry's
+	// credentials and tokens are only ever sent to the registry itself
+	toRegistry := false
+	send := func() (*http.Response, error) {
+		req, err := build()
+		if err != nil {
+			return nil, fmt.Errorf("build request: %w", err)
+		}
+		toRegistry = c.isRegistryURL(registry, req.URL)
+		if auth := c.token(registry, scope); auth != "" && toRegistry {
+			req.Header.Set("Authorization", auth)
+		}
+		resp, err := c.httpClient().Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("contact registry %s: %w", registry, err)
+		}
+		return resp, nil
+	}
+
+	resp, err := send()
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !toRegistry {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+
+	if cred == nil {
+		stored, ok, err := c.Credentials.Get(ctx, registry)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cred = &stored
+		}
+	}
+	if err := c.authenticate(ctx, registry, scope, challenge, cred); err != nil {
+		return nil, err
+	}
+	return send()
+}
+
+// authenticate answers a Basic or Bearer (token service) challenge and caches the header.
+func (c *Client) authenticate(ctx context.Context, registry, scope, challenge string, cred *Credential) error {
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if cred == nil {
+			return fmt.Errorf("registry %s requires authentication (run composepack registry login %s)", registry, registry)
+		}
+		c.setToken(registry, scope, "Basic "+basicAuth(*cred))
+		return nil
+	case "bearer":
+	default:
+		return fmt.Errorf("registry %s: unsupported authentication challenge %q", registry, challenge)
+	}
+
+	realm := params["realm"]
+	if realm == "" {
+		return fmt.Errorf("registry %s: bearer challenge without realm", registry)
+	}
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return fmt.Errorf("registry %s: invalid token realm: %w", registry, err)
+	}
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if s := params["scope"]; s != "" {
+		query.Set("scope", s)
+	} else if scope != "" {
+		query.Set("scope", scope)
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("build token request: %w", err)
+	}
+	if cred != nil {
+		req.Header.Set("Authorization", "Basic "+basicAuth(*cred))
+	}
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		if cred == nil {
+			return fmt.Errorf("registry %s requires authentication (run composepack registry login %s)", registry, registry)
+		}
+		return registryError("fetch registry token", resp)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("parse registry token: %w", err)
+	}
+	value := token.Token
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return fmt.Errorf("registry %s returned an empty token", registry)
+	}
+	c.setToken(registry, scope, "Bearer "+value)
+	return nil
+}
+
+func (c *Client) token(registry, scope string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[registry+"|"+scope]
+}
+
+func (c *Client) setToken(registry, scope, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[registry+"|"+scope] = value
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTP != nil {
+		return c.HTTP
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) url(ref Reference, suffix string) string {
+	return c.baseURL(ref.Registry) + "/v2/" + ref.Repository + suffix
+}
+
+func (c *Client) baseURL(registry string) string {
+	scheme := "https"
+	if c.PlainHTTP || isLocalhost(registry) {
+		scheme = "http"
+	}
+	if isDockerHub(registry) {
+		registry = "registry-1.docker.io"
+	}
+	return scheme + "://" + registry
+}
+
+// isRegistryURL reports whether u points at registry itself, with the same scheme.
+func (c *Client) isRegistryURL(registry string, u *url.URL) bool {
+	base, err := url.Parse(c.baseURL(registry))
+	return err == nil && u.Scheme == base.Scheme && strings.EqualFold(u.Host, base.Host)
+}
+
+func isLocalhost(registry string) bool {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// parseChallenge splits `Bearer realm="...",service="...",scope="..."` into scheme and params.
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		var value string
+		if strings.HasPrefix(after, `"`) {
+			end := strings.Index(after[1:], `"`)
+			if end < 0 {
+				value, rest = after[1:], ""
+			} else {
+				value, rest = after[1:end+1], after[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(after, ",")
+		}
+		params[key] = value
+	}
+	return scheme, params
+}
+
+func basicAuth(cred Credential) string {
+	return base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func registryError(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var payload struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &payload) == nil && len(payload.Errors) > 0 {
+		msgs := make([]string, 0, len(payload.Errors))
+		for _, e := range payload.Errors {
+			msgs = append(msgs, strings.TrimSpace(e.Code+": "+e.Message))
+		}
+		return fmt.Errorf("%s: %s: %s", action, resp.Status, strings.Join(msgs, "; "))
+	}
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Errorf("%s: %s: %s", action, resp.Status, msg)
+	}
+	return errors.New(action + ": " + resp.Status)
+}
@@ -0,0 +1,307 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry is a minimal OCI distribution registry kept in memory.
+type fakeRegistry struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+	fetched   []string
+
+	// token, when set, requires `Bearer <token>`, issued by /token for user:pass.
+	token      string
+	user, pass string
+	// tamper rewrites blobs as they are served.
+	tamper func(data []byte) []byte
+	// uploadURL, when set, hands blob uploads off to another server.
+	uploadURL string
+}
+
+func newFakeRegistry(t *testing.T) *fakeRegistry {
+	r := &fakeRegistry{t: t, blobs: map[string][]byte{}, manifests: map[string][]byte{}}
+	r.srv = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.srv.Close)
+	return r
+}
+
+// host is the registry address; loopback registries are reached over plain http.
+func (r *fakeRegistry) host() string {
+	return strings.TrimPrefix(r.srv.URL, "http://")
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		user, pass, ok := req.BasicAuth()
+		if !ok || user != r.user || pass != r.pass {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": r.token})
+		return
+	}
+	if r.token != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, r.srv.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case path == "":
+		w.WriteHeader(http.StatusOK)
+	case strings.Contains(path, "/blobs/uploads/"):
+		repo, id, _ := strings.Cut(path, "/blobs/uploads/")
+		if req.Method == http.MethodPost {
+			r.uploads++
+			w.Header().Set("Location", fmt.Sprintf("%s/v2/%s/blobs/uploads/%d", r.uploadURL, repo, r.uploads))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		data, _ := io.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if id == "" || digestOf(data) != digest {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = data
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		_, digest, _ := strings.Cut(path, "/blobs/")
+		data, ok := r.blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.Method == http.MethodGet {
+			r.fetched = append(r.fetched, digest)
+			if r.tamper != nil {
+				data = r.tamper(data)
+			}
+			w.Write(data)
+		}
+	case strings.Contains(path, "/manifests/"):
+		repo, version, _ := strings.Cut(path, "/manifests/")
+		if req.Method == http.MethodPut {
+			data, _ := io.ReadAll(req.Body)
+			r.manifests[repo+":"+version] = data
+			r.manifests[repo+"@"+digestOf(data)] = data
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		sep := ":"
+		if strings.HasPrefix(version, "sha256:") {
+			sep = "@"
+		}
+		data, ok := r.manifests[repo+sep+version]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testClient(t *testing.T) *Client {
+	return &Client{Credentials: &CredentialStore{Path: filepath.Join(t.TempDir(), "config.json")}}
+}
+
+func testArtifact() Artifact {
+	return Artifact{
+		Config:      []byte(`{"name":"demo","version":"1.0.0"}`),
+		Archive:     []byte("not really a tarball"),
+		Provenance:  []byte("signed"),
+		Annotations: map[string]string{"org.opencontainers.image.title": "demo"},
+	}
+}
+
+func TestPushPull(t *testing.T) {
+	reg := newFakeRegistry(t)
+	client := testClient(t)
+	ctx := context.Background()
+	ref := Reference{Registry: reg.host(), Repository: "charts/demo", Tag: "1.0.0"}
+
+	want := testArtifact()
+	digest, err := client.Push(ctx, ref, want)
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	for _, pullRef := range []Reference{ref, {Registry: ref.Registry, Repository: ref.Repository, Digest: digest}} {
+		got, gotDigest, err := client.Pull(ctx, pullRef)
+		if err != nil {
+			t.Fatalf("Pull %s: %v", pullRef, err)
+		}
+		if gotDigest != digest {
+			t.Errorf("Pull %s digest = %s, want %s", pullRef, gotDigest, digest)
+		}
+		if !bytes.Equal(got.Archive, want.Archive) || !bytes.Equal(got.Config, want.Config) || !bytes.Equal(got.Provenance, want.Provenance) {
+			t.Errorf("Pull %s returned %+v, want %+v", pullRef, got, want)
+		}
+		if got.Annotations["org.opencontainers.image.title"] != "demo" {
+			t.Errorf("Pull %s annotations = %v", pullRef, got.Annotations)
+		}
+	}
+
+	// pushing again reuses the blobs already in the registry
+	uploads := reg.uploads
+	if _, err := client.Push(ctx, ref.WithTag("1.0.1"), want); err != nil {
+		t.Fatalf("second Push: %v", err)
+	}
+	if reg.uploads != uploads {
+		t.Errorf("second Push started %d uploads, want none", reg.uploads-uploads)
+	}
+}
+
+func TestTokenAuth(t *testing.T) {
+	reg := newFakeRegistry(t)
+	reg.token, reg.user, reg.pass = "s3cret-token", "alice", "pw"
+	ctx := context.Background()
+	ref := Reference{Registry: reg.host(), Repository: "charts/demo", Tag: "1.0.0"}
+
+	anonymous := testClient(t)
+	if _, err := anonymous.Push(ctx, ref, testArtifact()); err == nil || !strings.Contains(err.Error(), "requires authentication") {
+		t.Fatalf("anonymous Push error = %v, want an authentication error", err)
+	}
+
+	client := testClient(t)
+	if err := client.Login(ctx, reg.host(), Credential{Username: "alice", Password: "wrong"}); err == nil {
+		t.Fatal("Login with a wrong password succeeded")
+	}
+	if err := client.Login(ctx, reg.host(), Credential{Username: "alice", Password: "pw"}); err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+
+	// a fresh client picks the stored credentials up from config.json
+	fresh := &Client{Credentials: client.Credentials}
+	if _, err := fresh.Push(ctx, ref, testArtifact()); err != nil {
+		t.Fatalf("Push after login: %v", err)
+	}
+	if _, _, err := fresh.Pull(ctx, ref); err != nil {
+		t.Fatalf("Pull after login: %v", err)
+	}
+}
+
+func TestPullDigestMismatch(t *testing.T) {
+	reg := newFakeRegistry(t)
+	client := testClient(t)
+	ctx := context.Background()
+	ref := Reference{Registry: reg.host(), Repository: "charts/demo", Tag: "1.0.0"}
+	if _, err := client.Push(ctx, ref, testArtifact()); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	wrong := Reference{Registry: ref.Registry, Repository: ref.Repository, Digest: digestOf([]byte("other"))}
+	reg.manifests[ref.Repository+"@"+wrong.Digest] = reg.manifests[ref.Repository+":1.0.0"]
+	if _, _, err := client.Pull(ctx, wrong); err == nil || !strings.Contains(err.Error(), "manifest digest mismatch") {
+		t.Errorf("Pull with a wrong manifest digest error = %v", err)
+	}
+
+	reg.tamper = func(data []byte) []byte {
+		out := append([]byte{}, data...)
+		out[0] ^= 0xff
+		return out
+	}
+	if _, _, err := client.Pull(ctx, ref); err == nil || !strings.Contains(err.Error(), "failed verification") {
+		t.Errorf("Pull of a tampered blob error = %v", err)
+	}
+}
+
+func TestPullRejectsOversizedBlob(t *testing.T) {
+	reg := newFakeRegistry(t)
+	client := testClient(t)
+	client.MaxBlobSize = 1 << 10
+	ctx := context.Background()
+	ref := Reference{Registry: reg.host(), Repository: "charts/demo", Tag: "1.0.0"}
+
+	config := []byte(`{}`)
+	reg.blobs[digestOf(config)] = config
+	huge := Descriptor{MediaType: ChartLayerMediaType, Digest: digestOf([]byte("x")), Size: 1 << 40}
+	reg.blobs[huge.Digest] = []byte("x")
+	manifest, _ := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		Config:        Descriptor{MediaType: ConfigMediaType, Digest: digestOf(config), Size: int64(len(config))},
+		Layers:        []Descriptor{huge},
+	})
+	reg.manifests[ref.Repository+":1.0.0"] = manifest
+
+	if _, _, err := client.Pull(ctx, ref); err == nil || !strings.Contains(err.Error(), "more than the 1024 allowed") {
+		t.Fatalf("Pull error = %v, want the blob size to be rejected", err)
+	}
+	for _, digest := range reg.fetched {
+		if digest == huge.Digest {
+			t.Error("oversized blob was requested from the registry")
+		}
+	}
+}
+
+func TestPushKeepsCredentialsOnTheRegistry(t *testing.T) {
+	for _, challenge := range []bool{false, true} {
+		t.Run(fmt.Sprintf("storage challenges: %v", challenge), func(t *testing.T) {
+			reg := newFakeRegistry(t)
+			reg.token, reg.user, reg.pass = "s3cret-token", "alice", "pw"
+
+			var mu sync.Mutex
+			var leaked []string
+			storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if auth := req.Header.Get("Authorization"); auth != "" {
+					leaked = append(leaked, req.URL.Path+": "+auth)
+				}
+				if challenge {
+					w.Header().Set("WWW-Authenticate", `Basic realm="storage"`)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				data, _ := io.ReadAll(req.Body)
+				reg.mu.Lock()
+				reg.blobs[req.URL.Query().Get("digest")] = data
+				reg.mu.Unlock()
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer storage.Close()
+			reg.uploadURL = storage.URL
+
+			client := testClient(t)
+			ctx := context.Background()
+			if err := client.Login(ctx, reg.host(), Credential{Username: "alice", Password: "pw"}); err != nil {
+				t.Fatalf("Login: %v", err)
+			}
+			ref := Reference{Registry: reg.host(), Repository: "charts/demo", Tag: "1.0.0"}
+			_, err := client.Push(ctx, ref, testArtifact())
+			if challenge {
+				if err == nil || !strings.Contains(err.Error(), "upload blob") {
+					t.Errorf("Push error = %v, want the storage's refusal", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Push: %v", err)
+			}
+			if len(leaked) > 0 {
+				t.Errorf("upload host received registry credentials: %q", leaked)
+			}
+		})
+	}
+}
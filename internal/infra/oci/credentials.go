@@ -0,0 +1,262 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"composepack/internal/infra/process"
+	"composepack/internal/util/fsutil"
+)
+
+const dockerHubConfigKey = "https://index.docker.io/v1/"
+
+// Credential is a username/password (or token) pair for a registry.
+type Credential struct {
+	Username string
+	Password string
+}
+
+// CredentialStore reads and writes registry credentials in Docker's config.json so logins are
+// shared with the docker CLI, including credential helpers (`credsStore` / `credHelpers`).
+type CredentialStore struct {
+	// Path is the config.json location; empty resolves $DOCKER_CONFIG or ~/.docker.
+	Path   string
+	runner *process.Runner
+}
+
+// NewCredentialStore returns a store backed by the default Docker config file.
+func NewCredentialStore() *CredentialStore {
+	return &CredentialStore{runner: process.NewRunner()}
+}
+
+type dockerAuth struct {
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+}
+
+// Get returns the credential stored for registry; ok is false when none is configured.
+func (s *CredentialStore) Get(ctx context.Context, registry string) (Credential, bool, error) {
+	raw, err := s.load()
+	if err != nil {
+		return Credential{}, false, err
+	}
+
+	if helper := s.helperFor(raw, registry); helper != "" {
+		return s.helperGet(ctx, helper, configKey(registry))
+	}
+
+	var auths map[string]dockerAuth
+	if data, ok := raw["auths"]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return Credential{}, false, fmt.Errorf("parse docker config auths: %w", err)
+		}
+	}
+	for _, key := range lookupKeys(registry) {
+		entry, ok := auths[key]
+		if !ok || entry.Auth == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return Credential{}, false, fmt.Errorf("decode credentials for %s: %w", registry, err)
+		}
+		user, pass, _ := strings.Cut(string(decoded), ":")
+		return Credential{Username: user, Password: pass}, true, nil
+	}
+	return Credential{}, false, nil
+}
+
+// Store saves cred for registry, using the configured credential helper when there is one.
+func (s *CredentialStore) Store(ctx context.Context, registry string, cred Credential) error {
+	raw, err := s.load()
+	if err != nil {
+		return err
+	}
+	key := configKey(registry)
+	if helper := s.helperFor(raw, registry); helper != "" {
+		payload, _ := json.Marshal(map[string]string{"ServerURL": key, "Username": cred.Username, "Secret": cred.Password})
+		_, err := s.helper(ctx, helper, "store", payload)
+		return err
+	}
+
+	auths := map[string]dockerAuth{}
+	if data, ok := raw["auths"]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return fmt.Errorf("parse docker config auths: %w", err)
+		}
+	}
+	auths[key] = dockerAuth{Auth: base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))}
+	return s.save(raw, auths)
+}
+
+// Erase removes the credential stored for registry.
+func (s *CredentialStore) Erase(ctx context.Context, registry string) error {
+	raw, err := s.load()
+	if err != nil {
+		return err
+	}
+	if helper := s.helperFor(raw, registry); helper != "" {
+		_, err := s.helper(ctx, helper, "erase", []byte(configKey(registry)))
+		return err
+	}
+
+	auths := map[string]dockerAuth{}
+	if data, ok := raw["auths"]; ok {
+		if err := json.Unmarshal(data, &auths); err != nil {
+			return fmt.Errorf("parse docker config auths: %w", err)
+		}
+	}
+	found := false
+	for _, key := range lookupKeys(registry) {
+		if _, ok := auths[key]; ok {
+			delete(auths, key)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("not logged in to %s", registry)
+	}
+	return s.save(raw, auths)
+}
+
+func (s *CredentialStore) path() (string, error) {
+	if s.Path != "" {
+		return s.Path, nil
+	}
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// load returns the top-level keys of config.json so unrelated settings survive a save.
+func (s *CredentialStore) load() (map[string]json.RawMessage, error) {
+	path, err := s.path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]json.RawMessage{}, nil
+		}
+		return nil, fmt.Errorf("read docker config: %w", err)
+	}
+	raw := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("parse docker config %s: %w", path, err)
+		}
+	}
+	return raw, nil
+}
+
+func (s *CredentialStore) save(raw map[string]json.RawMessage, auths map[string]dockerAuth) error {
+	path, err := s.path()
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(auths)
+	if err != nil {
+		return err
+	}
+	raw["auths"] = encoded
+	data, err := json.MarshalIndent(raw, "", "\t")
+	if err != nil {
+		return fmt.Errorf("serialize docker config: %w", err)
+	}
+	if err := fsutil.EnsureDir(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("ensure docker config dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(context.Background(), path, data, 0o600); err != nil {
+		return fmt.Errorf("write docker config: %w", err)
+	}
+	return nil
+}
+
+func (s *CredentialStore) helperFor(raw map[string]json.RawMessage, registry string) string {
+	var helpers map[string]string
+	if data, ok := raw["credHelpers"]; ok {
+		_ = json.Unmarshal(data, &helpers)
+	}
+	for _, key := range lookupKeys(registry) {
+		if helper := helpers[key]; helper != "" {
+			return helper
+		}
+	}
+	var store string
+	if data, ok := raw["credsStore"]; ok {
+		_ = json.Unmarshal(data, &store)
+	}
+	return store
+}
+
+func (s *CredentialStore) helperGet(ctx context.Context, helper, serverURL string) (Credential, bool, error) {
+	out, err := s.helper(ctx, helper, "get", []byte(serverURL))
+	if err != nil {
+		if strings.Contains(err.Error(), "credentials not found") {
+			return Credential{}, false, nil
+		}
+		return Credential{}, false, err
+	}
+	var resp struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return Credential{}, false, fmt.Errorf("parse docker-credential-%s output: %w", helper, err)
+	}
+	return Credential{Username: resp.Username, Password: resp.Secret}, resp.Secret != "", nil
+}
+
+func (s *CredentialStore) helper(ctx context.Context, helper, action string, input []byte) ([]byte, error) {
+	runner := s.runner
+	if runner == nil {
+		runner = process.NewRunner()
+	}
+	var stdout bytes.Buffer
+	stderr, err := runner.Stream(ctx, process.Command{Name: "docker-credential-" + helper, Args: []string{action}}, process.Streams{
+		Stdin:  bytes.NewReader(input),
+		Stdout: &stdout,
+	})
+	if err != nil {
+		msg := strings.TrimSpace(stdout.String() + " " + string(stderr))
+		return nil, fmt.Errorf("docker-credential-%s %s: %w: %s", helper, action, err, msg)
+	}
+	return stdout.Bytes(), nil
+}
+
+// configKey is the key Docker uses for registry in config.json.
+func configKey(registry string) string {
+	if isDockerHub(registry) {
+		return dockerHubConfigKey
+	}
+	return registry
+}
+
+func lookupKeys(registry string) []string {
+	if isDockerHub(registry) {
+		return []string{dockerHubConfigKey, "docker.io", "registry-1.docker.io"}
+	}
+	return []string{registry, "https://" + registry, "http://" + registry}
+}
+
+func isDockerHub(registry string) bool {
+	switch registry {
+	case "docker.io", "index.docker.io", "registry-1.docker.io":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package oci
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Scheme prefixes chart references stored in OCI registries.
+const Scheme = "oci://"
+
+// Reference points at a repository in an OCI registry, optionally pinned to a tag or digest.
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// IsReference reports whether source uses the oci:// scheme.
+func IsReference(source string) bool {
+	return strings.HasPrefix(strings.ToLower(source), Scheme)
+}
+
+// ParseReference parses `oci://registry/repository[:tag|@digest]`.
+func ParseReference(source string) (Reference, error) {
+	if !IsReference(source) {
+		return Reference{}, fmt.Errorf("reference %q must start with %s", source, Scheme)
+	}
+	rest := source[len(Scheme):]
+	registry, path, ok := strings.Cut(rest, "/")
+	if !ok || registry == "" || path == "" {
+		return Reference{}, fmt.Errorf("reference %q must include a registry and repository", source)
+	}
+
+	ref := Reference{Registry: registry}
+	if repo, digest, ok := strings.Cut(path, "@"); ok {
+		if !strings.HasPrefix(digest, "sha256:") {
+			return Reference{}, fmt.Errorf("reference %q: unsupported digest %q", source, digest)
+		}
+		ref.Repository, ref.Digest = repo, digest
+	} else {
+		ref.Repository = path
+		if idx := strings.LastIndex(path, ":"); idx > strings.LastIndex(path, "/") {
+			ref.Repository, ref.Tag = path[:idx], path[idx+1:]
+		}
+	}
+	if ref.Repository == "" || strings.HasSuffix(ref.Repository, "/") {
+		return Reference{}, fmt.Errorf("reference %q has an invalid repository", source)
+	}
+	if ref.Repository != strings.ToLower(ref.Repository) {
+		return Reference{}, fmt.Errorf("reference %q: repository must be lowercase", source)
+	}
+	return ref, nil
+}
+
+// WithTag returns a copy of the reference pointing at tag.
+func (r Reference) WithTag(tag string) Reference {
+	r.Tag, r.Digest = tag, ""
+	return r
+}
+
+// String renders the reference in oci:// form.
+func (r Reference) String() string {
+	s := Scheme + r.Registry + "/" + r.Repository
+	switch {
+	case r.Digest != "":
+		s += "@" + r.Digest
+	case r.Tag != "":
+		s += ":" + r.Tag
+	}
+	return s
+}
+
+// version returns the tag or digest used in manifest URLs.
+func (r Reference) version() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
@@ -4,8 +4,19 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"os"
 	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	// stderrTailLimit bounds how much stderr Stream keeps around for error reporting.
+	stderrTailLimit = 64 * 1024
+	// DefaultGracePeriod is how long a child gets to exit after SIGINT/SIGTERM before it is killed.
+	DefaultGracePeriod = 10 * time.Second
 )
 
 // Command describes a process invocation.
@@ -16,12 +27,46 @@ type Command struct {
 	Env  []string
 }
 
+// Streams wires a child process to caller-provided readers/writers.
+type Streams struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// TTY hands terminal files straight to the child so it can detect an interactive session.
+	// Stderr is not captured in this mode because the child writes to the terminal directly.
+	TTY bool
+}
+
+// ExitError reports a child process that exited with a non-zero status.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements error.
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap exposes the underlying *exec.ExitError.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the status the child exited with (128+signal when it was killed by a signal).
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
 // Runner centralizes os/exec usage so we can stub it in tests.
-type Runner struct{}
+type Runner struct {
+	// GracePeriod bounds how long a signalled or cancelled child may take to exit before it is killed.
+	GracePeriod time.Duration
+}
 
 // NewRunner constructs a process runner.
 func NewRunner() *Runner {
-	return &Runner{}
+	return &Runner{GracePeriod: DefaultGracePeriod}
 }
 
 // Run executes a command and returns stdout/stderr once it completes.
@@ -30,6 +75,46 @@ func (r *Runner) Run(ctx context.Context, cmd Command) ([]byte, []byte, error) {
 		return nil, nil, errors.New("command name is required")
 	}
 
+	command := r.build(ctx, cmd, false)
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	err := r.execute(command, false)
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// Stream executes a command with its stdio attached to streams while it runs.
+// The returned bytes hold the tail of stderr so callers can still build error messages.
+func (r *Runner) Stream(ctx context.Context, cmd Command, streams Streams) ([]byte, error) {
+	if cmd.Name == "" {
+		return nil, errors.New("command name is required")
+	}
+
+	command := r.build(ctx, cmd, streams.TTY)
+	command.Stdin = streams.Stdin
+	command.Stdout = streams.Stdout
+	if command.Stdout == nil {
+		command.Stdout = io.Discard
+	}
+
+	stderr := &tailBuffer{limit: stderrTailLimit}
+	switch {
+	case streams.TTY && isFile(streams.Stderr):
+		command.Stderr = streams.Stderr
+	case streams.Stderr != nil:
+		command.Stderr = io.MultiWriter(streams.Stderr, stderr)
+	default:
+		command.Stderr = stderr
+	}
+
+	err := r.execute(command, streams.TTY)
+	return stderr.Bytes(), err
+}
+
+func (r *Runner) build(ctx context.Context, cmd Command, tty bool) *exec.Cmd {
 	command := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
 	if cmd.Dir != "" {
 		command.Dir = cmd.Dir
@@ -37,14 +122,112 @@ func (r *Runner) Run(ctx context.Context, cmd Command) ([]byte, []byte, error) {
 	if len(cmd.Env) > 0 {
 		command.Env = append(os.Environ(), cmd.Env...)
 	}
+	// Cancellation asks the child to stop gracefully; WaitDelay escalates to a kill.
+	command.Cancel = func() error {
+		if err := command.Process.Signal(syscall.SIGTERM); err != nil {
+			return command.Process.Kill()
+		}
+		return nil
+	}
+	command.WaitDelay = r.gracePeriod()
+	configureProcessGroup(command, tty)
+	return command
+}
 
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	command.Stdout = &stdout
-	command.Stderr = &stderr
+// execute starts the command and relays SIGINT/SIGTERM to it until it exits.
+// Children sharing our terminal already receive keyboard signals from the TTY, so only
+// SIGTERM is relayed to them; detached children get every signal forwarded.
+func (r *Runner) execute(command *exec.Cmd, tty bool) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
 
-	err := command.Run()
-	return stdout.Bytes(), stderr.Bytes(), err
+	if err := command.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- command.Wait()
+	}()
+
+	var deadline <-chan time.Time
+	for {
+		select {
+		case err := <-done:
+			return wrapExitError(err)
+		case sig := <-signals:
+			if !tty || sig != os.Interrupt {
+				if err := command.Process.Signal(sig); err != nil {
+					_ = command.Process.Kill()
+				}
+			}
+			if deadline == nil {
+				deadline = time.After(r.gracePeriod())
+			}
+		case <-deadline:
+			_ = command.Process.Kill()
+			deadline = nil
+		}
+	}
+}
+
+func (r *Runner) gracePeriod() time.Duration {
+	if r.GracePeriod <= 0 {
+		return DefaultGracePeriod
+	}
+	return r.GracePeriod
+}
+
+func wrapExitError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	code := exitErr.ExitCode()
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		code = 128 + int(status.Signal())
+	}
+	if code <= 0 {
+		code = 1
+	}
+	return &ExitError{Code: code, Err: err}
+}
+
+// IsTerminal reports whether the stream is a character device such as an interactive terminal.
+func IsTerminal(stream any) bool {
+	file, ok := stream.(*os.File)
+	if !ok || file == nil {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func isFile(stream any) bool {
+	file, ok := stream.(*os.File)
+	return ok && file != nil
+}
+
+// tailBuffer keeps only the most recent bytes written to it.
+type tailBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) Bytes() []byte {
+	return t.buf
 }
 
 // IsNotFound reports whether the error indicates the command binary was not found.
@@ -0,0 +1,55 @@
+//go:build !windows
+
+package process
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRunPropagatesExitCode(t *testing.T) {
+	r := NewRunner()
+	_, stderr, err := r.Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "echo failed >&2; exit 3"}})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("Run error = %v, want exit code 3", err)
+	}
+	if string(stderr) != "failed\n" {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestStreamPropagatesExitCode(t *testing.T) {
+	tests := map[string]struct {
+		script string
+		code   int
+	}{
+		"exit status": {"echo out; exit 3", 3},
+		"signal":      {"echo out; kill -TERM $$", 128 + 15},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var stdout bytes.Buffer
+			_, err := NewRunner().Stream(context.Background(), Command{Name: "sh", Args: []string{"-c", tt.script}}, Streams{Stdout: &stdout})
+			// callers wrap the error; the code must survive that
+			wrapped := fmt.Errorf("docker compose failed: %w", err)
+			var coded interface{ ExitCode() int }
+			if !errors.As(wrapped, &coded) || coded.ExitCode() != tt.code {
+				t.Fatalf("Stream error = %v, want exit code %d", err, tt.code)
+			}
+			if stdout.String() != "out\n" {
+				t.Errorf("stdout = %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunSucceeds(t *testing.T) {
+	stdout, _, err := NewRunner().Run(context.Background(), Command{Name: "sh", Args: []string{"-c", "echo ok"}})
+	if err != nil || string(stdout) != "ok\n" {
+		t.Errorf("Run = %q, %v", stdout, err)
+	}
+}
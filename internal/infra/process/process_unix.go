@@ -0,0 +1,17 @@
+//go:build !windows
+
+package process
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureProcessGroup detaches non-interactive children into their own process group so
+// terminal signals reach them only through Runner's forwarding, never twice.
+func configureProcessGroup(command *exec.Cmd, tty bool) {
+	if tty {
+		return
+	}
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package process
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on Windows where console signals are delivered per console.
+func configureProcessGroup(command *exec.Cmd, tty bool) {}
@@ -7,12 +7,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"composepack/internal/core/chart"
+	"composepack/internal/provenance"
 )
 
 // Options controls packaging behavior.
@@ -21,6 +23,8 @@ type Options struct {
 	Destination string
 	OutputName  string
 	Force       bool
+	// SigningKey, when set, is an ed25519 PEM private key used to write <archive>.prov.
+	SigningKey string
 }
 
 // PackageChart produces a .cpack.tgz archive containing the chart source.
@@ -93,6 +97,10 @@ func PackageChart(ctx context.Context, loader chart.Loader, opts Options) (strin
 			return nil
 		}
 
+		if d.Type()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink; charts cannot contain links", rel)
+		}
+
 		info, err := d.Info()
 		if err != nil {
 			return err
@@ -118,6 +126,8 @@ func PackageChart(ctx context.Context, loader chart.Loader, opts Options) (strin
 		}
 		return f.Close()
 	}); err != nil {
+		file.Close()
+		os.Remove(outputPath)
 		return "", fmt.Errorf("archive chart: %w", err)
 	}
 
@@ -131,13 +141,38 @@ func PackageChart(ctx context.Context, loader chart.Loader, opts Options) (strin
 		return "", err
 	}
 
+	if opts.SigningKey != "" {
+		if err := signArchive(outputPath, chartPath, ch, opts.SigningKey); err != nil {
+			return "", err
+		}
+	}
+
 	return outputPath, nil
 }
 
+// signArchive writes the provenance file for a freshly packaged archive.
+func signArchive(archive, chartPath string, ch *chart.Chart, keyPath string) error {
+	key, err := provenance.LoadPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+	chartYAML, err := os.ReadFile(filepath.Join(chartPath, chart.MetadataFile))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", chart.MetadataFile, err)
+	}
+	prov, err := provenance.Sign(archive, ch.Metadata.Name, ch.Metadata.Version, chartYAML, key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(archive+provenance.Extension, prov, 0o644); err != nil {
+		return fmt.Errorf("write provenance file: %w", err)
+	}
+	return nil
+}
+
 func shouldSkip(rel string) bool {
-	parts := strings.Split(rel, string(os.PathSeparator))
-	for _, part := range parts {
-		if part == ".git" || part == ".DS_Store" || strings.HasPrefix(part, "._") || strings.HasPrefix(part, "__MACOSX") {
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		if chart.IgnoredFile(part) {
 			return true
 		}
 	}
@@ -147,3 +182,84 @@ func shouldSkip(rel string) bool {
 func nilPathErr(msg string) (string, error) {
 	return "", errors.New(msg)
 }
+
+// ArchiveChart writes a loaded chart as a .cpack.tgz stream. Every resolved dependency is
+// written under charts/<name>-<version>/, including `file://` ones, so the archive loads
+// without the directories the chart was built from.
+func ArchiveChart(w io.Writer, ch *chart.Chart) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := archiveTree(tw, ch, ""); err != nil {
+		return fmt.Errorf("archive chart: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// archiveTree writes the layout files of ch.FS below prefix, replacing its charts/ directory
+// with the subcharts the loader actually resolved.
+func archiveTree(tw *tar.Writer, ch *chart.Chart, prefix string) error {
+	if ch.FS == nil {
+		return fmt.Errorf("chart %s has no files", ch.Metadata.Name)
+	}
+	err := fs.WalkDir(ch.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		// only the chart layout is kept: a release directory or tests next to the templates
+		// must not end up in the copy stored with every revision
+		if name == chart.ChartsDir || !chart.InLayout(name, d.IsDir()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return fmt.Errorf("%s is not a regular file", name)
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = prefix + name
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		f, err := ch.FS.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// aliases can pull the same chart in twice; it is stored once
+	written := map[string]bool{}
+	for _, sub := range ch.Subcharts {
+		dir := fmt.Sprintf("%s%s/%s-%s/", prefix, chart.ChartsDir, sub.Chart.Metadata.Name, sub.Chart.Metadata.Version)
+		if written[dir] {
+			continue
+		}
+		written[dir] = true
+		if err := archiveTree(tw, sub.Chart, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package packager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/oci"
+	"composepack/internal/provenance"
+)
+
+// chartConfig is the OCI config blob stored alongside a chart archive.
+type chartConfig struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PushResult describes a chart pushed to a registry.
+type PushResult struct {
+	Ref    oci.Reference
+	Digest string
+}
+
+// Push uploads a packaged chart archive to an `oci://registry/repo` destination, tagging it
+// `<repo>/<name>:<version>`. A sibling .prov file is pushed with it.
+func Push(ctx context.Context, loader chart.Loader, client *oci.Client, archive, destination string) (*PushResult, error) {
+	if loader == nil {
+		return nil, fmt.Errorf("loader is required")
+	}
+	ref, err := oci.ParseReference(destination)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Tag != "" || ref.Digest != "" {
+		return nil, fmt.Errorf("destination %s must not include a tag or digest; the chart version is used", destination)
+	}
+
+	ch, err := loader.Load(ctx, archive)
+	if err != nil {
+		return nil, fmt.Errorf("load chart: %w", err)
+	}
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	prov, err := os.ReadFile(archive + provenance.Extension)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("read provenance file: %w", err)
+	}
+	config, err := json.Marshal(chartConfig{
+		Name:        ch.Metadata.Name,
+		Version:     ch.Metadata.Version,
+		Description: ch.Metadata.Description,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode chart config: %w", err)
+	}
+
+	ref.Repository += "/" + ch.Metadata.Name
+	ref = ref.WithTag(ch.Metadata.Version)
+	digest, err := client.Push(ctx, ref, oci.Artifact{
+		Config:     config,
+		Archive:    data,
+		Provenance: prov,
+		Annotations: map[string]string{
+			"org.opencontainers.image.title":   ch.Metadata.Name,
+			"org.opencontainers.image.version": ch.Metadata.Version,
+			"org.opencontainers.image.created": time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("push %s: %w", ref, err)
+	}
+	return &PushResult{Ref: ref, Digest: digest}, nil
+}
+
+// Pull downloads an `oci://` chart into destination as <name>-<version>.cpack.tgz, along
+// with its provenance file when one was pushed.
+func Pull(ctx context.Context, client *oci.Client, source, destination string) (string, error) {
+	ref, err := oci.ParseReference(source)
+	if err != nil {
+		return "", err
+	}
+	artifact, _, err := client.Pull(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("pull %s: %w", ref, err)
+	}
+	var config chartConfig
+	if err := json.Unmarshal(artifact.Config, &config); err != nil {
+		return "", fmt.Errorf("parse chart config: %w", err)
+	}
+	if config.Name == "" || config.Version == "" {
+		return "", fmt.Errorf("%s: chart config is missing name or version", ref)
+	}
+
+	if destination == "" {
+		destination = "."
+	}
+	if err := os.MkdirAll(destination, 0o755); err != nil {
+		return "", fmt.Errorf("ensure destination: %w", err)
+	}
+	path := filepath.Join(destination, fmt.Sprintf("%s-%s.cpack.tgz", config.Name, config.Version))
+	if err := os.WriteFile(path, artifact.Archive, 0o644); err != nil {
+		return "", fmt.Errorf("write %s: %w", path, err)
+	}
+	if len(artifact.Provenance) > 0 {
+		if err := os.WriteFile(path+provenance.Extension, artifact.Provenance, 0o644); err != nil {
+			return "", fmt.Errorf("write %s: %w", path+provenance.Extension, err)
+		}
+	}
+	return path, nil
+}
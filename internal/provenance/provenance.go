@@ -0,0 +1,260 @@
+package provenance
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Extension is appended to an archive path to name its provenance file.
+const Extension = ".prov"
+
+const (
+	apiVersion     = "v1"
+	signatureBlock = "COMPOSEPACK SIGNATURE"
+	keyIDHeader    = "Key-Id"
+)
+
+// ErrUnverified is wrapped by every verification failure.
+var ErrUnverified = errors.New("chart verification failed")
+
+// Statement is the signed part of a provenance file.
+type Statement struct {
+	APIVersion string    `json:"apiVersion"`
+	Name       string    `json:"name"`
+	Version    string    `json:"version"`
+	Archive    string    `json:"archive"`
+	Digest     string    `json:"digest"`
+	Created    time.Time `json:"created"`
+	// ChartYAML is the chart's Chart.yaml exactly as packaged.
+	ChartYAML string `json:"chartYAML"`
+}
+
+// Result describes a successful verification.
+type Result struct {
+	Statement *Statement
+	// KeyID identifies the keyring entry that signed the chart; empty for checksum-only checks.
+	KeyID string
+}
+
+// Sign produces a provenance file for the archive at path: a YAML statement carrying the
+// archive's SHA-256 and Chart.yaml, followed by a PEM-armoured ed25519 signature over it.
+func Sign(path, name, version string, chartYAML []byte, key ed25519.PrivateKey) ([]byte, error) {
+	digest, err := FileDigest(path)
+	if err != nil {
+		return nil, err
+	}
+	statement, err := yaml.Marshal(Statement{
+		APIVersion: apiVersion,
+		Name:       name,
+		Version:    version,
+		Archive:    filepath.Base(path),
+		Digest:     digest,
+		Created:    time.Now().UTC().Truncate(time.Second),
+		ChartYAML:  string(chartYAML),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("serialize provenance: %w", err)
+	}
+
+	pub := key.Public().(ed25519.PublicKey)
+	block := pem.EncodeToMemory(&pem.Block{
+		Type:    signatureBlock,
+		Headers: map[string]string{keyIDHeader: KeyID(pub)},
+		Bytes:   ed25519.Sign(key, statement),
+	})
+	return append(statement, block...), nil
+}
+
+// Verify checks that provenance was signed by a key in keyring and that it describes the
+// archive at path.
+func Verify(path string, provenance []byte, keyring []ed25519.PublicKey) (*Result, error) {
+	if len(keyring) == 0 {
+		return nil, fmt.Errorf("%w: keyring is empty", ErrUnverified)
+	}
+	idx := bytes.Index(provenance, []byte("-----BEGIN "+signatureBlock+"-----"))
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: provenance file has no signature", ErrUnverified)
+	}
+	message := provenance[:idx]
+	block, _ := pem.Decode(provenance[idx:])
+	if block == nil || block.Type != signatureBlock {
+		return nil, fmt.Errorf("%w: malformed signature block", ErrUnverified)
+	}
+
+	keyID := ""
+	for _, pub := range keyring {
+		if ed25519.Verify(pub, message, block.Bytes) {
+			keyID = KeyID(pub)
+			break
+		}
+	}
+	if keyID == "" {
+		return nil, fmt.Errorf("%w: signature (key %s) does not match any key in the keyring", ErrUnverified, block.Headers[keyIDHeader])
+	}
+
+	var statement Statement
+	if err := yaml.Unmarshal(message, &statement); err != nil {
+		return nil, fmt.Errorf("%w: parse provenance: %v", ErrUnverified, err)
+	}
+	if statement.APIVersion != apiVersion {
+		return nil, fmt.Errorf("%w: unsupported provenance apiVersion %q", ErrUnverified, statement.APIVersion)
+	}
+	if err := VerifyChecksum(path, statement.Digest); err != nil {
+		return nil, err
+	}
+	return &Result{Statement: &statement, KeyID: keyID}, nil
+}
+
+// VerifyChecksum checks the archive at path against a `sha256:<hex>` digest.
+func VerifyChecksum(path, want string) error {
+	if !strings.HasPrefix(want, "sha256:") {
+		return fmt.Errorf("checksum %q must be of the form sha256:<hex>", want)
+	}
+	got, err := FileDigest(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(got, want) {
+		return fmt.Errorf("%w: archive digest %s does not match %s", ErrUnverified, got, want)
+	}
+	return nil
+}
+
+// FileDigest returns the `sha256:<hex>` digest of a file.
+func FileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// KeyID is a short fingerprint of a public key.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadPrivateKey reads a PKCS#8 PEM ed25519 private key (as written by
+// `openssl genpkey -algorithm ed25519`).
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("%s: expected a PEM \"PRIVATE KEY\" block", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: parse private key: %w", path, err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: signing key must be ed25519", path)
+	}
+	return ed, nil
+}
+
+// LoadKeyring reads every PEM "PUBLIC KEY" block from a file, or from every file in a directory.
+func LoadKeyring(path string) ([]ed25519.PublicKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read keyring: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("read keyring: %w", err)
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	var keys []ed25519.PublicKey
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read keyring: %w", err)
+		}
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type != "PUBLIC KEY" {
+				continue
+			}
+			key, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%s: parse public key: %w", file, err)
+			}
+			if ed, ok := key.(ed25519.PublicKey); ok {
+				keys = append(keys, ed)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keyring %s contains no ed25519 public keys", path)
+	}
+	return keys, nil
+}
+
+// Verifier checks chart archives against an expected checksum and/or signed provenance
+// before they are loaded.
+type Verifier struct {
+	// Checksum is an expected `sha256:<hex>` archive digest.
+	Checksum string
+	// Keyring, when non-empty, requires a provenance file signed by one of its keys.
+	Keyring []ed25519.PublicKey
+	// Result is filled in by a successful VerifyArchive.
+	Result *Result
+}
+
+// VerifyArchive implements chart.ArchiveVerifier.
+func (v *Verifier) VerifyArchive(path string, provenance func() ([]byte, error)) error {
+	if v.Checksum != "" {
+		if err := VerifyChecksum(path, v.Checksum); err != nil {
+			return err
+		}
+		v.Result = &Result{}
+	}
+	if len(v.Keyring) == 0 {
+		return nil
+	}
+	data, err := provenance()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnverified, err)
+	}
+	result, err := Verify(path, data, v.Keyring)
+	if err != nil {
+		return err
+	}
+	v.Result = result
+	return nil
+}
@@ -0,0 +1,201 @@
+package provenance
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func publicPEM(t *testing.T, pub any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// signedArchive writes a stand-in archive and signs it with key.
+func signedArchive(t *testing.T, key ed25519.PrivateKey) (string, []byte) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "demo-0.1.0.cpack.tgz")
+	if err := os.WriteFile(path, []byte("archive bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prov, err := Sign(path, "demo", "0.1.0", []byte("name: demo\nversion: 0.1.0\n"), key)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return path, prov
+}
+
+func TestSignVerify(t *testing.T) {
+	key := newKey(t)
+	path, prov := signedArchive(t, key)
+	pub := key.Public().(ed25519.PublicKey)
+
+	result, err := Verify(path, prov, []ed25519.PublicKey{newKey(t).Public().(ed25519.PublicKey), pub})
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if result.KeyID != KeyID(pub) {
+		t.Errorf("KeyID = %s, want %s", result.KeyID, KeyID(pub))
+	}
+	digest, _ := FileDigest(path)
+	s := result.Statement
+	if s.Name != "demo" || s.Version != "0.1.0" || s.Archive != filepath.Base(path) || s.Digest != digest || !strings.Contains(s.ChartYAML, "name: demo") {
+		t.Errorf("statement = %+v", s)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	key := newKey(t)
+	pub := key.Public().(ed25519.PublicKey)
+
+	tests := map[string]struct {
+		tamper  func(path string, prov []byte) []byte
+		keyring []ed25519.PublicKey
+		want    string
+	}{
+		"tampered archive": {
+			tamper: func(path string, prov []byte) []byte {
+				os.WriteFile(path, []byte("other bytes"), 0o644)
+				return prov
+			},
+			want: "does not match",
+		},
+		"tampered statement": {
+			tamper: func(_ string, prov []byte) []byte {
+				return bytes.Replace(prov, []byte("version: 0.1.0"), []byte("version: 0.2.0"), 1)
+			},
+			want: "does not match any key",
+		},
+		"unknown key": {
+			keyring: []ed25519.PublicKey{newKey(t).Public().(ed25519.PublicKey)},
+			want:    "does not match any key",
+		},
+		"empty keyring": {
+			keyring: []ed25519.PublicKey{},
+			want:    "keyring is empty",
+		},
+		"no signature": {
+			tamper: func(_ string, prov []byte) []byte {
+				return prov[:bytes.Index(prov, []byte("-----BEGIN"))]
+			},
+			want: "has no signature",
+		},
+		"truncated signature": {
+			tamper: func(_ string, prov []byte) []byte {
+				return prov[:len(prov)-10]
+			},
+			want: "malformed signature block",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, prov := signedArchive(t, key)
+			if tt.tamper != nil {
+				prov = tt.tamper(path, prov)
+			}
+			keyring := tt.keyring
+			if keyring == nil {
+				keyring = []ed25519.PublicKey{pub}
+			}
+			_, err := Verify(path, prov, keyring)
+			if !errors.Is(err, ErrUnverified) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path, _ := signedArchive(t, newKey(t))
+	digest, err := FileDigest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyChecksum(path, strings.ToUpper(digest[:7])+digest[7:]); err == nil {
+		t.Error("checksum with an upper-case algorithm was accepted")
+	}
+	if err := VerifyChecksum(path, "sha256:"+strings.ToUpper(digest[7:])); err != nil {
+		t.Errorf("upper-case hex: %v", err)
+	}
+	if err := VerifyChecksum(path, "sha256:00"); !errors.Is(err, ErrUnverified) {
+		t.Errorf("wrong checksum: %v", err)
+	}
+	if err := VerifyChecksum(path, "md5:00"); err == nil || errors.Is(err, ErrUnverified) {
+		t.Errorf("unsupported checksum: %v", err)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	first, second := newKey(t), newKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	bundle := append(publicPEM(t, first.Public()), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("x")})...)
+	bundle = append(bundle, publicPEM(t, &ecKey.PublicKey)...)
+	os.WriteFile(filepath.Join(dir, "first.pub"), bundle, 0o644)
+	os.WriteFile(filepath.Join(dir, "second.pub"), publicPEM(t, second.Public()), 0o644)
+	os.Mkdir(filepath.Join(dir, "nested"), 0o755)
+
+	keys, err := LoadKeyring(filepath.Join(dir, "first.pub"))
+	if err != nil || len(keys) != 1 || !keys[0].Equal(first.Public()) {
+		t.Errorf("LoadKeyring(file) = %d keys, %v", len(keys), err)
+	}
+	keys, err = LoadKeyring(dir)
+	if err != nil || len(keys) != 2 {
+		t.Errorf("LoadKeyring(dir) = %d keys, %v", len(keys), err)
+	}
+
+	ecOnly := filepath.Join(t.TempDir(), "ec.pub")
+	os.WriteFile(ecOnly, publicPEM(t, &ecKey.PublicKey), 0o644)
+	if _, err := LoadKeyring(ecOnly); err == nil || !strings.Contains(err.Error(), "no ed25519 public keys") {
+		t.Errorf("keyring without ed25519 keys: %v", err)
+	}
+	if _, err := LoadKeyring(filepath.Join(dir, "missing.pub")); err == nil {
+		t.Error("missing keyring was accepted")
+	}
+}
+
+func TestLoadPrivateKey(t *testing.T) {
+	key := newKey(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "signing.key")
+	os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	loaded, err := LoadPrivateKey(path)
+	if err != nil || !loaded.Equal(key) {
+		t.Errorf("LoadPrivateKey = %v", err)
+	}
+
+	public := filepath.Join(t.TempDir(), "signing.pub")
+	os.WriteFile(public, publicPEM(t, key.Public()), 0o644)
+	if _, err := LoadPrivateKey(public); err == nil {
+		t.Error("public key was accepted as a signing key")
+	}
+}
@@ -0,0 +1,243 @@
+package repo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/util/fsutil"
+)
+
+// IndexFileName is the file a chart repository serves at its root.
+const IndexFileName = "index.yaml"
+
+// indexAPIVersion is written into generated index files.
+const indexAPIVersion = "v1"
+
+// maxIndexSize bounds how much of a remote index.yaml is read.
+const maxIndexSize = 32 << 20
+
+// Index lists every chart version published in a repository.
+type Index struct {
+	APIVersion string                     `json:"apiVersion"`
+	Generated  time.Time                  `json:"generated"`
+	Entries    map[string][]*ChartVersion `json:"entries"`
+}
+
+// ChartVersion describes one packaged chart in an index.
+type ChartVersion struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Description string    `json:"description,omitempty"`
+	Digest      string    `json:"digest"`
+	URLs        []string  `json:"urls"`
+	Created     time.Time `json:"created"`
+}
+
+// IndexDirectory builds an index from the .cpack.tgz archives in dir. URLs are relative to
+// the index unless baseURL is set.
+func IndexDirectory(ctx context.Context, loader chart.Loader, dir, baseURL string) (*Index, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	index := &Index{APIVersion: indexAPIVersion, Generated: time.Now().UTC(), Entries: map[string][]*ChartVersion{}}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".cpack.tgz") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		ch, err := loader.Load(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %w", path, err)
+		}
+		digest, err := fileDigest(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		link := entry.Name()
+		if baseURL != "" {
+			link = strings.TrimSuffix(baseURL, "/") + "/" + entry.Name()
+		}
+		name := ch.Metadata.Name
+		for _, existing := range index.Entries[name] {
+			if existing.Version == ch.Metadata.Version {
+				return nil, fmt.Errorf("%s %s is packaged twice (%s and %s)", name, existing.Version, existing.URLs[0], link)
+			}
+		}
+		index.Entries[name] = append(index.Entries[name], &ChartVersion{
+			Name:        name,
+			Version:     ch.Metadata.Version,
+			Description: ch.Metadata.Description,
+			Digest:      digest,
+			URLs:        []string{link},
+			Created:     info.ModTime().UTC(),
+		})
+	}
+	index.sort()
+	return index, nil
+}
+
+// ParseIndex decodes an index.yaml document.
+func ParseIndex(data []byte) (*Index, error) {
+	var index Index
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", IndexFileName, err)
+	}
+	if index.APIVersion == "" {
+		return nil, fmt.Errorf("parse %s: missing apiVersion", IndexFileName)
+	}
+	if index.Entries == nil {
+		index.Entries = map[string][]*ChartVersion{}
+	}
+	index.sort()
+	return &index, nil
+}
+
+// ReadIndex loads an index file from disk.
+func ReadIndex(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return ParseIndex(data)
+}
+
+// FetchIndex downloads `<repoURL>/index.yaml`.
+func FetchIndex(ctx context.Context, client *http.Client, repoURL string) (*Index, []byte, error) {
+	indexURL := strings.TrimSuffix(repoURL, "/") + "/" + IndexFileName
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("build request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch %s: %w", indexURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("fetch %s: unexpected status %s", indexURL, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxIndexSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read %s: %w", indexURL, err)
+	}
+	index, err := ParseIndex(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", indexURL, err)
+	}
+	return index, data, nil
+}
+
+// WriteFile saves the index as YAML.
+func (i *Index) WriteFile(ctx context.Context, path string) error {
+	data, err := yaml.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("serialize %s: %w", IndexFileName, err)
+	}
+	if err := fsutil.WriteFileAtomic(ctx, path, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Find returns the newest version of name satisfying constraint (an exact version or semver
+// range). An empty constraint selects the newest stable version.
+func (i *Index) Find(name, constraint string) (*ChartVersion, error) {
+	versions, ok := i.Entries[name]
+	if !ok || len(versions) == 0 {
+		return nil, fmt.Errorf("chart %q not found in repository index", name)
+	}
+
+	var check *semver.Constraints
+	if constraint != "" {
+		c, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+		check = c
+	}
+	for _, cv := range versions {
+		if constraint == cv.Version {
+			return cv, nil
+		}
+		v, err := semver.NewVersion(cv.Version)
+		if err != nil {
+			continue
+		}
+		if check == nil {
+			if v.Prerelease() == "" {
+				return cv, nil
+			}
+			continue
+		}
+		if check.Check(v) {
+			return cv, nil
+		}
+	}
+	if constraint == "" {
+		return nil, fmt.Errorf("chart %q has no stable version (use --version)", name)
+	}
+	return nil, fmt.Errorf("chart %q has no version matching %s", name, constraint)
+}
+
+// ResolveURL returns the absolute download URL of cv, resolving relative URLs against repoURL.
+func (cv *ChartVersion) ResolveURL(repoURL string) (string, error) {
+	if len(cv.URLs) == 0 {
+		return "", fmt.Errorf("%s %s has no download URL", cv.Name, cv.Version)
+	}
+	base, err := url.Parse(strings.TrimSuffix(repoURL, "/") + "/")
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL %q: %w", repoURL, err)
+	}
+	ref, err := url.Parse(cv.URLs[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid chart URL %q: %w", cv.URLs[0], err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// sort orders every chart's versions newest first.
+func (i *Index) sort() {
+	for _, versions := range i.Entries {
+		sort.SliceStable(versions, func(a, b int) bool {
+			va, errA := semver.NewVersion(versions[a].Version)
+			vb, errB := semver.NewVersion(versions[b].Version)
+			if errA != nil || errB != nil {
+				return versions[a].Version > versions[b].Version
+			}
+			return va.GreaterThan(vb)
+		})
+	}
+}
+
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,331 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"composepack/internal/core/chart"
+	"composepack/internal/infra/config"
+	"composepack/internal/util/fsutil"
+)
+
+// RepositoriesFile is the user config file listing added repositories.
+const RepositoriesFile = "repositories.yaml"
+
+var repoNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Entry is a named chart repository.
+type Entry struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type repositoriesFile struct {
+	Repositories []Entry `json:"repositories"`
+}
+
+// SearchResult is one chart version matched by Search.
+type SearchResult struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Manager maintains the user's repository list and cached indexes.
+type Manager struct {
+	// ConfigPath is the repositories.yaml location.
+	ConfigPath string
+	// CacheDir holds downloaded `<name>-index.yaml` files.
+	CacheDir string
+	// Offline only uses indexes that are already cached.
+	Offline bool
+	client  *http.Client
+}
+
+// NewManager returns a manager backed by the user's config and cache directories.
+func NewManager() (*Manager, error) {
+	configDir, err := config.UserConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	cacheDir, err := config.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{
+		ConfigPath: filepath.Join(configDir, RepositoriesFile),
+		CacheDir:   filepath.Join(cacheDir, "repository"),
+		client:     http.DefaultClient,
+	}, nil
+}
+
+// SplitChartRef splits `repo/chart` into its parts; ok is false for anything else.
+func SplitChartRef(source string) (repoName, chartName string, ok bool) {
+	repoName, chartName, ok = strings.Cut(source, "/")
+	if !ok || strings.Contains(chartName, "/") || !repoNamePattern.MatchString(repoName) || chartName == "" {
+		return "", "", false
+	}
+	return repoName, chartName, true
+}
+
+// List returns the configured repositories in the order they were added.
+func (m *Manager) List() ([]Entry, error) {
+	file, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+	return file.Repositories, nil
+}
+
+// Get returns the repository called name.
+func (m *Manager) Get(name string) (Entry, bool, error) {
+	entries, err := m.List()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	for _, entry := range entries {
+		if entry.Name == name {
+			return entry, true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// Add registers a repository after downloading its index. An existing name is only
+// replaced when force is set.
+func (m *Manager) Add(ctx context.Context, name, repoURL string, force bool) error {
+	if !repoNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid repository name %q", name)
+	}
+	if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
+		return fmt.Errorf("repository URL %q must use http or https", repoURL)
+	}
+	repoURL = strings.TrimSuffix(repoURL, "/")
+
+	file, err := m.load()
+	if err != nil {
+		return err
+	}
+	idx := -1
+	for i, entry := range file.Repositories {
+		if entry.Name == name {
+			idx = i
+		}
+	}
+	if idx >= 0 && !force && file.Repositories[idx].URL != repoURL {
+		return fmt.Errorf("repository %q already exists with URL %s (use --force-update to replace it)", name, file.Repositories[idx].URL)
+	}
+
+	if err := m.refresh(ctx, Entry{Name: name, URL: repoURL}); err != nil {
+		return err
+	}
+	if idx >= 0 {
+		file.Repositories[idx].URL = repoURL
+	} else {
+		file.Repositories = append(file.Repositories, Entry{Name: name, URL: repoURL})
+	}
+	return m.save(ctx, file)
+}
+
+// Remove forgets a repository and its cached index.
+func (m *Manager) Remove(ctx context.Context, name string) error {
+	file, err := m.load()
+	if err != nil {
+		return err
+	}
+	kept := file.Repositories[:0]
+	found := false
+	for _, entry := range file.Repositories {
+		if entry.Name == name {
+			found = true
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	if !found {
+		return fmt.Errorf("repository %q not found", name)
+	}
+	file.Repositories = kept
+	if err := os.Remove(m.indexPath(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove cached index: %w", err)
+	}
+	return m.save(ctx, file)
+}
+
+// Update re-downloads the index of the named repositories (all when names is empty).
+func (m *Manager) Update(ctx context.Context, names []string) ([]Entry, error) {
+	entries, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	selected := entries
+	if len(names) > 0 {
+		selected = nil
+		for _, name := range names {
+			entry, ok, err := m.Get(name)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, fmt.Errorf("repository %q not found", name)
+			}
+			selected = append(selected, entry)
+		}
+	}
+
+	var errs []error
+	var updated []Entry
+	for _, entry := range selected {
+		if err := m.refresh(ctx, entry); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name, err))
+			continue
+		}
+		updated = append(updated, entry)
+	}
+	return updated, errors.Join(errs...)
+}
+
+// Index returns the cached index of a repository, downloading it when missing.
+func (m *Manager) Index(ctx context.Context, entry Entry) (*Index, error) {
+	index, err := ReadIndex(m.indexPath(entry.Name))
+	if err == nil {
+		return index, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err := m.refresh(ctx, entry); err != nil {
+		return nil, err
+	}
+	return ReadIndex(m.indexPath(entry.Name))
+}
+
+// Search matches term (case-insensitive) against `repo/chart` names and descriptions in every
+// cached index. Only the newest version of each chart is returned unless all is set; a
+// non-empty constraint filters versions.
+func (m *Manager) Search(ctx context.Context, term, constraint string, all bool) ([]SearchResult, error) {
+	entries, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	term = strings.ToLower(term)
+
+	var results []SearchResult
+	for _, entry := range entries {
+		index, err := m.Index(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		for name, versions := range index.Entries {
+			full := entry.Name + "/" + name
+			if term != "" && len(versions) > 0 &&
+				!strings.Contains(strings.ToLower(full), term) &&
+				!strings.Contains(strings.ToLower(versions[0].Description), term) {
+				continue
+			}
+			if !all {
+				cv, err := index.Find(name, constraint)
+				if err != nil {
+					continue
+				}
+				results = append(results, SearchResult{Name: full, Version: cv.Version, Description: cv.Description})
+				continue
+			}
+			for _, cv := range versions {
+				if chart.VersionMatches(constraint, cv.Version) {
+					results = append(results, SearchResult{Name: full, Version: cv.Version, Description: cv.Description})
+				}
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, nil
+}
+
+// Resolve finds the newest version of `repoName/chartName` matching constraint and returns
+// its absolute download URL.
+func (m *Manager) Resolve(ctx context.Context, repoName, chartName, constraint string) (string, *ChartVersion, error) {
+	entry, ok, err := m.Get(repoName)
+	if err != nil {
+		return "", nil, err
+	}
+	if !ok {
+		return "", nil, fmt.Errorf("repository %q not found (add it with composepack repo add)", repoName)
+	}
+	index, err := m.Index(ctx, entry)
+	if err != nil {
+		return "", nil, err
+	}
+	cv, err := index.Find(chartName, constraint)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %w", repoName, err)
+	}
+	chartURL, err := cv.ResolveURL(entry.URL)
+	if err != nil {
+		return "", nil, err
+	}
+	return chartURL, cv, nil
+}
+
+func (m *Manager) refresh(ctx context.Context, entry Entry) error {
+	if m.Offline {
+		return fmt.Errorf("index of repository %q is not cached (offline)", entry.Name)
+	}
+	_, data, err := FetchIndex(ctx, m.client, entry.URL)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.EnsureDir(m.CacheDir); err != nil {
+		return fmt.Errorf("ensure repository cache: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(ctx, m.indexPath(entry.Name), data, 0o644); err != nil {
+		return fmt.Errorf("cache index: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) indexPath(name string) string {
+	return filepath.Join(m.CacheDir, name+"-index.yaml")
+}
+
+func (m *Manager) load() (*repositoriesFile, error) {
+	data, err := os.ReadFile(m.ConfigPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &repositoriesFile{}, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", m.ConfigPath, err)
+	}
+	var file repositoriesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", m.ConfigPath, err)
+	}
+	return &file, nil
+}
+
+func (m *Manager) save(ctx context.Context, file *repositoriesFile) error {
+	if file.Repositories == nil {
+		file.Repositories = []Entry{}
+	}
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("serialize %s: %w", RepositoriesFile, err)
+	}
+	if err := fsutil.EnsureDir(filepath.Dir(m.ConfigPath)); err != nil {
+		return fmt.Errorf("ensure config dir: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(ctx, m.ConfigPath, data, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", m.ConfigPath, err)
+	}
+	return nil
+}
@@ -7,9 +7,11 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-// FileSystemLoader provides helpers for reading directories and files from disk.
+// FileSystemLoader provides helpers for resolving chart directories on disk and reading files
+// from any fs.FS (a directory via os.DirFS, an in-memory archive or an embed.FS).
 type FileSystemLoader struct{}
 
 // NewFileSystemLoader creates a filesystem loader instance.
@@ -39,11 +41,11 @@ func (l *FileSystemLoader) ResolveDir(path string) (string, error) {
 	return abs, nil
 }
 
-// ReadFileIfExists reads a file returning (nil, nil) when the file is missing.
-func (l *FileSystemLoader) ReadFileIfExists(path string) ([]byte, error) {
-	data, err := os.ReadFile(path)
+// ReadFileIfExists reads name from fsys, returning (nil, nil) when the file is missing.
+func (l *FileSystemLoader) ReadFileIfExists(fsys fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, name)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
+		if errors.Is(err, fs.ErrNotExist) {
 			return nil, nil
 		}
 		return nil, err
@@ -51,19 +53,16 @@ func (l *FileSystemLoader) ReadFileIfExists(path string) ([]byte, error) {
 	return data, nil
 }
 
-// ReadFile reads a file and returns an error if it cannot be read.
-func (l *FileSystemLoader) ReadFile(path string) ([]byte, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	return data, nil
+// ReadFile reads name from fsys and returns an error if it cannot be read.
+func (l *FileSystemLoader) ReadFile(fsys fs.FS, name string) ([]byte, error) {
+	return fs.ReadFile(fsys, name)
 }
 
-// WalkFiles walks the directory tree rooted at dir, invoking visit for each file.
-func (l *FileSystemLoader) WalkFiles(ctx context.Context, dir string, visit func(rel string, data []byte) error) error {
-	info, err := os.Stat(dir)
-	if errors.Is(err, os.ErrNotExist) {
+// WalkFiles walks the tree rooted at dir inside fsys, invoking visit for each file with its
+// slash-separated path relative to dir. A missing dir is not an error.
+func (l *FileSystemLoader) WalkFiles(ctx context.Context, fsys fs.FS, dir string, visit func(rel string, data []byte) error) error {
+	info, err := fs.Stat(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
 		return nil
 	}
 	if err != nil {
@@ -73,7 +72,7 @@ func (l *FileSystemLoader) WalkFiles(ctx context.Context, dir string, visit func
 		return fmt.Errorf("path %q is not a directory", dir)
 	}
 
-	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+	return fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -84,16 +83,11 @@ func (l *FileSystemLoader) WalkFiles(ctx context.Context, dir string, visit func
 			return ctx.Err()
 		}
 
-		rel, relErr := filepath.Rel(dir, path)
-		if relErr != nil {
-			return relErr
-		}
-
-		data, readErr := os.ReadFile(path)
+		data, readErr := fs.ReadFile(fsys, name)
 		if readErr != nil {
 			return readErr
 		}
 
-		return visit(filepath.ToSlash(rel), data)
+		return visit(strings.TrimPrefix(name, dir+"/"), data)
 	})
 }
@@ -0,0 +1,186 @@
+package fileloader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// MemFS is a read-only in-memory fs.FS, used for chart archives that are never unpacked to disk.
+// It is populated with AddFile/AddDir and must not be modified once it is being read.
+type MemFS struct {
+	entries map[string]*memEntry
+}
+
+// NewMemFS returns an empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	return &MemFS{entries: map[string]*memEntry{
+		".": {name: ".", mode: fs.ModeDir | 0o755},
+	}}
+}
+
+// AddDir records a directory and any missing parents.
+func (m *MemFS) AddDir(name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	_, err := m.dir(name)
+	return err
+}
+
+// AddFile stores data at name, creating parent directories. Adding an existing path fails.
+func (m *MemFS) AddFile(name string, data []byte, mode fs.FileMode, modTime time.Time) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	if _, ok := m.entries[name]; ok {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	parent, err := m.dir(path.Dir(name))
+	if err != nil {
+		return err
+	}
+	m.entries[name] = &memEntry{name: path.Base(name), data: data, mode: mode.Perm(), modTime: modTime}
+	parent.children = append(parent.children, name)
+	return nil
+}
+
+func (m *MemFS) dir(name string) (*memEntry, error) {
+	if entry, ok := m.entries[name]; ok {
+		if !entry.IsDir() {
+			return nil, &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+		}
+		return entry, nil
+	}
+	parent, err := m.dir(path.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+	entry := &memEntry{name: path.Base(name), mode: fs.ModeDir | 0o755}
+	m.entries[name] = entry
+	parent.children = append(parent.children, name)
+	return entry, nil
+}
+
+func (m *MemFS) lookup(op, name string) (*memEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+// Open implements fs.FS.
+func (m *MemFS) Open(name string) (fs.File, error) {
+	entry, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() {
+		return &memDir{entry: entry, entries: m.list(entry)}, nil
+	}
+	return &memFile{entry: entry, reader: bytes.NewReader(entry.data)}, nil
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	entry, err := m.lookup("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	return bytes.Clone(entry.data), nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (m *MemFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return m.list(entry), nil
+}
+
+// Stat implements fs.StatFS.
+func (m *MemFS) Stat(name string) (fs.FileInfo, error) {
+	return m.lookup("stat", name)
+}
+
+// list returns a directory's children sorted by name, as fs.ReadDir does.
+func (m *MemFS) list(dir *memEntry) []fs.DirEntry {
+	out := make([]fs.DirEntry, 0, len(dir.children))
+	for _, child := range dir.children {
+		out = append(out, m.entries[child])
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out
+}
+
+// memEntry is both the stored node and its fs.FileInfo / fs.DirEntry.
+type memEntry struct {
+	name     string
+	data     []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children []string
+}
+
+func (e *memEntry) Name() string               { return e.name }
+func (e *memEntry) Size() int64                { return int64(len(e.data)) }
+func (e *memEntry) Mode() fs.FileMode          { return e.mode }
+func (e *memEntry) ModTime() time.Time         { return e.modTime }
+func (e *memEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *memEntry) Sys() any                   { return nil }
+func (e *memEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *memEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+type memFile struct {
+	entry  *memEntry
+	reader *bytes.Reader
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *memFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *memFile) Close() error               { return nil }
+
+type memDir struct {
+	entry   *memEntry
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *memDir) Close() error               { return nil }
+
+func (d *memDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *memDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,58 @@
+package fileloader
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestMemFS(t *testing.T) {
+	m := NewMemFS()
+	modTime := time.Unix(1700000000, 0)
+	files := map[string]string{
+		"Chart.yaml":                     "name: demo\n",
+		"templates/compose/app.tpl.yaml": "services: {}\n",
+		"files/config/app.conf":          "key=value\n",
+		"files/b.txt":                    "b\n",
+		"files/a.txt":                    "a\n",
+	}
+	for name, content := range files {
+		if err := m.AddFile(name, []byte(content), 0o644, modTime); err != nil {
+			t.Fatalf("AddFile(%q): %v", name, err)
+		}
+	}
+	if err := m.AddDir("charts"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(m, "Chart.yaml", "templates/compose/app.tpl.yaml", "files/config/app.conf", "files/a.txt", "files/b.txt", "charts"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemFSRejects(t *testing.T) {
+	m := NewMemFS()
+	if err := m.AddFile("files/a.txt", []byte("a"), 0o644, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]error{
+		"duplicate file":    m.AddFile("files/a.txt", nil, 0o644, time.Time{}),
+		"file under a file": m.AddFile("files/a.txt/b", nil, 0o644, time.Time{}),
+		"dir over a file":   m.AddDir("files/a.txt"),
+		"escaping path":     m.AddFile("../a.txt", nil, 0o644, time.Time{}),
+		"root":              m.AddFile(".", nil, 0o644, time.Time{}),
+	}
+	for name, err := range tests {
+		if err == nil {
+			t.Errorf("%s was accepted", name)
+		}
+	}
+	if _, err := m.Open("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open(missing) = %v", err)
+	}
+	if _, err := m.ReadFile("files"); err == nil {
+		t.Error("ReadFile on a directory succeeded")
+	}
+}